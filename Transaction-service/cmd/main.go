@@ -3,13 +3,17 @@ package main
 import (
 	"context"
 	"log"
+	"net"
 	"net/http"
 	"os"
+	"strconv"
 
 	//"strings"
 	//"transaction/internal/account"
 	"transaction/internal/infrastructure/database"
 	"transaction/internal/infrastructure/kafka"
+	"transaction/internal/schedule"
+	"transaction/internal/statement"
 	"transaction/internal/transaction"
 	"transaction/pb"
 
@@ -19,6 +23,7 @@ import (
 	// "transaction/Transaction-service/internal/infrastructure/database"
 	// "transaction/Transaction-service/internal/transaction"
 
+	transactionGrpc "transaction/internal/grpc"
 	httpinfra "transaction/internal/infrastructure/http"
 
 	"github.com/joho/godotenv"
@@ -73,7 +78,34 @@ func main() {
 		
 	 )
 
-	 transactionHandler := transaction.NewTransactionHandler(transactionService)
+	// e.g. LEDGER_SIGNING_KEY=$(head -c 32 /dev/urandom | base64) signs chain
+	// checkpoints with that Ed25519 seed; unset disables signing
+	if v := os.Getenv("LEDGER_SIGNING_KEY"); v != "" {
+		signer, err := transaction.ParseChainSigner(v)
+		if err != nil {
+			log.Fatal(err)
+		}
+		transactionService.UseChainSigner(signer)
+	}
+
+	// e.g. IDEMPOTENCY_RETENTION="24h,transfer=72h"
+	retention, err := transaction.ParseIdempotencyRetention(os.Getenv("IDEMPOTENCY_RETENTION"))
+	if err != nil {
+		log.Fatal(err)
+	}
+	transactionService.UseIdempotencyRetention(retention)
+	idempotencyRepo := transaction.NewPostgresIdempotencyRepoWithRetention(db, retention)
+
+	 transactionHandler := transaction.NewTransactionHandler(
+		transactionService,
+		idempotencyRepo,
+	)
+
+	scheduleService := schedule.NewService(db, transactionService, accountClient)
+	scheduleHandler := schedule.NewHandler(scheduleService)
+
+	statementService := statement.NewService(db)
+	statementHandler := statement.NewHandler(statementService)
 
 
 	producer := kafka.NewProducer([]string{"localhost:9092"})
@@ -88,13 +120,61 @@ func main() {
 	router := httpinfra.NewRouter(
 		//accountHandler.Routes(),
 		transactionHandler.Routes(),
+		scheduleHandler.Routes(),
+		statementHandler.Routes(),
 	)
 
 
+	holdWorker := transaction.NewHoldExpiryWorker(transactionService)
+	scheduleWorker := schedule.NewWorker(scheduleService)
+	statementWorker := statement.NewWorker(statementService)
+	checkpointWorker := transaction.NewCheckpointWorker(transactionService)
+	interestWorker := transaction.NewInterestWorker(transactionService)
+	reconciliationWorker := transaction.NewReconciliationWorker(transactionService)
+	chainWorker := transaction.NewChainWorker(transactionService)
+	idempotencySweeper := transaction.NewIdempotencySweeper(idempotencyRepo)
+
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
 	go worker.Start(ctx)
+	go holdWorker.Start(ctx)
+	go scheduleWorker.Start(ctx)
+	go statementWorker.Start(ctx)
+	go checkpointWorker.Start(ctx)
+	go interestWorker.Start(ctx)
+	go reconciliationWorker.Start(ctx)
+	go chainWorker.Start(ctx)
+	go idempotencySweeper.Start(ctx)
+
+	// e.g. OVERDRAFT_DAILY_FEE=500 charges 5.00 a day while overdrawn; unset disables it
+	if v := os.Getenv("OVERDRAFT_DAILY_FEE"); v != "" {
+		fee, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || fee <= 0 {
+			log.Fatal("OVERDRAFT_DAILY_FEE must be a positive number of cents")
+		}
+		go transaction.NewOverdraftFeeWorker(transactionService, fee).Start(ctx)
+	}
+
+	// gRPC Server
+	grpcServer := grpc.NewServer()
+	pb.RegisterTransactionServiceServer(grpcServer, transactionGrpc.NewGrpcTransactionServer(transactionService))
+
+	grpcAddr := os.Getenv("TRANSACTION_SERVICE_GRPC_ADDR")
+	if grpcAddr == "" {
+		grpcAddr = ":50052"
+	}
+
+	go func() {
+		listener, err := net.Listen("tcp", grpcAddr)
+		if err != nil {
+			log.Fatalf("Failed to listen on %s: %v", grpcAddr, err)
+		}
+		log.Println("🚀 Transaction gRPC Server running on", grpcAddr)
+		if err := grpcServer.Serve(listener); err != nil {
+			log.Fatalf("Failed to serve gRPC: %v", err)
+		}
+	}()
 
 	log.Println("🚀 Transaction Service running on :8080")
 	log.Fatal(http.ListenAndServe(":8080", router))
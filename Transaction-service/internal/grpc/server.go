@@ -0,0 +1,247 @@
+package grpc
+
+import (
+	"context"
+	"strings"
+	"time"
+	"transaction/internal/transaction"
+	"transaction/pb"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// IdempotencyKeyHeader is the metadata key write calls read their
+// idempotency key from.
+const IdempotencyKeyHeader = "idempotency-key"
+
+type GrpcTransactionServer struct {
+	pb.UnimplementedTransactionServiceServer
+	service transaction.TransactionService
+}
+
+func NewGrpcTransactionServer(service transaction.TransactionService) *GrpcTransactionServer {
+	return &GrpcTransactionServer{
+		service: service,
+	}
+}
+
+func (s *GrpcTransactionServer) Deposit(ctx context.Context, req *pb.DepositRequest) (*pb.DepositResponse, error) {
+	if err := s.service.Deposit(
+		ctx,
+		idempotencyKey(ctx),
+		req.AccountId,
+		req.Amount,
+		req.Currency,
+		req.Note,
+		transaction.Labels{Metadata: req.Metadata, Tags: req.Tags},
+	); err != nil {
+		return nil, transaction.GRPCError(err)
+	}
+
+	return &pb.DepositResponse{}, nil
+}
+
+func (s *GrpcTransactionServer) Withdraw(ctx context.Context, req *pb.WithdrawRequest) (*pb.WithdrawResponse, error) {
+	fees, err := s.service.Withdraw(
+		ctx,
+		idempotencyKey(ctx),
+		req.AccountId,
+		req.Amount,
+		req.Currency,
+		req.Note,
+		transaction.Labels{Metadata: req.Metadata, Tags: req.Tags},
+	)
+	if err != nil {
+		return nil, transaction.GRPCError(err)
+	}
+
+	return &pb.WithdrawResponse{Fees: feeList(fees)}, nil
+}
+
+func (s *GrpcTransactionServer) Transfer(ctx context.Context, req *pb.TransferRequest) (*pb.TransferResponse, error) {
+	fees, err := s.service.Transfer(
+		ctx,
+		idempotencyKey(ctx),
+		req.FromAccountId,
+		req.ToAccountId,
+		req.Amount,
+		req.Currency,
+		req.Note,
+		transaction.Labels{Metadata: req.Metadata, Tags: req.Tags},
+	)
+	if err != nil {
+		return nil, transaction.GRPCError(err)
+	}
+
+	return &pb.TransferResponse{Fees: feeList(fees)}, nil
+}
+
+func feeList(fees []transaction.Fee) []*pb.Fee {
+	var list []*pb.Fee
+	for _, f := range fees {
+		list = append(list, &pb.Fee{
+			RuleId:        f.RuleID,
+			Name:          f.Name,
+			Amount:        f.Amount,
+			Currency:      f.Currency,
+			TransactionId: f.TransactionID,
+		})
+	}
+	return list
+}
+
+func (s *GrpcTransactionServer) GetBalance(ctx context.Context, req *pb.GetBalanceRequest) (*pb.GetBalanceResponse, error) {
+	if req.AsOf != "" {
+		return s.balanceAsOf(ctx, req)
+	}
+
+	balances, err := s.service.Balance(ctx, req.AccountId)
+	if err != nil {
+		return nil, transaction.GRPCError(err)
+	}
+
+	resp := &pb.GetBalanceResponse{AccountId: req.AccountId}
+	for _, b := range balances {
+		resp.Balances = append(resp.Balances, &pb.Balance{
+			Currency:  b.Currency,
+			Ledger:    b.Ledger,
+			Available: b.Available,
+		})
+	}
+
+	return resp, nil
+}
+
+func (s *GrpcTransactionServer) balanceAsOf(ctx context.Context, req *pb.GetBalanceRequest) (*pb.GetBalanceResponse, error) {
+	asOf, err := time.Parse(time.RFC3339Nano, req.AsOf)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "as_of must be an RFC 3339 timestamp")
+	}
+
+	balances, err := s.service.BalanceAsOf(ctx, req.AccountId, asOf)
+	if err != nil {
+		return nil, transaction.GRPCError(err)
+	}
+
+	resp := &pb.GetBalanceResponse{AccountId: req.AccountId, AsOf: asOf.UTC().Format(time.RFC3339Nano)}
+	for _, b := range balances {
+		resp.Balances = append(resp.Balances, &pb.Balance{
+			Currency: b.Currency,
+			Ledger:   b.Ledger,
+		})
+	}
+
+	return resp, nil
+}
+
+// ListHistory walks the history page by page, so the stream never holds
+// more than one page in memory. Entries carry their own balance; the as_of
+// balances of the HTTP history are not sent.
+func (s *GrpcTransactionServer) ListHistory(req *pb.ListHistoryRequest, stream pb.TransactionService_ListHistoryServer) error {
+	filter, err := historyFilter(req)
+	if err != nil {
+		return err
+	}
+
+	var sendErr error
+	err = s.service.EachHistory(stream.Context(), req.AccountId, filter, func(t transaction.Transaction) error {
+		sendErr = stream.Send(historyEntry(t))
+		return sendErr
+	})
+	if sendErr != nil {
+		return sendErr
+	}
+	if err != nil {
+		return transaction.GRPCError(err)
+	}
+	return nil
+}
+
+func historyFilter(req *pb.ListHistoryRequest) (transaction.HistoryFilter, error) {
+	filter := transaction.HistoryFilter{
+		Cursor:       req.Cursor,
+		Limit:        transaction.MaxHistoryLimit,
+		NoteContains: req.NoteContains,
+		Metadata:     req.Metadata,
+	}
+
+	for _, tag := range req.Tags {
+		filter.Tags = append(filter.Tags, strings.ToLower(strings.TrimSpace(tag)))
+	}
+
+	for _, t := range req.Types {
+		typ := transaction.Type(t)
+		if !typ.Valid() {
+			return filter, status.Errorf(codes.InvalidArgument, "unknown transaction type %q", t)
+		}
+		filter.Types = append(filter.Types, typ)
+	}
+
+	if req.From != "" {
+		from, err := time.Parse(time.RFC3339Nano, req.From)
+		if err != nil {
+			return filter, status.Error(codes.InvalidArgument, "from must be an RFC 3339 timestamp")
+		}
+		filter.From = &from
+	}
+
+	if req.To != "" {
+		to, err := time.Parse(time.RFC3339Nano, req.To)
+		if err != nil {
+			return filter, status.Error(codes.InvalidArgument, "to must be an RFC 3339 timestamp")
+		}
+		filter.To = &to
+	}
+
+	if req.AsOf != "" {
+		asOf, err := time.Parse(time.RFC3339Nano, req.AsOf)
+		if err != nil {
+			return filter, status.Error(codes.InvalidArgument, "as_of must be an RFC 3339 timestamp")
+		}
+		filter.AsOf = &asOf
+	}
+
+	if req.MinAmount > 0 {
+		filter.MinAmount = &req.MinAmount
+	}
+
+	if req.MaxAmount > 0 {
+		filter.MaxAmount = &req.MaxAmount
+	}
+
+	return filter, nil
+}
+
+func historyEntry(t transaction.Transaction) *pb.HistoryEntry {
+	return &pb.HistoryEntry{
+		Id:           t.ID,
+		AccountId:    t.AccountID,
+		JournalId:    t.JournalID,
+		Type:         string(t.Type),
+		Amount:       t.Amount,
+		Currency:     t.Currency,
+		BalanceAfter: t.BalanceAfter,
+		ReversalOf:   t.ReversalOf,
+		Note:         t.Note,
+		CreatedAt:    t.CreatedAt.Format(time.RFC3339Nano),
+		Cursor:       t.Cursor(),
+		Metadata:     t.Metadata,
+		Tags:         t.Tags,
+
+		CounterpartyAccountId: t.CounterpartyID,
+	}
+}
+
+func idempotencyKey(ctx context.Context) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ""
+	}
+
+	if keys := md.Get(IdempotencyKeyHeader); len(keys) > 0 {
+		return keys[0]
+	}
+	return ""
+}
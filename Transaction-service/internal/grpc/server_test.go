@@ -0,0 +1,209 @@
+package grpc_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"testing"
+	"time"
+	transactionGrpc "transaction/internal/grpc"
+	"transaction/internal/transaction"
+	"transaction/pb"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// fakeService implements the calls under test; the embedded interface
+// panics for anything else.
+type fakeService struct {
+	transaction.TransactionService
+
+	depositKey    string
+	depositLabels transaction.Labels
+	depositErr    error
+	pages         map[string]*transaction.HistoryPage // by cursor
+	historyCalls  int
+	asOf          time.Time
+}
+
+func (f *fakeService) Deposit(ctx context.Context, key string, accountID, amount int64, currency, note string, labels transaction.Labels) error {
+	f.depositKey = key
+	f.depositLabels = labels
+	return f.depositErr
+}
+
+func (f *fakeService) EachHistory(ctx context.Context, accountID int64, filter transaction.HistoryFilter, fn func(transaction.Transaction) error) error {
+	f.historyCalls++
+	for {
+		page := f.pages[filter.Cursor]
+		for _, t := range page.Entries {
+			if err := fn(t); err != nil {
+				return err
+			}
+		}
+		if page.NextCursor == "" {
+			return nil
+		}
+		filter.Cursor = page.NextCursor
+	}
+}
+
+func (f *fakeService) BalanceAsOf(ctx context.Context, accountID int64, asOf time.Time) ([]transaction.HistoricalBalance, error) {
+	f.asOf = asOf
+	return []transaction.HistoricalBalance{{Currency: "USD", Ledger: 1_250, AsOf: asOf}}, nil
+}
+
+func dial(t *testing.T, service transaction.TransactionService) pb.TransactionServiceClient {
+	t.Helper()
+
+	lis := bufconn.Listen(1 << 20)
+	srv := grpc.NewServer()
+	pb.RegisterTransactionServiceServer(srv, transactionGrpc.NewGrpcTransactionServer(service))
+	go srv.Serve(lis)
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.NewClient(
+		"passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	return pb.NewTransactionServiceClient(conn)
+}
+
+func TestDeposit_ReadsIdempotencyKeyFromMetadata(t *testing.T) {
+	service := &fakeService{}
+	client := dial(t, service)
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(), transactionGrpc.IdempotencyKeyHeader, "grpc-key")
+	if _, err := client.Deposit(ctx, &pb.DepositRequest{AccountId: 1, Amount: 500}); err != nil {
+		t.Fatal(err)
+	}
+
+	if service.depositKey != "grpc-key" {
+		t.Fatalf("expected idempotency key grpc-key, got %q", service.depositKey)
+	}
+}
+
+func TestDeposit_PassesMetadataAndTags(t *testing.T) {
+	service := &fakeService{}
+	client := dial(t, service)
+
+	_, err := client.Deposit(context.Background(), &pb.DepositRequest{
+		AccountId: 1,
+		Amount:    500,
+		Metadata:  map[string]string{"invoice_id": "123"},
+		Tags:      []string{"payroll"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if service.depositLabels.Metadata["invoice_id"] != "123" || len(service.depositLabels.Tags) != 1 || service.depositLabels.Tags[0] != "payroll" {
+		t.Fatalf("unexpected labels %+v", service.depositLabels)
+	}
+}
+
+func TestDeposit_MapsDomainErrors(t *testing.T) {
+	service := &fakeService{depositErr: &transaction.AccountError{AccountID: 1, Err: transaction.ErrAccountNotFound}}
+	client := dial(t, service)
+
+	_, err := client.Deposit(context.Background(), &pb.DepositRequest{AccountId: 1, Amount: 500})
+	if status.Code(err) != codes.NotFound {
+		t.Fatalf("expected NotFound, got %v", err)
+	}
+
+	service.depositErr = transaction.ErrIdempotencyKeyReused
+	_, err = client.Deposit(context.Background(), &pb.DepositRequest{AccountId: 1, Amount: 500})
+	if status.Code(err) != codes.AlreadyExists {
+		t.Fatalf("expected AlreadyExists, got %v", err)
+	}
+
+	service.depositErr = errors.New("disk on fire")
+	_, err = client.Deposit(context.Background(), &pb.DepositRequest{AccountId: 1, Amount: 500})
+	if status.Code(err) != codes.Internal {
+		t.Fatalf("expected Internal, got %v", err)
+	}
+}
+
+func TestListHistory_StreamsAllPages(t *testing.T) {
+	service := &fakeService{pages: map[string]*transaction.HistoryPage{
+		"": {
+			Entries:    []transaction.Transaction{{ID: 3}, {ID: 2}},
+			NextCursor: "page-2",
+		},
+		"page-2": {
+			Entries: []transaction.Transaction{{ID: 1}},
+		},
+	}}
+	client := dial(t, service)
+
+	stream, err := client.ListHistory(context.Background(), &pb.ListHistoryRequest{AccountId: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var ids []int64
+	for {
+		entry, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, entry.Id)
+	}
+
+	if len(ids) != 3 || ids[0] != 3 || ids[2] != 1 {
+		t.Fatalf("expected entries 3, 2, 1, got %v", ids)
+	}
+	if service.historyCalls != 1 {
+		t.Fatalf("expected the history to be walked once, got %d", service.historyCalls)
+	}
+}
+
+func TestGetBalance_AsOf(t *testing.T) {
+	service := &fakeService{}
+	client := dial(t, service)
+
+	resp, err := client.GetBalance(context.Background(), &pb.GetBalanceRequest{AccountId: 1, AsOf: "2026-03-31T23:59:59Z"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !service.asOf.Equal(time.Date(2026, 3, 31, 23, 59, 59, 0, time.UTC)) {
+		t.Fatalf("unexpected as_of passed to the service: %v", service.asOf)
+	}
+
+	if len(resp.Balances) != 1 || resp.Balances[0].Ledger != 1_250 || resp.AsOf != "2026-03-31T23:59:59Z" {
+		t.Fatalf("unexpected response %v", resp)
+	}
+
+	if _, err := client.GetBalance(context.Background(), &pb.GetBalanceRequest{AccountId: 1, AsOf: "yesterday"}); status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("expected InvalidArgument, got %v", err)
+	}
+}
+
+func TestListHistory_RejectsUnknownType(t *testing.T) {
+	client := dial(t, &fakeService{})
+
+	stream, err := client.ListHistory(context.Background(), &pb.ListHistoryRequest{AccountId: 1, Types: []string{"GIFT"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := stream.Recv(); status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("expected InvalidArgument, got %v", err)
+	}
+}
@@ -0,0 +1,123 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"expvar"
+	"fmt"
+	"math/rand/v2"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// ErrRetriesExhausted wraps the last error of a transaction that kept
+// conflicting until its attempts ran out.
+var ErrRetriesExhausted = errors.New("transaction kept conflicting with concurrent writes")
+
+// txMetrics is published at /debug/vars under "db_transactions":
+// "retries.<operation>" counts retried attempts, "exhausted.<operation>"
+// transactions that gave up, and "conflicts.<sqlstate>" the failures that
+// caused them.
+var txMetrics = expvar.NewMap("db_transactions")
+
+// RetryPolicy bounds how a TxRunner retries. Attempt n waits a random
+// delay of up to BaseDelay*2^(n-1), capped at MaxDelay.
+type RetryPolicy struct {
+	MaxAttempts int
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+}
+
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 5,
+	BaseDelay:   10 * time.Millisecond,
+	MaxDelay:    500 * time.Millisecond,
+}
+
+// Retryable reports whether Postgres aborted a transaction in a way that
+// may succeed if it is run again: a serialization failure (40001) or a
+// deadlock (40P01).
+func Retryable(err error) bool {
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) {
+		return false
+	}
+	return pqErr.Code == "40001" || pqErr.Code == "40P01"
+}
+
+// TxRunner runs functions in transactions, retrying the whole transaction
+// when Postgres aborts it with a retryable error.
+type TxRunner struct {
+	db     *sql.DB
+	policy RetryPolicy
+}
+
+func NewTxRunner(db *sql.DB, policy RetryPolicy) *TxRunner {
+	if policy.MaxAttempts < 1 {
+		policy.MaxAttempts = 1
+	}
+	return &TxRunner{db: db, policy: policy}
+}
+
+// Run begins a transaction, calls fn with it and commits it if fn returns
+// nil; any error rolls it back. fn may run several times, so it must not
+// keep state from a previous attempt. operation names the transaction in
+// metrics.
+func (r *TxRunner) Run(ctx context.Context, operation string, opts *sql.TxOptions, fn func(tx *sql.Tx) error) error {
+	for attempt := 1; ; attempt++ {
+		err := r.run(ctx, opts, fn)
+		if !Retryable(err) {
+			return err
+		}
+
+		var pqErr *pq.Error
+		errors.As(err, &pqErr)
+		txMetrics.Add("conflicts."+string(pqErr.Code), 1)
+
+		if attempt == r.policy.MaxAttempts {
+			txMetrics.Add("exhausted."+operation, 1)
+			return fmt.Errorf("%w: %s after %d attempts: %w", ErrRetriesExhausted, operation, attempt, err)
+		}
+
+		txMetrics.Add("retries."+operation, 1)
+
+		timer := time.NewTimer(r.backoff(attempt))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+func (r *TxRunner) run(ctx context.Context, opts *sql.TxOptions, fn func(tx *sql.Tx) error) error {
+	tx, err := r.db.BeginTx(ctx, opts)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// backoff returns a random delay for the retry after attempt, so
+// conflicting writers do not collide again in lockstep.
+func (r *TxRunner) backoff(attempt int) time.Duration {
+	ceiling := r.policy.MaxDelay
+	if shift := attempt - 1; shift < 30 {
+		if d := r.policy.BaseDelay << shift; d < ceiling {
+			ceiling = d
+		}
+	}
+	if ceiling <= 0 {
+		return 0
+	}
+	return rand.N(ceiling) + 1
+}
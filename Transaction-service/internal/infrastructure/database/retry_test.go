@@ -0,0 +1,166 @@
+package database_test
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"expvar"
+	"fmt"
+	"testing"
+	"time"
+	"transaction/internal/infrastructure/database"
+
+	"github.com/lib/pq"
+)
+
+func TestRetryable(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"serialization failure", &pq.Error{Code: "40001"}, true},
+		{"deadlock", &pq.Error{Code: "40P01"}, true},
+		{"wrapped", fmt.Errorf("account 1: %w", &pq.Error{Code: "40001"}), true},
+		{"unique violation", &pq.Error{Code: "23505"}, false},
+		{"not a postgres error", errors.New("boom"), false},
+		{"nil", nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := database.Retryable(tt.err); got != tt.want {
+				t.Fatalf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+// stubDriver hands out connections whose transactions do nothing but count
+// how they ended, so TxRunner can be exercised without a database.
+type stubDriver struct {
+	commits, rollbacks int
+}
+
+func (d *stubDriver) Open(string) (driver.Conn, error) { return &stubConn{d: d}, nil }
+
+func (d *stubDriver) Connect(context.Context) (driver.Conn, error) { return d.Open("") }
+
+func (d *stubDriver) Driver() driver.Driver { return d }
+
+type stubConn struct{ d *stubDriver }
+
+func (c *stubConn) Prepare(string) (driver.Stmt, error) { return nil, errors.New("not supported") }
+func (c *stubConn) Close() error                        { return nil }
+func (c *stubConn) Begin() (driver.Tx, error)           { return &stubTx{d: c.d}, nil }
+
+type stubTx struct{ d *stubDriver }
+
+func (t *stubTx) Commit() error   { t.d.commits++; return nil }
+func (t *stubTx) Rollback() error { t.d.rollbacks++; return nil }
+
+func stubRunner(t *testing.T, policy database.RetryPolicy) (*database.TxRunner, *stubDriver) {
+	d := &stubDriver{}
+	db := sql.OpenDB(d)
+	t.Cleanup(func() { db.Close() })
+
+	return database.NewTxRunner(db, policy), d
+}
+
+func txMetric(key string) int64 {
+	v, ok := expvar.Get("db_transactions").(*expvar.Map).Get(key).(*expvar.Int)
+	if !ok {
+		return 0
+	}
+	return v.Value()
+}
+
+var fastRetries = database.RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond}
+
+func TestTxRunner_RetriesUntilCommitted(t *testing.T) {
+	runner, d := stubRunner(t, fastRetries)
+	retries, conflicts := txMetric("retries.retry_until_committed"), txMetric("conflicts.40001")
+
+	attempts := 0
+	err := runner.Run(context.Background(), "retry_until_committed", nil, func(*sql.Tx) error {
+		attempts++
+		if attempts < 3 {
+			return &pq.Error{Code: "40001"}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if attempts != 3 || d.commits != 1 || d.rollbacks != 2 {
+		t.Fatalf("expected 3 attempts, 1 commit and 2 rollbacks, got %d, %d and %d", attempts, d.commits, d.rollbacks)
+	}
+
+	if got := txMetric("retries.retry_until_committed") - retries; got != 2 {
+		t.Fatalf("expected 2 retries counted, got %d", got)
+	}
+	if got := txMetric("conflicts.40001") - conflicts; got != 2 {
+		t.Fatalf("expected 2 conflicts counted, got %d", got)
+	}
+}
+
+func TestTxRunner_GivesUpAfterMaxAttempts(t *testing.T) {
+	runner, d := stubRunner(t, fastRetries)
+	retries, exhausted := txMetric("retries.gives_up"), txMetric("exhausted.gives_up")
+
+	attempts := 0
+	err := runner.Run(context.Background(), "gives_up", nil, func(*sql.Tx) error {
+		attempts++
+		return &pq.Error{Code: "40P01"}
+	})
+
+	var pqErr *pq.Error
+	if !errors.Is(err, database.ErrRetriesExhausted) || !errors.As(err, &pqErr) || pqErr.Code != "40P01" {
+		t.Fatalf("expected ErrRetriesExhausted wrapping the deadlock, got %v", err)
+	}
+
+	if attempts != 3 || d.commits != 0 {
+		t.Fatalf("expected 3 attempts and no commit, got %d and %d", attempts, d.commits)
+	}
+
+	if got := txMetric("exhausted.gives_up") - exhausted; got != 1 {
+		t.Fatalf("expected 1 exhausted transaction counted, got %d", got)
+	}
+	if got := txMetric("retries.gives_up") - retries; got != 2 {
+		t.Fatalf("expected 2 retries counted, got %d", got)
+	}
+}
+
+func TestTxRunner_DoesNotRetryOtherErrors(t *testing.T) {
+	runner, _ := stubRunner(t, fastRetries)
+	boom := errors.New("boom")
+
+	attempts := 0
+	err := runner.Run(context.Background(), "other_error", nil, func(*sql.Tx) error {
+		attempts++
+		return boom
+	})
+
+	if err != boom || attempts != 1 {
+		t.Fatalf("expected the error back after 1 attempt, got %v after %d", err, attempts)
+	}
+}
+
+func TestTxRunner_StopsWhenContextIsDone(t *testing.T) {
+	runner, _ := stubRunner(t, database.RetryPolicy{MaxAttempts: 5, BaseDelay: time.Hour, MaxDelay: time.Hour})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	attempts := 0
+	err := runner.Run(ctx, "cancelled", nil, func(*sql.Tx) error {
+		attempts++
+		cancel() // cancelled while the retry waits out its backoff
+		return &pq.Error{Code: "40001"}
+	})
+
+	if !errors.Is(err, context.Canceled) || attempts != 1 {
+		t.Fatalf("expected context.Canceled after 1 attempt, got %v after %d", err, attempts)
+	}
+}
@@ -1,22 +1,28 @@
 package http
 
 import (
+	"expvar"
 	"net/http"
 
 	"github.com/go-chi/chi"
 	"github.com/go-chi/chi/middleware"
 )
 
-func NewRouter (transactionHandler http.Handler) http.Handler {
+func NewRouter (transactionHandler http.Handler, scheduleHandler http.Handler, statementHandler http.Handler) http.Handler {
 
 	r := chi.NewRouter()
 
 	r.Use(middleware.Logger)
 	r.Use(middleware.Recoverer)
 
+	// runtime and service counters, e.g. idempotency replays
+	r.Handle("/debug/vars", expvar.Handler())
+
 	r.Route("/api/v1", func(r chi.Router) {
 		//r.Mount("/accounts", accountHandler)
 		r.Mount("/transactions", transactionHandler)
+		r.Mount("/schedules", scheduleHandler)
+		r.Mount("/accounts", statementHandler)
 	})
 
 	return r
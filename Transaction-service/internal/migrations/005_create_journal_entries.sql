@@ -0,0 +1,99 @@
+CREATE TABLE journal_entries (
+    id BIGSERIAL PRIMARY KEY,
+
+    type TEXT NOT NULL CHECK (
+        type IN (
+            'DEPOSIT',
+            'WITHDRAW',
+            'TRANSFER'
+        )
+    ),
+
+    note TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT now()
+);
+
+-- amount is signed: positive credits the ledger account, negative debits it.
+-- A posting targets either a customer account or a system account.
+CREATE TABLE postings (
+    id BIGSERIAL PRIMARY KEY,
+
+    journal_id BIGINT NOT NULL
+        REFERENCES journal_entries(id)
+        ON DELETE CASCADE,
+
+    account_id BIGINT
+        REFERENCES accounts(id)
+        ON DELETE CASCADE,
+
+    system_account TEXT CHECK (
+        system_account IN (
+            'CASH',
+            'CLEARING'
+        )
+    ),
+
+    amount BIGINT NOT NULL CHECK (amount <> 0),
+
+    CHECK ((account_id IS NULL) <> (system_account IS NULL))
+);
+
+CREATE INDEX idx_postings_journal_id ON postings(journal_id);
+CREATE INDEX idx_postings_account_id ON postings(account_id);
+
+ALTER TABLE transactions
+    ADD COLUMN journal_id BIGINT REFERENCES journal_entries(id);
+
+CREATE INDEX idx_transactions_journal_id ON transactions(journal_id);
+
+-- Reject any journal whose postings do not sum to zero once the DB
+-- transaction that wrote it commits.
+CREATE FUNCTION check_journal_balanced() RETURNS trigger AS $$
+DECLARE
+    jid BIGINT := COALESCE(NEW.journal_id, OLD.journal_id);
+BEGIN
+    IF (SELECT COALESCE(SUM(amount), 0) FROM postings WHERE journal_id = jid) <> 0 THEN
+        RAISE EXCEPTION 'journal % is not balanced', jid;
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE CONSTRAINT TRIGGER postings_balanced
+    AFTER INSERT OR UPDATE OR DELETE ON postings
+    DEFERRABLE INITIALLY DEFERRED
+    FOR EACH ROW EXECUTE FUNCTION check_journal_balanced();
+
+-- Backfill: give every existing single-sided row its own balanced journal.
+-- Legacy transfer legs each balance against clearing, which nets to zero
+-- once both legs are present.
+DO $$
+DECLARE
+    t RECORD;
+    jid BIGINT;
+BEGIN
+    FOR t IN SELECT * FROM transactions WHERE journal_id IS NULL ORDER BY id LOOP
+        INSERT INTO journal_entries (type, note, created_at)
+        VALUES (
+            CASE t.type
+                WHEN 'DEPOSIT' THEN 'DEPOSIT'
+                WHEN 'WITHDRAW' THEN 'WITHDRAW'
+                ELSE 'TRANSFER'
+            END,
+            t.note,
+            t.created_at
+        )
+        RETURNING id INTO jid;
+
+        INSERT INTO postings (journal_id, account_id, system_account, amount)
+        VALUES
+            (jid, t.account_id, NULL,
+                CASE WHEN t.type IN ('DEPOSIT', 'TRANSFER_IN') THEN t.amount ELSE -t.amount END),
+            (jid, NULL,
+                CASE WHEN t.type IN ('DEPOSIT', 'WITHDRAW') THEN 'CASH' ELSE 'CLEARING' END,
+                CASE WHEN t.type IN ('DEPOSIT', 'TRANSFER_IN') THEN -t.amount ELSE t.amount END);
+
+        UPDATE transactions SET journal_id = jid WHERE id = t.id;
+    END LOOP;
+END;
+$$;
@@ -0,0 +1,29 @@
+CREATE TABLE account_balances (
+    account_id BIGINT PRIMARY KEY
+        REFERENCES accounts(id)
+        ON DELETE CASCADE,
+
+    balance BIGINT NOT NULL DEFAULT 0,
+    updated_at TIMESTAMP NOT NULL DEFAULT now()
+);
+
+ALTER TABLE transactions ADD COLUMN balance_after BIGINT;
+
+-- Backfill running balances in ledger order, then the per-account totals.
+UPDATE transactions t
+SET balance_after = r.balance_after
+FROM (
+    SELECT id,
+           SUM(CASE WHEN type IN ('DEPOSIT', 'TRANSFER_IN') THEN amount ELSE -amount END)
+               OVER (PARTITION BY account_id ORDER BY created_at, id) AS balance_after
+    FROM transactions
+) r
+WHERE t.id = r.id;
+
+INSERT INTO account_balances (account_id, balance)
+SELECT account_id,
+       SUM(CASE WHEN type IN ('DEPOSIT', 'TRANSFER_IN') THEN amount ELSE -amount END)
+FROM transactions
+GROUP BY account_id;
+
+ALTER TABLE transactions ALTER COLUMN balance_after SET NOT NULL;
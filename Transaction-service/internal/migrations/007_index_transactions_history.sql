@@ -0,0 +1,3 @@
+-- Supports keyset pagination of an account's history, newest first.
+CREATE INDEX idx_transactions_account_history
+ON transactions (account_id, created_at DESC, id DESC);
@@ -0,0 +1,29 @@
+ALTER TABLE journal_entries
+    ADD COLUMN reverses_journal_id BIGINT REFERENCES journal_entries(id);
+
+ALTER TABLE journal_entries DROP CONSTRAINT journal_entries_type_check;
+ALTER TABLE journal_entries ADD CONSTRAINT journal_entries_type_check CHECK (
+    type IN (
+        'DEPOSIT',
+        'WITHDRAW',
+        'TRANSFER',
+        'REVERSAL'
+    )
+);
+
+ALTER TABLE transactions
+    ADD COLUMN reversal_of BIGINT REFERENCES transactions(id);
+
+ALTER TABLE transactions DROP CONSTRAINT transactions_type_check;
+ALTER TABLE transactions ADD CONSTRAINT transactions_type_check CHECK (
+    type IN (
+        'DEPOSIT',
+        'WITHDRAW',
+        'TRANSFER_IN',
+        'TRANSFER_OUT',
+        'REVERSAL_IN',
+        'REVERSAL_OUT'
+    )
+);
+
+CREATE INDEX idx_transactions_reversal_of ON transactions(reversal_of);
@@ -0,0 +1,17 @@
+-- Every journal and ledger entry is denominated in one ISO 4217 currency.
+-- Rows written before currencies existed are USD.
+ALTER TABLE journal_entries
+    ADD COLUMN currency TEXT NOT NULL DEFAULT 'USD'
+        CHECK (currency ~ '^[A-Z]{3}$');
+
+ALTER TABLE transactions
+    ADD COLUMN currency TEXT NOT NULL DEFAULT 'USD'
+        CHECK (currency ~ '^[A-Z]{3}$');
+
+-- Balances are kept per account and currency.
+ALTER TABLE account_balances
+    ADD COLUMN currency TEXT NOT NULL DEFAULT 'USD'
+        CHECK (currency ~ '^[A-Z]{3}$');
+
+ALTER TABLE account_balances DROP CONSTRAINT account_balances_pkey;
+ALTER TABLE account_balances ADD PRIMARY KEY (account_id, currency);
@@ -0,0 +1,37 @@
+CREATE TABLE holds (
+    id BIGSERIAL PRIMARY KEY,
+
+    account_id BIGINT NOT NULL
+        REFERENCES accounts(id)
+        ON DELETE CASCADE,
+
+    amount BIGINT NOT NULL CHECK (amount > 0),
+    captured_amount BIGINT NOT NULL DEFAULT 0 CHECK (captured_amount >= 0),
+    currency TEXT NOT NULL CHECK (currency ~ '^[A-Z]{3}$'),
+
+    status TEXT NOT NULL DEFAULT 'ACTIVE' CHECK (
+        status IN (
+            'ACTIVE',
+            'CAPTURED',
+            'RELEASED',
+            'EXPIRED'
+        )
+    ),
+
+    note TEXT NOT NULL DEFAULT '',
+    transaction_id BIGINT REFERENCES transactions(id),
+
+    expires_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT now(),
+    updated_at TIMESTAMP NOT NULL DEFAULT now(),
+
+    CHECK (captured_amount <= amount)
+);
+
+CREATE INDEX idx_holds_account_active
+ON holds (account_id, currency)
+WHERE status = 'ACTIVE';
+
+CREATE INDEX idx_holds_expiry
+ON holds (expires_at)
+WHERE status = 'ACTIVE';
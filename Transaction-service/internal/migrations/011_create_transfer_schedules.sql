@@ -0,0 +1,82 @@
+CREATE TABLE transfer_schedules (
+    id BIGSERIAL PRIMARY KEY,
+
+    from_account_id BIGINT NOT NULL
+        REFERENCES accounts(id)
+        ON DELETE CASCADE,
+
+    to_account_id BIGINT NOT NULL
+        REFERENCES accounts(id)
+        ON DELETE CASCADE,
+
+    amount BIGINT NOT NULL CHECK (amount > 0),
+    currency TEXT NOT NULL DEFAULT '' CHECK (currency = '' OR currency ~ '^[A-Z]{3}$'),
+    note TEXT NOT NULL DEFAULT '',
+
+    frequency TEXT NOT NULL CHECK (
+        frequency IN (
+            'ONCE',
+            'DAILY',
+            'WEEKLY',
+            'MONTHLY'
+        )
+    ),
+
+    start_at TIMESTAMP NOT NULL,
+    end_at TIMESTAMP,
+    max_runs INT CHECK (max_runs > 0),
+
+    run_count INT NOT NULL DEFAULT 0,
+    attempt INT NOT NULL DEFAULT 0,
+    next_run_at TIMESTAMP,
+    locked_until TIMESTAMP,
+
+    status TEXT NOT NULL DEFAULT 'ACTIVE' CHECK (
+        status IN (
+            'ACTIVE',
+            'PAUSED',
+            'COMPLETED',
+            'CANCELLED'
+        )
+    ),
+
+    created_at TIMESTAMP NOT NULL DEFAULT now(),
+    updated_at TIMESTAMP NOT NULL DEFAULT now(),
+
+    CHECK (from_account_id <> to_account_id)
+);
+
+CREATE INDEX idx_transfer_schedules_due
+ON transfer_schedules (next_run_at)
+WHERE status = 'ACTIVE';
+
+CREATE INDEX idx_transfer_schedules_from
+ON transfer_schedules (from_account_id);
+
+CREATE INDEX idx_transfer_schedules_to
+ON transfer_schedules (to_account_id);
+
+CREATE TABLE transfer_schedule_runs (
+    id BIGSERIAL PRIMARY KEY,
+
+    schedule_id BIGINT NOT NULL
+        REFERENCES transfer_schedules(id)
+        ON DELETE CASCADE,
+
+    scheduled_for TIMESTAMP NOT NULL,
+    attempt INT NOT NULL,
+
+    status TEXT NOT NULL CHECK (
+        status IN (
+            'SUCCEEDED',
+            'RETRYING',
+            'FAILED'
+        )
+    ),
+
+    error TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_transfer_schedule_runs_schedule
+ON transfer_schedule_runs (schedule_id, id);
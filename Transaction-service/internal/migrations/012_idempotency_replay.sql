@@ -0,0 +1,20 @@
+-- Store the original response verbatim (JSONB would reorder keys), a hash
+-- of the request and whether the request is still in progress.
+ALTER TABLE idempotency_keys
+    ALTER COLUMN response DROP NOT NULL,
+    ALTER COLUMN response TYPE BYTEA USING convert_to(response::text, 'UTF8'),
+    ADD COLUMN request_hash TEXT,
+    ADD COLUMN status TEXT NOT NULL DEFAULT 'COMPLETED' CHECK (
+        status IN (
+            'IN_PROGRESS',
+            'COMPLETED'
+        )
+    ),
+    ADD COLUMN response_code INT,
+    ADD COLUMN applied_at TIMESTAMP,
+    ADD COLUMN locked_at TIMESTAMP NOT NULL DEFAULT now();
+
+-- existing keys were only ever written by committed, successful requests
+UPDATE idempotency_keys
+SET applied_at = created_at,
+    response_code = 201;
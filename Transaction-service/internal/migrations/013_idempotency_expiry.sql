@@ -0,0 +1,8 @@
+ALTER TABLE idempotency_keys
+    ADD COLUMN expires_at TIMESTAMP NOT NULL DEFAULT now() + INTERVAL '24 hours';
+
+UPDATE idempotency_keys
+SET expires_at = created_at + INTERVAL '24 hours';
+
+CREATE INDEX idx_idempotency_keys_expires_at
+ON idempotency_keys (expires_at);
@@ -0,0 +1,6 @@
+ALTER TABLE journal_entries
+    ADD COLUMN batch_id UUID;
+
+CREATE INDEX idx_journal_entries_batch
+ON journal_entries (batch_id)
+WHERE batch_id IS NOT NULL;
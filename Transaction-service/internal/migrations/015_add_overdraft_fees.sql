@@ -0,0 +1,42 @@
+ALTER TABLE journal_entries DROP CONSTRAINT journal_entries_type_check;
+ALTER TABLE journal_entries ADD CONSTRAINT journal_entries_type_check CHECK (
+    type IN (
+        'DEPOSIT',
+        'WITHDRAW',
+        'TRANSFER',
+        'REVERSAL',
+        'FEE'
+    )
+);
+
+ALTER TABLE postings DROP CONSTRAINT postings_system_account_check;
+ALTER TABLE postings ADD CONSTRAINT postings_system_account_check CHECK (
+    system_account IN (
+        'CASH',
+        'CLEARING',
+        'FEE_INCOME'
+    )
+);
+
+ALTER TABLE transactions DROP CONSTRAINT transactions_type_check;
+ALTER TABLE transactions ADD CONSTRAINT transactions_type_check CHECK (
+    type IN (
+        'DEPOSIT',
+        'WITHDRAW',
+        'TRANSFER_IN',
+        'TRANSFER_OUT',
+        'REVERSAL_IN',
+        'REVERSAL_OUT',
+        'FEE'
+    )
+);
+
+-- One overdraft fee per overdrawn balance and day.
+CREATE TABLE overdraft_fees (
+    account_id BIGINT NOT NULL REFERENCES accounts(id) ON DELETE CASCADE,
+    currency TEXT NOT NULL,
+    charged_on DATE NOT NULL,
+    transaction_id BIGINT NOT NULL REFERENCES transactions(id),
+    created_at TIMESTAMP NOT NULL DEFAULT now(),
+    PRIMARY KEY (account_id, currency, charged_on)
+);
@@ -0,0 +1,18 @@
+-- Limits on money leaving an account. scope is 'global' (scope_id ''),
+-- 'tier' (scope_id is the tier name) or 'account' (scope_id is the account
+-- id). A NULL limit is inherited from the broader scope.
+CREATE TABLE transfer_limits (
+    scope TEXT NOT NULL CHECK (scope IN ('global', 'tier', 'account')),
+    scope_id TEXT NOT NULL DEFAULT '',
+    per_transaction BIGINT CHECK (per_transaction > 0),
+    daily BIGINT CHECK (daily > 0),
+    rolling_30_days BIGINT CHECK (rolling_30_days > 0),
+    hourly_count BIGINT CHECK (hourly_count > 0),
+    updated_at TIMESTAMP NOT NULL DEFAULT now(),
+    PRIMARY KEY (scope, scope_id)
+);
+
+-- Outflows of an account over the limit windows
+CREATE INDEX IF NOT EXISTS idx_transactions_account_outflows
+    ON transactions(account_id, currency, created_at)
+    WHERE type IN ('WITHDRAW', 'TRANSFER_OUT');
@@ -0,0 +1,27 @@
+-- Generated account statements. Rows are written once and never changed.
+CREATE TABLE statements (
+    id BIGSERIAL PRIMARY KEY,
+    account_id BIGINT NOT NULL REFERENCES accounts(id),
+    currency TEXT NOT NULL,
+    period_start TIMESTAMP NOT NULL,
+    period_end TIMESTAMP NOT NULL,
+    opening_balance BIGINT NOT NULL,
+    closing_balance BIGINT NOT NULL,
+    totals JSONB NOT NULL,
+    entries JSONB NOT NULL,
+    generated_at TIMESTAMP NOT NULL DEFAULT now(),
+    CHECK (period_end > period_start),
+    UNIQUE (account_id, currency, period_start, period_end)
+);
+
+CREATE INDEX idx_statements_account ON statements(account_id, period_start DESC);
+
+CREATE FUNCTION reject_statement_change() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'statement % is immutable', OLD.id;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER statements_immutable
+    BEFORE UPDATE OR DELETE ON statements
+    FOR EACH ROW EXECUTE FUNCTION reject_statement_change();
@@ -0,0 +1,10 @@
+-- Daily snapshots of each account's balance per currency. A point-in-time
+-- balance starts from the latest checkpoint before it and sums the rest.
+CREATE TABLE balance_checkpoints (
+    account_id BIGINT NOT NULL REFERENCES accounts(id) ON DELETE CASCADE,
+    currency TEXT NOT NULL,
+    as_of TIMESTAMP NOT NULL,
+    balance BIGINT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT now(),
+    PRIMARY KEY (account_id, currency, as_of)
+);
@@ -0,0 +1,8 @@
+ALTER TABLE transactions
+    ADD COLUMN counterparty_account_id BIGINT REFERENCES accounts(id),
+    ADD COLUMN metadata JSONB NOT NULL DEFAULT '{}',
+    ADD COLUMN tags TEXT[] NOT NULL DEFAULT '{}';
+
+-- containment lookups for ?tag= and ?metadata.<key>= history filters
+CREATE INDEX idx_transactions_metadata ON transactions USING GIN (metadata jsonb_path_ops);
+CREATE INDEX idx_transactions_tags ON transactions USING GIN (tags);
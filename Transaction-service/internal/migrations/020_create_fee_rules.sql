@@ -0,0 +1,24 @@
+CREATE TABLE fee_rules (
+    id BIGSERIAL PRIMARY KEY,
+    name TEXT NOT NULL,
+    operation TEXT NOT NULL CHECK (operation IN ('WITHDRAW', 'TRANSFER')),
+    currency TEXT CHECK (currency ~ '^[A-Z]{3}$'),
+    kind TEXT NOT NULL CHECK (kind IN ('flat', 'percentage', 'tiered')),
+    amount BIGINT NOT NULL DEFAULT 0,
+    basis_points BIGINT NOT NULL DEFAULT 0,
+    tiers JSONB NOT NULL DEFAULT '[]',
+    min_amount BIGINT,
+    max_amount BIGINT,
+    waived_tiers TEXT[] NOT NULL DEFAULT '{}',
+    disabled BOOLEAN NOT NULL DEFAULT false,
+    created_at TIMESTAMP NOT NULL DEFAULT now(),
+    updated_at TIMESTAMP NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_fee_rules_operation ON fee_rules (operation) WHERE NOT disabled;
+
+-- a FEE entry points at the withdrawal or transfer it was charged with
+ALTER TABLE transactions
+    ADD COLUMN fee_for BIGINT REFERENCES transactions(id);
+
+CREATE INDEX idx_transactions_fee_for ON transactions (fee_for) WHERE fee_for IS NOT NULL;
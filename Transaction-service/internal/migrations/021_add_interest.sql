@@ -0,0 +1,64 @@
+ALTER TABLE journal_entries DROP CONSTRAINT journal_entries_type_check;
+ALTER TABLE journal_entries ADD CONSTRAINT journal_entries_type_check CHECK (
+    type IN (
+        'DEPOSIT',
+        'WITHDRAW',
+        'TRANSFER',
+        'REVERSAL',
+        'FEE',
+        'INTEREST'
+    )
+);
+
+ALTER TABLE postings DROP CONSTRAINT postings_system_account_check;
+ALTER TABLE postings ADD CONSTRAINT postings_system_account_check CHECK (
+    system_account IN (
+        'CASH',
+        'CLEARING',
+        'FEE_INCOME',
+        'INTEREST_EXPENSE'
+    )
+);
+
+ALTER TABLE transactions DROP CONSTRAINT transactions_type_check;
+ALTER TABLE transactions ADD CONSTRAINT transactions_type_check CHECK (
+    type IN (
+        'DEPOSIT',
+        'WITHDRAW',
+        'TRANSFER_IN',
+        'TRANSFER_OUT',
+        'REVERSAL_IN',
+        'REVERSAL_OUT',
+        'FEE',
+        'INTEREST'
+    )
+);
+
+-- Annual rates per account tier and currency; tiers is a JSON list of
+-- {"up_to", "rate_bps"} bands, the last one without up_to.
+CREATE TABLE interest_rates (
+    tier TEXT NOT NULL,
+    currency TEXT NOT NULL CHECK (currency ~ '^[A-Z]{3}$'),
+    method TEXT NOT NULL CHECK (method IN ('simple', 'compound')),
+    tiers JSONB NOT NULL,
+    updated_at TIMESTAMP NOT NULL DEFAULT now(),
+    PRIMARY KEY (tier, currency)
+);
+
+-- One accrual per balance and day, in millionths of a cent, until the
+-- monthly posting pays it out.
+CREATE TABLE interest_accruals (
+    account_id BIGINT NOT NULL REFERENCES accounts(id) ON DELETE CASCADE,
+    currency TEXT NOT NULL,
+    day DATE NOT NULL,
+    balance BIGINT NOT NULL,
+    basis BIGINT NOT NULL,
+    rate_bps BIGINT NOT NULL,
+    method TEXT NOT NULL,
+    amount_micros BIGINT NOT NULL,
+    posted_at TIMESTAMP,
+    transaction_id BIGINT REFERENCES transactions(id),
+    PRIMARY KEY (account_id, currency, day)
+);
+
+CREATE INDEX idx_interest_accruals_unposted ON interest_accruals (day, account_id, currency) WHERE posted_at IS NULL;
@@ -0,0 +1,9 @@
+-- lets reconciliation find the event of a journal, and the events written
+-- before payloads carried a journal_id
+CREATE INDEX idx_outbox_journal_id ON outbox_events (((payload->>'journal_id')::bigint));
+CREATE INDEX idx_outbox_aggregate ON outbox_events (aggregate_id, created_at);
+
+-- reconciliation pages entries by (created_at, id); this supersedes the
+-- created_at index from 002
+DROP INDEX idx_transactions_created_at;
+CREATE INDEX idx_transactions_created_at ON transactions (created_at, id);
@@ -0,0 +1,31 @@
+-- Each entry is hashed onto its account's chain; see chain.go. Entries
+-- written before this migration are chained by the account's next entry
+-- or by the chain worker.
+ALTER TABLE transactions
+    ADD COLUMN chain_seq BIGINT,
+    ADD COLUMN prev_hash BYTEA,
+    ADD COLUMN hash BYTEA;
+
+CREATE UNIQUE INDEX idx_transactions_chain ON transactions (account_id, chain_seq);
+CREATE INDEX idx_transactions_unchained ON transactions (account_id) WHERE chain_seq IS NULL;
+
+CREATE TABLE ledger_chain_heads (
+    account_id BIGINT PRIMARY KEY REFERENCES accounts(id) ON DELETE CASCADE,
+    last_transaction_id BIGINT REFERENCES transactions(id),
+    entries BIGINT NOT NULL DEFAULT 0,
+    hash BYTEA,
+    updated_at TIMESTAMP NOT NULL DEFAULT now()
+);
+
+-- Signed statements of a chain's length and head hash.
+CREATE TABLE ledger_chain_checkpoints (
+    id BIGSERIAL PRIMARY KEY,
+    account_id BIGINT NOT NULL REFERENCES accounts(id) ON DELETE CASCADE,
+    transaction_id BIGINT NOT NULL REFERENCES transactions(id),
+    entries BIGINT NOT NULL,
+    hash BYTEA NOT NULL,
+    key_id TEXT NOT NULL,
+    signature BYTEA NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    UNIQUE (account_id, entries)
+);
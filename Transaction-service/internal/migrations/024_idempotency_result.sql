@@ -0,0 +1,6 @@
+-- Keys applied outside of HTTP remember a fingerprint of the operation's
+-- arguments and its result, so a retry gets the result back and a key
+-- reused for different arguments is rejected.
+ALTER TABLE idempotency_keys
+    ADD COLUMN payload_hash TEXT,
+    ADD COLUMN result BYTEA;
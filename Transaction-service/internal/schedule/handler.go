@@ -0,0 +1,187 @@
+package schedule
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+	"transaction/internal/transaction"
+
+	"github.com/go-chi/chi"
+)
+
+type Handler struct {
+	service *Service
+}
+
+func NewHandler(service *Service) *Handler {
+	return &Handler{service: service}
+}
+
+func (h *Handler) Create(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		FromAccountID int64      `json:"from_account_id"`
+		ToAccountID   int64      `json:"to_account_id"`
+		Amount        int64      `json:"amount"`
+		Currency      string     `json:"currency"`
+		Note          string     `json:"note"`
+		Frequency     Frequency  `json:"frequency"`
+		StartAt       time.Time  `json:"start_at"`
+		EndAt         *time.Time `json:"end_at"`
+		MaxRuns       *int       `json:"max_runs"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		transaction.RespondError(w, http.StatusBadRequest, "INVALID_REQUEST", "Invalid JSON payload")
+		return
+	}
+
+	if req.Frequency == "" {
+		req.Frequency = FrequencyOnce
+	}
+
+	sch := &Schedule{
+		FromAccountID: req.FromAccountID,
+		ToAccountID:   req.ToAccountID,
+		Amount:        req.Amount,
+		Currency:      req.Currency,
+		Note:          req.Note,
+		Frequency:     req.Frequency,
+		StartAt:       req.StartAt,
+		EndAt:         req.EndAt,
+		MaxRuns:       req.MaxRuns,
+	}
+
+	if err := h.service.Create(r.Context(), sch); err != nil {
+		transaction.RespondServiceError(w, err)
+		return
+	}
+
+	transaction.RespondJSON(w, http.StatusCreated, transaction.SuccessResponse{
+		Status:  "success",
+		Message: "Transfer scheduled successfully",
+		Data:    sch,
+	})
+}
+
+func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
+	accountID, err := strconv.ParseInt(r.URL.Query().Get("account_id"), 10, 64)
+	if err != nil {
+		transaction.RespondError(w, http.StatusBadRequest, "INVALID_ACCOUNT_ID", "account_id query parameter must be a number")
+		return
+	}
+
+	schedules, err := h.service.ListByAccount(r.Context(), accountID)
+	if err != nil {
+		transaction.RespondServiceError(w, err)
+		return
+	}
+
+	transaction.RespondJSON(w, http.StatusOK, transaction.SuccessResponse{
+		Status:  "success",
+		Message: "Schedules retrieved successfully",
+		Data:    schedules,
+	})
+}
+
+func (h *Handler) Get(w http.ResponseWriter, r *http.Request) {
+	id, ok := scheduleID(w, r)
+	if !ok {
+		return
+	}
+
+	sch, err := h.service.Get(r.Context(), id)
+	if err != nil {
+		transaction.RespondServiceError(w, err)
+		return
+	}
+
+	transaction.RespondJSON(w, http.StatusOK, transaction.SuccessResponse{
+		Status:  "success",
+		Message: "Schedule retrieved successfully",
+		Data:    sch,
+	})
+}
+
+func (h *Handler) Update(w http.ResponseWriter, r *http.Request) {
+	id, ok := scheduleID(w, r)
+	if !ok {
+		return
+	}
+
+	var req UpdateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		transaction.RespondError(w, http.StatusBadRequest, "INVALID_REQUEST", "Invalid JSON payload")
+		return
+	}
+
+	sch, err := h.service.Update(r.Context(), id, req)
+	if err != nil {
+		transaction.RespondServiceError(w, err)
+		return
+	}
+
+	transaction.RespondJSON(w, http.StatusOK, transaction.SuccessResponse{
+		Status:  "success",
+		Message: "Schedule updated successfully",
+		Data:    sch,
+	})
+}
+
+func (h *Handler) Cancel(w http.ResponseWriter, r *http.Request) {
+	id, ok := scheduleID(w, r)
+	if !ok {
+		return
+	}
+
+	sch, err := h.service.Cancel(r.Context(), id)
+	if err != nil {
+		transaction.RespondServiceError(w, err)
+		return
+	}
+
+	transaction.RespondJSON(w, http.StatusOK, transaction.SuccessResponse{
+		Status:  "success",
+		Message: "Schedule cancelled successfully",
+		Data:    sch,
+	})
+}
+
+func (h *Handler) Runs(w http.ResponseWriter, r *http.Request) {
+	id, ok := scheduleID(w, r)
+	if !ok {
+		return
+	}
+
+	runs, err := h.service.Runs(r.Context(), id)
+	if err != nil {
+		transaction.RespondServiceError(w, err)
+		return
+	}
+
+	transaction.RespondJSON(w, http.StatusOK, transaction.SuccessResponse{
+		Status:  "success",
+		Message: "Schedule runs retrieved successfully",
+		Data:    runs,
+	})
+}
+
+func (h *Handler) Routes() http.Handler {
+	r := chi.NewRouter()
+	r.Post("/", h.Create)
+	r.Get("/", h.List)
+	r.Get("/{id}", h.Get)
+	r.Put("/{id}", h.Update)
+	r.Delete("/{id}", h.Cancel)
+	r.Get("/{id}/runs", h.Runs)
+	return r
+}
+
+func scheduleID(w http.ResponseWriter, r *http.Request) (int64, bool) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		transaction.RespondError(w, http.StatusBadRequest, "INVALID_SCHEDULE_ID", "Schedule ID must be a number")
+		return 0, false
+	}
+	return id, true
+}
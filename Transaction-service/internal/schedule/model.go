@@ -0,0 +1,141 @@
+package schedule
+
+import (
+	"fmt"
+	"time"
+	"transaction/internal/transaction"
+)
+
+type Frequency string
+
+const (
+	FrequencyOnce    Frequency = "ONCE"
+	FrequencyDaily   Frequency = "DAILY"
+	FrequencyWeekly  Frequency = "WEEKLY"
+	FrequencyMonthly Frequency = "MONTHLY"
+)
+
+func (f Frequency) Valid() bool {
+	switch f {
+	case FrequencyOnce, FrequencyDaily, FrequencyWeekly, FrequencyMonthly:
+		return true
+	}
+	return false
+}
+
+type Status string
+
+const (
+	StatusActive    Status = "ACTIVE"
+	StatusPaused    Status = "PAUSED"
+	StatusCompleted Status = "COMPLETED"
+	StatusCancelled Status = "CANCELLED"
+)
+
+type RunStatus string
+
+const (
+	RunSucceeded RunStatus = "SUCCEEDED"
+	RunRetrying  RunStatus = "RETRYING"
+	RunFailed    RunStatus = "FAILED"
+)
+
+var (
+	ErrScheduleNotFound = transaction.ErrScheduleNotFound
+	ErrScheduleClosed   = transaction.ErrScheduleClosed
+	ErrInvalidSchedule  = transaction.ErrInvalidSchedule
+)
+
+func invalidSchedule(format string, args ...any) error {
+	return fmt.Errorf("%w: %s", ErrInvalidSchedule, fmt.Sprintf(format, args...))
+}
+
+// Schedule is a standing transfer instruction. Occurrences are derived from
+// StartAt and RunCount so monthly schedules keep their day of month.
+type Schedule struct {
+	ID            int64      `json:"id"`
+	FromAccountID int64      `json:"from_account_id"`
+	ToAccountID   int64      `json:"to_account_id"`
+	Amount        int64      `json:"amount"` // cents
+	Currency      string     `json:"currency"`
+	Note          string     `json:"note"`
+	Frequency     Frequency  `json:"frequency"`
+	StartAt       time.Time  `json:"start_at"`
+	EndAt         *time.Time `json:"end_at,omitempty"`   // no occurrence after this time
+	MaxRuns       *int       `json:"max_runs,omitempty"` // stop after this many occurrences
+	RunCount      int        `json:"run_count"`          // occurrences settled, successfully or not
+	Attempt       int        `json:"attempt"`            // failed attempts of the current occurrence
+	NextRunAt     *time.Time `json:"next_run_at,omitempty"`
+	Status        Status     `json:"status"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+// Run records one execution attempt of a schedule.
+type Run struct {
+	ID           int64     `json:"id"`
+	ScheduleID   int64     `json:"schedule_id"`
+	ScheduledFor time.Time `json:"scheduled_for"`
+	Attempt      int       `json:"attempt"`
+	Status       RunStatus `json:"status"`
+	Error        string    `json:"error,omitempty"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// RetryPolicy controls how an occurrence that failed for lack of funds, or
+// because the account service was down, is retried before it is reported
+// as failed.
+type RetryPolicy struct {
+	MaxAttempts int
+	Backoff     time.Duration // doubled after every failed attempt
+}
+
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 3,
+	Backoff:     time.Hour,
+}
+
+// delay returns how long to wait after the given failed attempt.
+func (p RetryPolicy) delay(attempt int) time.Duration {
+	return p.Backoff << (attempt - 1)
+}
+
+// Occurrence returns the n-th (zero based) execution time of a schedule
+// starting at start. Monthly schedules clamp to the last day of shorter
+// months instead of spilling into the next one.
+func Occurrence(start time.Time, freq Frequency, n int) time.Time {
+	switch freq {
+	case FrequencyDaily:
+		return start.AddDate(0, 0, n)
+	case FrequencyWeekly:
+		return start.AddDate(0, 0, 7*n)
+	case FrequencyMonthly:
+		y, m, d := start.Date()
+		first := time.Date(y, m+time.Month(n), 1, start.Hour(), start.Minute(), start.Second(), start.Nanosecond(), start.Location())
+		last := first.AddDate(0, 1, -1).Day()
+		if d > last {
+			d = last
+		}
+		return first.AddDate(0, 0, d-1)
+	}
+	return start
+}
+
+// nextOccurrence returns when the occurrence after RunCount is due, or nil
+// when the schedule has nothing left to run.
+func (s *Schedule) nextOccurrence() *time.Time {
+	if s.Frequency == FrequencyOnce && s.RunCount > 0 {
+		return nil
+	}
+
+	if s.MaxRuns != nil && s.RunCount >= *s.MaxRuns {
+		return nil
+	}
+
+	next := Occurrence(s.StartAt, s.Frequency, s.RunCount)
+	if s.EndAt != nil && next.After(*s.EndAt) {
+		return nil
+	}
+
+	return &next
+}
@@ -0,0 +1,278 @@
+package schedule
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+	"transaction/internal/infrastructure/database"
+)
+
+var _ Repository = (*PostgresRepo)(nil)
+
+// claimLease keeps a claimed schedule away from other workers while its
+// transfer runs. A worker that dies mid-run frees the schedule afterwards.
+const claimLease = 5 * time.Minute
+
+type PostgresRepo struct {
+	db database.DBTX
+}
+
+func NewPostgresRepo(db database.DBTX) *PostgresRepo {
+	return &PostgresRepo{db: db}
+}
+
+const scheduleColumns = `id, from_account_id, to_account_id, amount, currency, note, frequency,
+	start_at, end_at, max_runs, run_count, attempt, next_run_at, status, created_at, updated_at`
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanSchedule(row rowScanner) (*Schedule, error) {
+	s := &Schedule{}
+	var endAt, nextRunAt sql.NullTime
+	var maxRuns sql.NullInt64
+
+	err := row.Scan(
+		&s.ID,
+		&s.FromAccountID,
+		&s.ToAccountID,
+		&s.Amount,
+		&s.Currency,
+		&s.Note,
+		&s.Frequency,
+		&s.StartAt,
+		&endAt,
+		&maxRuns,
+		&s.RunCount,
+		&s.Attempt,
+		&nextRunAt,
+		&s.Status,
+		&s.CreatedAt,
+		&s.UpdatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, ErrScheduleNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if endAt.Valid {
+		s.EndAt = &endAt.Time
+	}
+	if nextRunAt.Valid {
+		s.NextRunAt = &nextRunAt.Time
+	}
+	if maxRuns.Valid {
+		n := int(maxRuns.Int64)
+		s.MaxRuns = &n
+	}
+
+	return s, nil
+}
+
+func (r *PostgresRepo) Create(ctx context.Context, s *Schedule) error {
+	created, err := scanSchedule(r.db.QueryRowContext(ctx, `
+		INSERT INTO transfer_schedules
+		    (from_account_id, to_account_id, amount, currency, note, frequency,
+		     start_at, end_at, max_runs, next_run_at, status)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		RETURNING `+scheduleColumns,
+		s.FromAccountID, s.ToAccountID, s.Amount, s.Currency, s.Note, s.Frequency,
+		s.StartAt, s.EndAt, s.MaxRuns, s.NextRunAt, s.Status,
+	))
+	if err != nil {
+		return err
+	}
+	*s = *created
+	return nil
+}
+
+func (r *PostgresRepo) GetByID(ctx context.Context, id int64) (*Schedule, error) {
+	return scanSchedule(r.db.QueryRowContext(ctx, `
+		SELECT `+scheduleColumns+`
+		FROM transfer_schedules
+		WHERE id = $1
+	`, id))
+}
+
+func (r *PostgresRepo) ListByAccount(ctx context.Context, accountID int64) ([]Schedule, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+scheduleColumns+`
+		FROM transfer_schedules
+		WHERE from_account_id = $1 OR to_account_id = $1
+		ORDER BY id
+	`, accountID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []Schedule
+	for rows.Next() {
+		s, err := scanSchedule(rows)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, *s)
+	}
+	return result, rows.Err()
+}
+
+// LockByID reads a schedule and locks it until the surrounding DB
+// transaction ends.
+func (r *PostgresRepo) LockByID(ctx context.Context, id int64) (*Schedule, error) {
+	return scanSchedule(r.db.QueryRowContext(ctx, `
+		SELECT `+scheduleColumns+`
+		FROM transfer_schedules
+		WHERE id = $1
+		FOR UPDATE
+	`, id))
+}
+
+// closedOrMissing tells a schedule that is gone from one that was closed.
+func (r *PostgresRepo) closedOrMissing(ctx context.Context, id int64) error {
+	if _, err := r.GetByID(ctx, id); err != nil {
+		return err
+	}
+	return ErrScheduleClosed
+}
+
+// Update saves an edit of an open schedule. The claim lease is left alone,
+// so an edit never frees a schedule a worker is running.
+func (r *PostgresRepo) Update(ctx context.Context, s *Schedule) error {
+	err := r.db.QueryRowContext(ctx, `
+		UPDATE transfer_schedules
+		SET amount = $2,
+		    note = $3,
+		    end_at = $4,
+		    max_runs = $5,
+		    run_count = $6,
+		    attempt = $7,
+		    next_run_at = $8,
+		    status = $9,
+		    updated_at = now()
+		WHERE id = $1 AND status IN ($10, $11)
+		RETURNING updated_at
+	`, s.ID, s.Amount, s.Note, s.EndAt, s.MaxRuns, s.RunCount, s.Attempt, s.NextRunAt, s.Status,
+		StatusActive, StatusPaused,
+	).Scan(&s.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return r.closedOrMissing(ctx, s.ID)
+	}
+	return err
+}
+
+// Cancel closes an open schedule.
+func (r *PostgresRepo) Cancel(ctx context.Context, id int64) (*Schedule, error) {
+	s, err := scanSchedule(r.db.QueryRowContext(ctx, `
+		UPDATE transfer_schedules
+		SET status = $2,
+		    next_run_at = NULL,
+		    updated_at = now()
+		WHERE id = $1 AND status IN ($3, $4)
+		RETURNING `+scheduleColumns,
+		id, StatusCancelled, StatusActive, StatusPaused,
+	))
+	if errors.Is(err, ErrScheduleNotFound) {
+		return nil, r.closedOrMissing(ctx, id)
+	}
+	return s, err
+}
+
+// Advance saves the outcome of a run and releases the claim lease. It
+// writes nothing if the occurrence was settled since runCount and attempt
+// were read.
+func (r *PostgresRepo) Advance(ctx context.Context, s *Schedule, runCount, attempt int) error {
+	err := r.db.QueryRowContext(ctx, `
+		UPDATE transfer_schedules
+		SET run_count = $2,
+		    attempt = $3,
+		    next_run_at = $4,
+		    status = $5,
+		    locked_until = NULL,
+		    updated_at = now()
+		WHERE id = $1 AND run_count = $6 AND attempt = $7
+		RETURNING updated_at
+	`, s.ID, s.RunCount, s.Attempt, s.NextRunAt, s.Status, runCount, attempt).Scan(&s.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return nil
+	}
+	return err
+}
+
+// ClaimDue leases up to limit active schedules whose next run is due.
+// Concurrent workers skip rows another worker is claiming.
+func (r *PostgresRepo) ClaimDue(ctx context.Context, now time.Time, limit int) ([]Schedule, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		UPDATE transfer_schedules
+		SET locked_until = $1::timestamp + make_interval(secs => $2)
+		WHERE id IN (
+			SELECT id
+			FROM transfer_schedules
+			WHERE status = $3
+			  AND next_run_at <= $1
+			  AND (locked_until IS NULL OR locked_until < $1)
+			ORDER BY next_run_at
+			LIMIT $4
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING `+scheduleColumns,
+		now, claimLease.Seconds(), StatusActive, limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []Schedule
+	for rows.Next() {
+		s, err := scanSchedule(rows)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, *s)
+	}
+	return result, rows.Err()
+}
+
+func (r *PostgresRepo) AddRun(ctx context.Context, run *Run) error {
+	return r.db.QueryRowContext(ctx, `
+		INSERT INTO transfer_schedule_runs (schedule_id, scheduled_for, attempt, status, error)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at
+	`, run.ScheduleID, run.ScheduledFor, run.Attempt, run.Status, run.Error).Scan(&run.ID, &run.CreatedAt)
+}
+
+func (r *PostgresRepo) ListRuns(ctx context.Context, scheduleID int64) ([]Run, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, schedule_id, scheduled_for, attempt, status, error, created_at
+		FROM transfer_schedule_runs
+		WHERE schedule_id = $1
+		ORDER BY id DESC
+	`, scheduleID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []Run
+	for rows.Next() {
+		var run Run
+		if err := rows.Scan(
+			&run.ID,
+			&run.ScheduleID,
+			&run.ScheduledFor,
+			&run.Attempt,
+			&run.Status,
+			&run.Error,
+			&run.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		result = append(result, run)
+	}
+	return result, rows.Err()
+}
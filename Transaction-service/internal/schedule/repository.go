@@ -0,0 +1,19 @@
+package schedule
+
+import (
+	"context"
+	"time"
+)
+
+type Repository interface {
+	Create(ctx context.Context, s *Schedule) error
+	GetByID(ctx context.Context, id int64) (*Schedule, error)
+	ListByAccount(ctx context.Context, accountID int64) ([]Schedule, error)
+	LockByID(ctx context.Context, id int64) (*Schedule, error)
+	Update(ctx context.Context, s *Schedule) error
+	Cancel(ctx context.Context, id int64) (*Schedule, error)
+	ClaimDue(ctx context.Context, now time.Time, limit int) ([]Schedule, error)
+	Advance(ctx context.Context, s *Schedule, runCount, attempt int) error
+	AddRun(ctx context.Context, run *Run) error
+	ListRuns(ctx context.Context, scheduleID int64) ([]Run, error)
+}
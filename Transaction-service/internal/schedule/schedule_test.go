@@ -0,0 +1,109 @@
+package schedule_test
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+	"transaction/internal/schedule"
+	"transaction/internal/transaction"
+	"transaction/pb"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestOccurrence(t *testing.T) {
+	start := time.Date(2026, time.January, 31, 9, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		freq schedule.Frequency
+		n    int
+		want time.Time
+	}{
+		{"once", schedule.FrequencyOnce, 3, start},
+		{"daily", schedule.FrequencyDaily, 1, time.Date(2026, time.February, 1, 9, 0, 0, 0, time.UTC)},
+		{"weekly", schedule.FrequencyWeekly, 2, time.Date(2026, time.February, 14, 9, 0, 0, 0, time.UTC)},
+		{"monthly clamps to february", schedule.FrequencyMonthly, 1, time.Date(2026, time.February, 28, 9, 0, 0, 0, time.UTC)},
+		{"monthly keeps day after short month", schedule.FrequencyMonthly, 2, time.Date(2026, time.March, 31, 9, 0, 0, 0, time.UTC)},
+		{"monthly clamps to thirty days", schedule.FrequencyMonthly, 3, time.Date(2026, time.April, 30, 9, 0, 0, 0, time.UTC)},
+		{"monthly crosses year", schedule.FrequencyMonthly, 12, time.Date(2027, time.January, 31, 9, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := schedule.Occurrence(start, tt.freq, tt.n)
+			if !got.Equal(tt.want) {
+				t.Fatalf("expected %s, got %s", tt.want, got)
+			}
+		})
+	}
+}
+
+// missingAccounts answers every lookup as the account service does for an
+// unknown account.
+type missingAccounts struct{}
+
+func (missingAccounts) GetAccount(ctx context.Context, in *pb.GetAccountRequest, opts ...grpc.CallOption) (*pb.GetAccountResponse, error) {
+	return nil, status.Error(codes.NotFound, "account not found")
+}
+
+func serve(t *testing.T, service *schedule.Service, method, path, body string) (int, transaction.ErrorResponse) {
+	t.Helper()
+
+	rec := httptest.NewRecorder()
+	schedule.NewHandler(service).Routes().ServeHTTP(rec, httptest.NewRequest(method, path, strings.NewReader(body)))
+
+	var resp transaction.ErrorResponse
+	json.NewDecoder(rec.Body).Decode(&resp)
+	return rec.Code, resp
+}
+
+func TestHandler_MapsCreateErrors(t *testing.T) {
+	// every request is rejected before anything is stored
+	service := schedule.NewService(nil, nil, missingAccounts{})
+
+	tests := []struct {
+		name       string
+		body       string
+		wantStatus int
+		wantCode   string
+	}{
+		{"bad amount", `{"from_account_id":1,"to_account_id":2,"amount":0}`, http.StatusBadRequest, "INVALID_AMOUNT"},
+		{"same account", `{"from_account_id":1,"to_account_id":1,"amount":100}`, http.StatusBadRequest, "SAME_ACCOUNT_TRANSFER"},
+		{"bad frequency", `{"from_account_id":1,"to_account_id":2,"amount":100,"frequency":"HOURLY"}`, http.StatusBadRequest, "INVALID_SCHEDULE"},
+		{"missing account", `{"from_account_id":1,"to_account_id":2,"amount":100}`, http.StatusNotFound, "ACCOUNT_NOT_FOUND"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, resp := serve(t, service, http.MethodPost, "/", tt.body)
+			if code != tt.wantStatus || resp.Error.Code != tt.wantCode {
+				t.Fatalf("expected %d %s, got %d %+v", tt.wantStatus, tt.wantCode, code, resp.Error)
+			}
+		})
+	}
+}
+
+func TestHandler_HidesInternalErrors(t *testing.T) {
+	// nothing listens on port 1, so every query fails
+	db, err := sql.Open("postgres", "host=127.0.0.1 port=1 sslmode=disable connect_timeout=1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	code, resp := serve(t, schedule.NewService(db, nil, nil), http.MethodGet, "/?account_id=7", "")
+	if code != http.StatusInternalServerError || resp.Error.Code != "INTERNAL_ERROR" {
+		t.Fatalf("expected 500 INTERNAL_ERROR, got %d %+v", code, resp.Error)
+	}
+	if strings.Contains(resp.Error.Message, "127.0.0.1") {
+		t.Fatalf("internal error details leaked: %q", resp.Error.Message)
+	}
+}
@@ -0,0 +1,340 @@
+package schedule
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+	"transaction/internal/infrastructure/database"
+	"transaction/internal/transaction"
+	"transaction/pb"
+
+	"github.com/google/uuid"
+)
+
+// UpdateRequest carries the fields of a schedule that may change after it
+// was created. Nil fields are left untouched.
+type UpdateRequest struct {
+	Amount  *int64     `json:"amount"`
+	Note    *string    `json:"note"`
+	EndAt   *time.Time `json:"end_at"`
+	MaxRuns *int       `json:"max_runs"`
+	Status  *Status    `json:"status"` // ACTIVE or PAUSED
+}
+
+type Service struct {
+	repo      Repository
+	transfers transaction.TransactionService
+	accounts  pb.AccountServiceClient
+	runner    *database.TxRunner
+	retry     RetryPolicy
+	now       func() time.Time
+}
+
+func NewService(db *sql.DB, transfers transaction.TransactionService, accounts pb.AccountServiceClient) *Service {
+	return &Service{
+		repo:      NewPostgresRepo(db),
+		transfers: transfers,
+		accounts:  accounts,
+		runner:    database.NewTxRunner(db, database.DefaultRetryPolicy),
+		retry:     DefaultRetryPolicy,
+		now:       func() time.Time { return time.Now().UTC() },
+	}
+}
+
+func (s *Service) Create(ctx context.Context, sch *Schedule) error {
+	if sch.Amount <= 0 {
+		return transaction.ErrInvalidAmount
+	}
+
+	if sch.FromAccountID == sch.ToAccountID {
+		return transaction.ErrSameAccount
+	}
+
+	if !sch.Frequency.Valid() {
+		return invalidSchedule("unknown frequency %q", sch.Frequency)
+	}
+
+	if sch.MaxRuns != nil && *sch.MaxRuns <= 0 {
+		return invalidSchedule("max_runs must be positive")
+	}
+
+	if sch.StartAt.IsZero() {
+		sch.StartAt = s.now()
+	}
+	sch.StartAt = sch.StartAt.UTC()
+
+	if sch.EndAt != nil {
+		end := sch.EndAt.UTC()
+		if end.Before(sch.StartAt) {
+			return invalidSchedule("end_at must not be before start_at")
+		}
+		sch.EndAt = &end
+	}
+
+	// a schedule that could never run is rejected now, not at its first
+	// occurrence
+	currency, err := transaction.CheckTransfer(ctx, s.accounts, sch.FromAccountID, sch.ToAccountID, sch.Currency)
+	if err != nil {
+		return err
+	}
+
+	sch.Currency = currency
+	sch.RunCount = 0
+	sch.Attempt = 0
+	sch.Status = StatusActive
+	sch.NextRunAt = sch.nextOccurrence()
+
+	return s.repo.Create(ctx, sch)
+}
+
+func (s *Service) Get(ctx context.Context, id int64) (*Schedule, error) {
+	return s.repo.GetByID(ctx, id)
+}
+
+func (s *Service) ListByAccount(ctx context.Context, accountID int64) ([]Schedule, error) {
+	return s.repo.ListByAccount(ctx, accountID)
+}
+
+func (s *Service) Runs(ctx context.Context, id int64) ([]Run, error) {
+	if _, err := s.repo.GetByID(ctx, id); err != nil {
+		return nil, err
+	}
+	return s.repo.ListRuns(ctx, id)
+}
+
+func (s *Service) Update(ctx context.Context, id int64, req UpdateRequest) (*Schedule, error) {
+	var sch *Schedule
+	err := s.runner.Run(ctx, "update_schedule", nil, func(tx *sql.Tx) error {
+		var err error
+		sch, err = s.update(ctx, NewPostgresRepo(tx), id, req)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return sch, nil
+}
+
+// update applies req to the schedule under its row lock, so a run that
+// finishes meanwhile settles against the edited schedule.
+func (s *Service) update(ctx context.Context, repo Repository, id int64, req UpdateRequest) (*Schedule, error) {
+	sch, err := repo.LockByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if sch.Status == StatusCompleted || sch.Status == StatusCancelled {
+		return nil, ErrScheduleClosed
+	}
+
+	if req.Amount != nil {
+		if *req.Amount <= 0 {
+			return nil, transaction.ErrInvalidAmount
+		}
+		sch.Amount = *req.Amount
+	}
+
+	if req.Note != nil {
+		sch.Note = *req.Note
+	}
+
+	if req.EndAt != nil {
+		end := req.EndAt.UTC()
+		if end.Before(sch.StartAt) {
+			return nil, invalidSchedule("end_at must not be before start_at")
+		}
+		sch.EndAt = &end
+	}
+
+	if req.MaxRuns != nil {
+		if *req.MaxRuns <= 0 {
+			return nil, invalidSchedule("max_runs must be positive")
+		}
+		sch.MaxRuns = req.MaxRuns
+	}
+
+	resumed := false
+	if req.Status != nil {
+		if *req.Status != StatusActive && *req.Status != StatusPaused {
+			return nil, invalidSchedule("status can only be set to ACTIVE or PAUSED")
+		}
+		resumed = sch.Status == StatusPaused && *req.Status == StatusActive
+		sch.Status = *req.Status
+	}
+
+	// a shorter end date or run limit may have finished the schedule
+	if sch.Attempt == 0 {
+		sch.NextRunAt = sch.nextOccurrence()
+	}
+
+	// occurrences that fell due while paused are skipped, not caught up
+	if resumed {
+		now := s.now()
+		for sch.NextRunAt != nil && sch.NextRunAt.Before(now) {
+			sch.RunCount++
+			sch.Attempt = 0
+			sch.NextRunAt = sch.nextOccurrence()
+		}
+	}
+	if sch.NextRunAt == nil {
+		sch.Status = StatusCompleted
+	}
+
+	if err := repo.Update(ctx, sch); err != nil {
+		return nil, err
+	}
+	return sch, nil
+}
+
+// Cancel closes the schedule. A run already in flight still settles, but
+// leaves the schedule cancelled.
+func (s *Service) Cancel(ctx context.Context, id int64) (*Schedule, error) {
+	return s.repo.Cancel(ctx, id)
+}
+
+// RunDue executes up to limit due schedules and returns how many it claimed.
+func (s *Service) RunDue(ctx context.Context, limit int) (int, error) {
+	due, err := s.repo.ClaimDue(ctx, s.now(), limit)
+	if err != nil {
+		return 0, err
+	}
+
+	for i := range due {
+		if err := s.execute(ctx, &due[i]); err != nil {
+			log.Printf("❌ Schedule %d run failed to record: %v", due[i].ID, err)
+		}
+	}
+
+	return len(due), nil
+}
+
+// execute runs the current occurrence of a claimed schedule, records the
+// outcome and moves the schedule on.
+func (s *Service) execute(ctx context.Context, sch *Schedule) error {
+	occurrence := Occurrence(sch.StartAt, sch.Frequency, sch.RunCount)
+
+	// one key per occurrence: a run retried after a crash cannot pay twice,
+	// while a failed attempt rolls its key back and may be tried again
+	_, err := s.transfers.Transfer(
+		ctx,
+		fmt.Sprintf("schedule-%d-%d", sch.ID, occurrence.Unix()),
+		sch.FromAccountID,
+		sch.ToAccountID,
+		sch.Amount,
+		sch.Currency,
+		sch.Note,
+		transaction.Labels{Metadata: map[string]string{"schedule_id": strconv.FormatInt(sch.ID, 10)}},
+	)
+
+	// the key is this occurrence's alone, so it was paid before the
+	// schedule was edited
+	if errors.Is(err, transaction.ErrIdempotencyKeyReused) {
+		err = nil
+	}
+
+	run := &Run{
+		ScheduleID:   sch.ID,
+		ScheduledFor: occurrence,
+		Attempt:      sch.Attempt + 1,
+		Status:       RunSucceeded,
+	}
+
+	if err != nil {
+		run.Error = err.Error()
+
+		// out of retries, or a failure retrying will not fix
+		run.Status = RunFailed
+		if retryable(err) && run.Attempt < s.retry.MaxAttempts {
+			run.Status = RunRetrying
+		}
+	}
+
+	return s.runner.Run(ctx, "settle_schedule_run", nil, func(tx *sql.Tx) error {
+		return s.settle(ctx, tx, sch, run)
+	})
+}
+
+// settle records a run and moves the schedule on in one DB transaction. The
+// schedule is re-read under its row lock: it may have been edited, paused
+// or cancelled while the transfer ran.
+func (s *Service) settle(ctx context.Context, tx *sql.Tx, claimed *Schedule, run *Run) error {
+	repo := NewPostgresRepo(tx)
+
+	if err := repo.AddRun(ctx, run); err != nil {
+		return err
+	}
+
+	if run.Status == RunFailed {
+		if err := reportFailure(ctx, transaction.NewPostgresOutboxRepository(tx), claimed, run); err != nil {
+			return err
+		}
+	}
+
+	sch, err := repo.LockByID(ctx, claimed.ID)
+	if err != nil {
+		return err
+	}
+
+	// the occurrence was settled meanwhile, e.g. skipped on resume
+	if sch.RunCount != claimed.RunCount || sch.Attempt != claimed.Attempt {
+		return nil
+	}
+
+	if run.Status == RunRetrying {
+		sch.Attempt++
+		retryAt := s.now().Add(s.retry.delay(sch.Attempt))
+		sch.NextRunAt = &retryAt
+	} else {
+		sch.RunCount++
+		sch.Attempt = 0
+		sch.NextRunAt = sch.nextOccurrence()
+	}
+
+	switch {
+	case sch.Status == StatusCancelled || sch.Status == StatusCompleted:
+		sch.NextRunAt = nil
+	case sch.NextRunAt == nil:
+		sch.Status = StatusCompleted
+	}
+
+	return repo.Advance(ctx, sch, claimed.RunCount, claimed.Attempt)
+}
+
+// reportFailure publishes a failed occurrence through the outbox so the
+// account holder can be notified.
+func reportFailure(ctx context.Context, outbox transaction.OutboxRepository, sch *Schedule, run *Run) error {
+	payload, err := json.Marshal(map[string]interface{}{
+		"account_id":    sch.FromAccountID,
+		"schedule_id":   sch.ID,
+		"to_account_id": sch.ToAccountID,
+		"amount":        sch.Amount,
+		"currency":      sch.Currency,
+		"type":          "scheduled_transfer_failed",
+		"scheduled_for": run.ScheduledFor,
+		"attempts":      run.Attempt,
+		"error":         run.Error,
+	})
+	if err != nil {
+		return err
+	}
+
+	return outbox.Add(ctx, &transaction.OutboxEvent{
+		ID:            uuid.New(),
+		AggregateType: "account",
+		AggregateID:   sch.FromAccountID,
+		EventType:     "schedule.run_failed",
+		Payload:       payload,
+	})
+}
+
+// retryable reports whether a later attempt of the same occurrence may
+// succeed: funds can arrive and the account service can come back.
+func retryable(err error) bool {
+	return errors.Is(err, transaction.ErrInsufficientFunds) ||
+		errors.Is(err, transaction.ErrUpstreamUnavailable)
+}
@@ -0,0 +1,365 @@
+package schedule_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/url"
+	"os"
+	"testing"
+	"time"
+	"transaction/internal/schedule"
+	"transaction/internal/transaction"
+	"transaction/pb"
+
+	"github.com/joho/godotenv"
+	"google.golang.org/grpc"
+)
+
+func setupTestDB(t *testing.T) *sql.DB {
+	_ = godotenv.Load("../../.env")
+	rawURL := os.Getenv("DATABASE_URL")
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		t.Fatalf("Invalid DATABASE_URL: %v", err)
+	}
+
+	// Force usage of transaction_test database for tests
+	u.Path = "/transaction_test"
+
+	db, err := sql.Open("postgres", u.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	db.Exec(`
+CREATE TABLE IF NOT EXISTS accounts (
+    id BIGSERIAL PRIMARY KEY,
+    name TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT now(),
+    updated_at TIMESTAMP NOT NULL DEFAULT now()
+);
+
+CREATE TABLE IF NOT EXISTS outbox_events (
+    id UUID PRIMARY KEY,
+    aggregate_type TEXT NOT NULL,
+    aggregate_id BIGINT NOT NULL,
+    event_type TEXT NOT NULL,
+    payload JSONB NOT NULL,
+    status TEXT NOT NULL DEFAULT 'pending',
+    created_at TIMESTAMP NOT NULL DEFAULT now(),
+    processed_at TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS transfer_schedules (
+    id BIGSERIAL PRIMARY KEY,
+    from_account_id BIGINT NOT NULL REFERENCES accounts(id) ON DELETE CASCADE,
+    to_account_id BIGINT NOT NULL REFERENCES accounts(id) ON DELETE CASCADE,
+    amount BIGINT NOT NULL CHECK (amount > 0),
+    currency TEXT NOT NULL DEFAULT '',
+    note TEXT NOT NULL DEFAULT '',
+    frequency TEXT NOT NULL,
+    start_at TIMESTAMP NOT NULL,
+    end_at TIMESTAMP,
+    max_runs INT CHECK (max_runs > 0),
+    run_count INT NOT NULL DEFAULT 0,
+    attempt INT NOT NULL DEFAULT 0,
+    next_run_at TIMESTAMP,
+    locked_until TIMESTAMP,
+    status TEXT NOT NULL DEFAULT 'ACTIVE',
+    created_at TIMESTAMP NOT NULL DEFAULT now(),
+    updated_at TIMESTAMP NOT NULL DEFAULT now(),
+    CHECK (from_account_id <> to_account_id)
+);
+
+CREATE TABLE IF NOT EXISTS transfer_schedule_runs (
+    id BIGSERIAL PRIMARY KEY,
+    schedule_id BIGINT NOT NULL REFERENCES transfer_schedules(id) ON DELETE CASCADE,
+    scheduled_for TIMESTAMP NOT NULL,
+    attempt INT NOT NULL,
+    status TEXT NOT NULL,
+    error TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT now()
+);
+`)
+
+	t.Cleanup(func() {
+		db.Exec("TRUNCATE transfer_schedule_runs, transfer_schedules, outbox_events RESTART IDENTITY CASCADE")
+		db.Close()
+	})
+
+	return db
+}
+
+// fakeTransfers answers Transfer with the next queued error, nil once the
+// queue is empty. during, when set, runs while the transfer is in flight.
+type fakeTransfers struct {
+	transaction.TransactionService
+	errs   []error
+	calls  int
+	during func()
+}
+
+func (f *fakeTransfers) Transfer(ctx context.Context, idempotencyKey string, fromAccountID int64, toAccountID int64, amount int64, currency string, note string, labels transaction.Labels) ([]transaction.Fee, error) {
+	f.calls++
+	if f.during != nil {
+		f.during()
+	}
+	if len(f.errs) == 0 {
+		return nil, nil
+	}
+	err := f.errs[0]
+	f.errs = f.errs[1:]
+	return nil, err
+}
+
+// fakeAccounts reports every account as an active USD account, except
+// the missing ones and those held in another currency.
+type fakeAccounts struct {
+	missing    map[int64]bool
+	currencies map[int64]string
+}
+
+func (f *fakeAccounts) GetAccount(ctx context.Context, in *pb.GetAccountRequest, opts ...grpc.CallOption) (*pb.GetAccountResponse, error) {
+	currency := "USD"
+	if c, ok := f.currencies[in.AccountId]; ok {
+		currency = c
+	}
+
+	return &pb.GetAccountResponse{
+		Id:       in.AccountId,
+		IsExists: !f.missing[in.AccountId],
+		IsActive: true,
+		Currency: currency,
+	}, nil
+}
+
+func createAccount(t *testing.T, db *sql.DB, name string) int64 {
+	var id int64
+	if err := db.QueryRow("INSERT INTO accounts (name) VALUES ($1) RETURNING id", name).Scan(&id); err != nil {
+		t.Fatalf("Failed to create test account: %v", err)
+	}
+	return id
+}
+
+// createDue creates a schedule whose first occurrence is already due.
+func createDue(t *testing.T, db *sql.DB, service *schedule.Service, freq schedule.Frequency) *schedule.Schedule {
+	sch := &schedule.Schedule{
+		FromAccountID: createAccount(t, db, "Payer"),
+		ToAccountID:   createAccount(t, db, "Payee"),
+		Amount:        1_000,
+		Currency:      "USD",
+		Frequency:     freq,
+		StartAt:       time.Now().UTC().Add(-time.Minute),
+	}
+	if err := service.Create(context.Background(), sch); err != nil {
+		t.Fatalf("create schedule failed: %v", err)
+	}
+	return sch
+}
+
+func runDue(t *testing.T, service *schedule.Service) {
+	t.Helper()
+	if _, err := service.RunDue(context.Background(), 10); err != nil {
+		t.Fatalf("run due failed: %v", err)
+	}
+}
+
+func TestRunDue_SettlesOccurrence(t *testing.T) {
+	db := setupTestDB(t)
+	ctx := context.Background()
+
+	transfers := &fakeTransfers{}
+	service := schedule.NewService(db, transfers, &fakeAccounts{})
+	sch := createDue(t, db, service, schedule.FrequencyOnce)
+
+	runDue(t, service)
+
+	got, err := service.Get(ctx, sch.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Status != schedule.StatusCompleted || got.RunCount != 1 || got.NextRunAt != nil {
+		t.Fatalf("expected a completed schedule after one run, got %+v", got)
+	}
+
+	runs, _ := service.Runs(ctx, sch.ID)
+	if len(runs) != 1 || runs[0].Status != schedule.RunSucceeded {
+		t.Fatalf("expected one successful run, got %+v", runs)
+	}
+
+	var leased bool
+	db.QueryRow(`SELECT locked_until IS NOT NULL FROM transfer_schedules WHERE id = $1`, sch.ID).Scan(&leased)
+	if leased {
+		t.Fatal("expected the claim lease to be released")
+	}
+}
+
+func TestRunDue_RetriesThenFails(t *testing.T) {
+	db := setupTestDB(t)
+	ctx := context.Background()
+
+	transfers := &fakeTransfers{errs: []error{
+		transaction.ErrInsufficientFunds,
+		transaction.ErrInsufficientFunds,
+		transaction.ErrInsufficientFunds,
+	}}
+	service := schedule.NewService(db, transfers, &fakeAccounts{})
+	sch := createDue(t, db, service, schedule.FrequencyDaily)
+
+	for attempt := 1; attempt <= schedule.DefaultRetryPolicy.MaxAttempts; attempt++ {
+		// bring the retry forward instead of waiting out the backoff
+		db.Exec(`UPDATE transfer_schedules SET next_run_at = now() - interval '1 minute' WHERE id = $1`, sch.ID)
+		runDue(t, service)
+	}
+
+	if transfers.calls != 3 {
+		t.Fatalf("expected 3 attempts, got %d", transfers.calls)
+	}
+
+	got, _ := service.Get(ctx, sch.ID)
+	if got.Status != schedule.StatusActive || got.RunCount != 1 || got.Attempt != 0 {
+		t.Fatalf("expected the failed occurrence to be settled and the schedule to move on, got %+v", got)
+	}
+
+	runs, _ := service.Runs(ctx, sch.ID)
+	if len(runs) != 3 || runs[0].Status != schedule.RunFailed || runs[1].Status != schedule.RunRetrying {
+		t.Fatalf("expected two retries and a failure, got %+v", runs)
+	}
+
+	var events int
+	db.QueryRow(`SELECT COUNT(*) FROM outbox_events WHERE event_type = 'schedule.run_failed' AND (payload->>'schedule_id')::bigint = $1`, sch.ID).Scan(&events)
+	if events != 1 {
+		t.Fatalf("expected one failure event, got %d", events)
+	}
+}
+
+func TestRunDue_CancelDuringRun(t *testing.T) {
+	db := setupTestDB(t)
+	ctx := context.Background()
+
+	transfers := &fakeTransfers{}
+	service := schedule.NewService(db, transfers, &fakeAccounts{})
+	sch := createDue(t, db, service, schedule.FrequencyDaily)
+
+	transfers.during = func() {
+		if _, err := service.Cancel(ctx, sch.ID); err != nil {
+			t.Errorf("cancel failed: %v", err)
+		}
+	}
+
+	runDue(t, service)
+
+	got, _ := service.Get(ctx, sch.ID)
+	if got.Status != schedule.StatusCancelled || got.NextRunAt != nil {
+		t.Fatalf("expected the cancel to stick, got %+v", got)
+	}
+	if got.RunCount != 1 {
+		t.Fatalf("expected the in-flight run to be counted, got %d", got.RunCount)
+	}
+}
+
+func TestRunDue_PauseDuringRun(t *testing.T) {
+	db := setupTestDB(t)
+	ctx := context.Background()
+
+	transfers := &fakeTransfers{}
+	service := schedule.NewService(db, transfers, &fakeAccounts{})
+	sch := createDue(t, db, service, schedule.FrequencyDaily)
+
+	paused := schedule.StatusPaused
+	transfers.during = func() {
+		if _, err := service.Update(ctx, sch.ID, schedule.UpdateRequest{Status: &paused}); err != nil {
+			t.Errorf("pause failed: %v", err)
+		}
+	}
+
+	runDue(t, service)
+
+	got, _ := service.Get(ctx, sch.ID)
+	if got.Status != schedule.StatusPaused || got.RunCount != 1 {
+		t.Fatalf("expected a paused schedule with the run counted, got %+v", got)
+	}
+}
+
+func TestUpdate_KeepsClaimLease(t *testing.T) {
+	db := setupTestDB(t)
+	ctx := context.Background()
+
+	transfers := &fakeTransfers{}
+	service := schedule.NewService(db, transfers, &fakeAccounts{})
+	sch := createDue(t, db, service, schedule.FrequencyDaily)
+
+	note := "rent"
+	transfers.during = func() {
+		if _, err := service.Update(ctx, sch.ID, schedule.UpdateRequest{Note: &note}); err != nil {
+			t.Errorf("update failed: %v", err)
+		}
+
+		var leased bool
+		db.QueryRow(`SELECT locked_until IS NOT NULL FROM transfer_schedules WHERE id = $1`, sch.ID).Scan(&leased)
+		if !leased {
+			t.Error("an edit released the lease of a running schedule")
+		}
+	}
+
+	runDue(t, service)
+
+	got, _ := service.Get(ctx, sch.ID)
+	if got.Note != note || got.RunCount != 1 {
+		t.Fatalf("expected the edit and the run to both be kept, got %+v", got)
+	}
+}
+
+func TestUpdate_RejectsEndBeforeStart(t *testing.T) {
+	db := setupTestDB(t)
+	ctx := context.Background()
+
+	service := schedule.NewService(db, &fakeTransfers{}, &fakeAccounts{})
+	sch := createDue(t, db, service, schedule.FrequencyDaily)
+
+	end := sch.StartAt.Add(-time.Hour)
+	if _, err := service.Update(ctx, sch.ID, schedule.UpdateRequest{EndAt: &end}); !errors.Is(err, schedule.ErrInvalidSchedule) {
+		t.Fatalf("expected end_at before start_at to be rejected, got %v", err)
+	}
+}
+
+func TestCreate_ChecksAccounts(t *testing.T) {
+	db := setupTestDB(t)
+	ctx := context.Background()
+
+	from := createAccount(t, db, "Payer")
+	to := createAccount(t, db, "Payee")
+	accounts := &fakeAccounts{missing: map[int64]bool{}, currencies: map[int64]string{}}
+	service := schedule.NewService(db, &fakeTransfers{}, accounts)
+
+	newSchedule := func(currency string) *schedule.Schedule {
+		return &schedule.Schedule{FromAccountID: from, ToAccountID: to, Amount: 1_000, Currency: currency, Frequency: schedule.FrequencyDaily}
+	}
+
+	accounts.missing[to] = true
+	if err := service.Create(ctx, newSchedule("USD")); !errors.Is(err, transaction.ErrAccountNotFound) {
+		t.Fatalf("missing payee: expected ErrAccountNotFound, got %v", err)
+	}
+	accounts.missing[to] = false
+
+	accounts.currencies[to] = "EUR"
+	if err := service.Create(ctx, newSchedule("USD")); !errors.Is(err, transaction.ErrCurrencyMismatch) {
+		t.Fatalf("payee in another currency: expected ErrCurrencyMismatch, got %v", err)
+	}
+	accounts.currencies[to] = "USD"
+
+	// an omitted currency is the payer's
+	sch := newSchedule("")
+	if err := service.Create(ctx, sch); err != nil {
+		t.Fatal(err)
+	}
+	if sch.Currency != "USD" {
+		t.Fatalf("expected the payer's currency, got %q", sch.Currency)
+	}
+
+	schedules, _ := service.ListByAccount(ctx, from)
+	if len(schedules) != 1 {
+		t.Fatalf("expected only the valid schedule to be stored, got %d", len(schedules))
+	}
+}
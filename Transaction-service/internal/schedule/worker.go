@@ -0,0 +1,50 @@
+package schedule
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// Worker executes due scheduled transfers.
+type Worker struct {
+	service   *Service
+	interval  time.Duration
+	batchSize int
+}
+
+func NewWorker(service *Service) *Worker {
+	return &Worker{
+		service:   service,
+		interval:  30 * time.Second,
+		batchSize: 50,
+	}
+}
+
+func (w *Worker) Start(ctx context.Context) {
+	log.Println("🚀 Schedule worker started")
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Println("🛑 Schedule worker stopped")
+			return
+
+		case <-ticker.C:
+			for {
+				n, err := w.service.RunDue(ctx, w.batchSize)
+				if err != nil {
+					log.Println("❌ Run due schedules failed:", err)
+					break
+				}
+
+				if n < w.batchSize {
+					break
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,150 @@
+package statement
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"transaction/internal/transaction"
+
+	"github.com/go-chi/chi"
+)
+
+type Handler struct {
+	service *Service
+}
+
+func NewHandler(service *Service) *Handler {
+	return &Handler{service: service}
+}
+
+// List returns the stored statements of the account, without entries.
+func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
+	accountID, ok := accountID(w, r)
+	if !ok {
+		return
+	}
+
+	statements, err := h.service.List(r.Context(), accountID)
+	if err != nil {
+		transaction.RespondServiceError(w, err)
+		return
+	}
+
+	transaction.RespondJSON(w, http.StatusOK, transaction.SuccessResponse{
+		Status:  "success",
+		Message: "Statements retrieved successfully",
+		Data:    statements,
+	})
+}
+
+func (h *Handler) Get(w http.ResponseWriter, r *http.Request) {
+	accountID, ok := accountID(w, r)
+	if !ok {
+		return
+	}
+
+	id, err := strconv.ParseInt(chi.URLParam(r, "statementID"), 10, 64)
+	if err != nil {
+		transaction.RespondError(w, http.StatusBadRequest, "INVALID_STATEMENT_ID", "Statement ID must be a number")
+		return
+	}
+
+	st, err := h.service.Get(r.Context(), accountID, id)
+	if err != nil {
+		transaction.RespondServiceError(w, err)
+		return
+	}
+
+	transaction.RespondJSON(w, http.StatusOK, transaction.SuccessResponse{
+		Status:  "success",
+		Message: "Statement retrieved successfully",
+		Data:    st,
+	})
+}
+
+// Preview builds statements for an arbitrary period without storing them.
+// The period is either month=YYYY-MM or from/to as RFC 3339 timestamps or
+// YYYY-MM-DD dates, where a date-only "to" includes that whole day.
+func (h *Handler) Preview(w http.ResponseWriter, r *http.Request) {
+	accountID, ok := accountID(w, r)
+	if !ok {
+		return
+	}
+
+	start, end, err := parsePeriod(r)
+	if err != nil {
+		transaction.RespondServiceError(w, err)
+		return
+	}
+
+	currency := strings.ToUpper(strings.TrimSpace(r.URL.Query().Get("currency")))
+
+	statements, err := h.service.Preview(r.Context(), accountID, currency, start, end)
+	if err != nil {
+		transaction.RespondServiceError(w, err)
+		return
+	}
+
+	transaction.RespondJSON(w, http.StatusOK, transaction.SuccessResponse{
+		Status:  "success",
+		Message: "Statements built successfully",
+		Data:    statements,
+	})
+}
+
+func parsePeriod(r *http.Request) (time.Time, time.Time, error) {
+	q := r.URL.Query()
+
+	if v := q.Get("month"); v != "" {
+		month, err := time.Parse("2006-01", v)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("%w: month must be YYYY-MM", transaction.ErrInvalidPeriod)
+		}
+		start, end := Month(month)
+		return start, end, nil
+	}
+
+	from, _, err := parsePeriodTime(q.Get("from"))
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("%w: from must be an RFC 3339 timestamp or YYYY-MM-DD date", transaction.ErrInvalidPeriod)
+	}
+
+	to, dateOnly, err := parsePeriodTime(q.Get("to"))
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("%w: to must be an RFC 3339 timestamp or YYYY-MM-DD date", transaction.ErrInvalidPeriod)
+	}
+
+	if dateOnly {
+		to = to.AddDate(0, 0, 1)
+	}
+
+	return from, to, nil
+}
+
+func parsePeriodTime(v string) (time.Time, bool, error) {
+	if t, err := time.Parse("2006-01-02", v); err == nil {
+		return t, true, nil
+	}
+	t, err := time.Parse(time.RFC3339, v)
+	return t.UTC(), false, err
+}
+
+// Routes is mounted under /accounts.
+func (h *Handler) Routes() http.Handler {
+	r := chi.NewRouter()
+	r.Get("/{id}/statements", h.List)
+	r.Get("/{id}/statements/preview", h.Preview)
+	r.Get("/{id}/statements/{statementID}", h.Get)
+	return r
+}
+
+func accountID(w http.ResponseWriter, r *http.Request) (int64, bool) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		transaction.RespondError(w, http.StatusBadRequest, "INVALID_ACCOUNT_ID", "Account ID must be a number")
+		return 0, false
+	}
+	return id, true
+}
@@ -0,0 +1,88 @@
+package statement
+
+import (
+	"fmt"
+	"time"
+	"transaction/internal/transaction"
+)
+
+// MaxPreviewDays caps the period a preview may cover; every entry of the
+// period is read and returned.
+const MaxPreviewDays = 366
+
+var (
+	ErrStatementNotFound = transaction.ErrStatementNotFound
+	ErrInvalidPeriod     = fmt.Errorf("%w: it must end after it starts", transaction.ErrInvalidPeriod)
+	ErrPeriodTooLong     = fmt.Errorf("%w: it must not be longer than %d days", transaction.ErrInvalidPeriod, MaxPreviewDays)
+)
+
+// Statement summarises one currency of an account over [PeriodStart,
+// PeriodEnd). Stored statements are never changed once generated.
+type Statement struct {
+	ID             int64                      `json:"id,omitempty"`
+	AccountID      int64                      `json:"account_id"`
+	Currency       string                     `json:"currency"`
+	PeriodStart    time.Time                  `json:"period_start"`
+	PeriodEnd      time.Time                  `json:"period_end"` // exclusive
+	OpeningBalance int64                      `json:"opening_balance"`
+	ClosingBalance int64                      `json:"closing_balance"`
+	Totals         map[transaction.Type]int64 `json:"totals"` // cents per entry type
+	Entries        []Entry                    `json:"entries,omitempty"`
+	GeneratedAt    time.Time                  `json:"generated_at"`
+}
+
+// Entry is one ledger entry of the period with the balance once it applied.
+type Entry struct {
+	TransactionID int64            `json:"transaction_id"`
+	JournalID     int64            `json:"journal_id"`
+	Type          transaction.Type `json:"type"`
+	Amount        int64            `json:"amount"` // cents, always positive
+	Balance       int64            `json:"balance"`
+	Note          string           `json:"note"`
+	CreatedAt     time.Time        `json:"created_at"`
+}
+
+// NewStatement derives running balances, totals and the closing balance
+// from the opening balance and the period's entries in ledger order.
+func NewStatement(accountID int64, currency string, start, end time.Time, opening int64, entries []Entry) *Statement {
+	st := &Statement{
+		AccountID:      accountID,
+		Currency:       currency,
+		PeriodStart:    start,
+		PeriodEnd:      end,
+		OpeningBalance: opening,
+		ClosingBalance: opening,
+		Totals:         map[transaction.Type]int64{},
+		Entries:        entries,
+	}
+
+	for i := range st.Entries {
+		e := &st.Entries[i]
+
+		if e.Type.Credit() {
+			st.ClosingBalance += e.Amount
+		} else {
+			st.ClosingBalance -= e.Amount
+		}
+
+		e.Balance = st.ClosingBalance
+		st.Totals[e.Type] += e.Amount
+	}
+
+	return st
+}
+
+// Month returns the calendar month (UTC) that contains t.
+func Month(t time.Time) (time.Time, time.Time) {
+	t = t.UTC()
+	start := time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+	return start, start.AddDate(0, 1, 0)
+}
+
+// ClosedMonth returns the start of the last month that has closed at now.
+// A month closes transaction.SettleDelay after its end, so entries dated
+// in it that were still committing at midnight are in its statement.
+func ClosedMonth(now time.Time) time.Time {
+	current, _ := Month(now.Add(-transaction.SettleDelay))
+	return current.AddDate(0, -1, 0)
+}
@@ -0,0 +1,238 @@
+package statement
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+	"transaction/internal/infrastructure/database"
+	"transaction/internal/transaction"
+)
+
+var _ Repository = (*PostgresRepo)(nil)
+
+type PostgresRepo struct {
+	db database.DBTX
+}
+
+func NewPostgresRepo(db database.DBTX) *PostgresRepo {
+	return &PostgresRepo{db: db}
+}
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+const summaryColumns = `id, account_id, currency, period_start, period_end,
+	opening_balance, closing_balance, totals, generated_at`
+
+func scanStatement(row rowScanner, extra ...any) (*Statement, error) {
+	st := &Statement{}
+	var totals []byte
+
+	err := row.Scan(append([]any{
+		&st.ID,
+		&st.AccountID,
+		&st.Currency,
+		&st.PeriodStart,
+		&st.PeriodEnd,
+		&st.OpeningBalance,
+		&st.ClosingBalance,
+		&totals,
+		&st.GeneratedAt,
+	}, extra...)...)
+	if err == sql.ErrNoRows {
+		return nil, ErrStatementNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return st, json.Unmarshal(totals, &st.Totals)
+}
+
+func (r *PostgresRepo) Create(ctx context.Context, st *Statement) (bool, error) {
+	totals, err := json.Marshal(st.Totals)
+	if err != nil {
+		return false, err
+	}
+
+	entries, err := json.Marshal(st.Entries)
+	if err != nil {
+		return false, err
+	}
+
+	err = r.db.QueryRowContext(ctx, `
+		INSERT INTO statements (account_id, currency, period_start, period_end,
+			opening_balance, closing_balance, totals, entries)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (account_id, currency, period_start, period_end) DO NOTHING
+		RETURNING id, generated_at
+	`,
+		st.AccountID, st.Currency, st.PeriodStart, st.PeriodEnd,
+		st.OpeningBalance, st.ClosingBalance, totals, entries,
+	).Scan(&st.ID, &st.GeneratedAt)
+
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (r *PostgresRepo) GetByID(ctx context.Context, id int64) (*Statement, error) {
+	var entries []byte
+
+	st, err := scanStatement(r.db.QueryRowContext(ctx, `
+		SELECT `+summaryColumns+`, entries
+		FROM statements
+		WHERE id = $1
+	`, id), &entries)
+	if err != nil {
+		return nil, err
+	}
+
+	return st, json.Unmarshal(entries, &st.Entries)
+}
+
+func (r *PostgresRepo) ListByAccount(ctx context.Context, accountID int64) ([]Statement, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+summaryColumns+`
+		FROM statements
+		WHERE account_id = $1
+		ORDER BY period_start DESC, currency
+	`, accountID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var statements []Statement
+	for rows.Next() {
+		st, err := scanStatement(rows)
+		if err != nil {
+			return nil, err
+		}
+		statements = append(statements, *st)
+	}
+
+	return statements, rows.Err()
+}
+
+func (r *PostgresRepo) Ledger(ctx context.Context, accountID int64, currency string, start, end time.Time) (int64, []Entry, error) {
+	// the opening balance is summed per type so the sign of each type
+	// comes from the same place as everywhere else
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT type, SUM(amount)
+		FROM transactions
+		WHERE account_id = $1 AND currency = $2 AND created_at < $3
+		GROUP BY type
+	`, accountID, currency, start)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer rows.Close()
+
+	var opening int64
+	for rows.Next() {
+		var t transaction.Type
+		var sum int64
+		if err := rows.Scan(&t, &sum); err != nil {
+			return 0, nil, err
+		}
+
+		if t.Credit() {
+			opening += sum
+		} else {
+			opening -= sum
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return 0, nil, err
+	}
+
+	entryRows, err := r.db.QueryContext(ctx, `
+		SELECT id, COALESCE(journal_id, 0), type, amount, COALESCE(note, ''), created_at
+		FROM transactions
+		WHERE account_id = $1 AND currency = $2
+		  AND created_at >= $3 AND created_at < $4
+		ORDER BY created_at, id
+	`, accountID, currency, start, end)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer entryRows.Close()
+
+	var entries []Entry
+	for entryRows.Next() {
+		var e Entry
+		if err := entryRows.Scan(&e.TransactionID, &e.JournalID, &e.Type, &e.Amount, &e.Note, &e.CreatedAt); err != nil {
+			return 0, nil, err
+		}
+		entries = append(entries, e)
+	}
+
+	return opening, entries, entryRows.Err()
+}
+
+func (r *PostgresRepo) Currencies(ctx context.Context, accountID int64) ([]string, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT currency
+		FROM account_balances
+		WHERE account_id = $1
+		ORDER BY currency
+	`, accountID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var currencies []string
+	for rows.Next() {
+		var c string
+		if err := rows.Scan(&c); err != nil {
+			return nil, err
+		}
+		currencies = append(currencies, c)
+	}
+
+	return currencies, rows.Err()
+}
+
+func (r *PostgresRepo) Due(ctx context.Context, start, end time.Time, limit int) ([]AccountCurrency, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT b.account_id, b.currency
+		FROM account_balances b
+		WHERE EXISTS (
+			SELECT 1
+			FROM transactions t
+			WHERE t.account_id = b.account_id
+			  AND t.currency = b.currency
+			  AND t.created_at < $2
+		  )
+		  AND NOT EXISTS (
+			SELECT 1
+			FROM statements s
+			WHERE s.account_id = b.account_id
+			  AND s.currency = b.currency
+			  AND s.period_start = $1
+			  AND s.period_end = $2
+		  )
+		ORDER BY b.account_id, b.currency
+		LIMIT $3
+	`, start, end, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var due []AccountCurrency
+	for rows.Next() {
+		var ac AccountCurrency
+		if err := rows.Scan(&ac.AccountID, &ac.Currency); err != nil {
+			return nil, err
+		}
+		due = append(due, ac)
+	}
+
+	return due, rows.Err()
+}
@@ -0,0 +1,29 @@
+package statement
+
+import (
+	"context"
+	"time"
+)
+
+// AccountCurrency is one currency an account holds.
+type AccountCurrency struct {
+	AccountID int64
+	Currency  string
+}
+
+type Repository interface {
+	// Create stores st unless a statement for the same account, currency
+	// and period exists; it reports whether st was stored.
+	Create(ctx context.Context, st *Statement) (bool, error)
+	GetByID(ctx context.Context, id int64) (*Statement, error)
+	// ListByAccount returns the account's statements, newest first and
+	// without their entries.
+	ListByAccount(ctx context.Context, accountID int64) ([]Statement, error)
+	// Ledger returns the balance before start and the entries posted in
+	// [start, end) in ledger order.
+	Ledger(ctx context.Context, accountID int64, currency string, start, end time.Time) (int64, []Entry, error)
+	Currencies(ctx context.Context, accountID int64) ([]string, error)
+	// Due lists up to limit account currencies with ledger history before
+	// end that have no statement for [start, end) yet.
+	Due(ctx context.Context, start, end time.Time, limit int) ([]AccountCurrency, error)
+}
@@ -0,0 +1,138 @@
+package statement
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+type Service struct {
+	db   *sql.DB
+	repo Repository
+	now  func() time.Time
+}
+
+func NewService(db *sql.DB) *Service {
+	return &Service{
+		db:   db,
+		repo: NewPostgresRepo(db),
+		now:  func() time.Time { return time.Now().UTC() },
+	}
+}
+
+// Build computes the statement of [start, end) from the ledger without
+// storing it. The opening balance and the entries are read from one
+// snapshot, so they always add up to the closing balance.
+func (s *Service) Build(ctx context.Context, accountID int64, currency string, start, end time.Time) (*Statement, error) {
+	start, end = start.UTC(), end.UTC()
+	if !end.After(start) {
+		return nil, ErrInvalidPeriod
+	}
+
+	tx, err := s.db.BeginTx(ctx, &sql.TxOptions{
+		Isolation: sql.LevelRepeatableRead,
+		ReadOnly:  true,
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	opening, entries, err := NewPostgresRepo(tx).Ledger(ctx, accountID, currency, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	st := NewStatement(accountID, currency, start, end, opening, entries)
+	st.GeneratedAt = s.now()
+	return st, nil
+}
+
+// Preview builds the statements of a period of up to MaxPreviewDays on
+// demand, one per currency the account holds, or only for currency if it
+// is set.
+func (s *Service) Preview(ctx context.Context, accountID int64, currency string, start, end time.Time) ([]Statement, error) {
+	if !end.After(start) {
+		return nil, ErrInvalidPeriod
+	}
+	if end.Sub(start) > MaxPreviewDays*24*time.Hour {
+		return nil, ErrPeriodTooLong
+	}
+
+	currencies := []string{currency}
+	if currency == "" {
+		var err error
+		currencies, err = s.repo.Currencies(ctx, accountID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	statements := []Statement{}
+	for _, c := range currencies {
+		st, err := s.Build(ctx, accountID, c, start, end)
+		if err != nil {
+			return nil, err
+		}
+		statements = append(statements, *st)
+	}
+
+	return statements, nil
+}
+
+func (s *Service) List(ctx context.Context, accountID int64) ([]Statement, error) {
+	return s.repo.ListByAccount(ctx, accountID)
+}
+
+func (s *Service) Get(ctx context.Context, accountID, id int64) (*Statement, error) {
+	st, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if st.AccountID != accountID {
+		return nil, ErrStatementNotFound
+	}
+
+	return st, nil
+}
+
+// GenerateMonth stores the statement of the calendar month containing month
+// for up to limit account currencies that do not have one yet.
+func (s *Service) GenerateMonth(ctx context.Context, month time.Time, limit int) (int, error) {
+	start, end := Month(month)
+
+	due, err := s.repo.Due(ctx, start, end, limit)
+	if err != nil {
+		return 0, err
+	}
+
+	generated := 0
+	for _, ac := range due {
+		st, err := s.Build(ctx, ac.AccountID, ac.Currency, start, end)
+		if err != nil {
+			return generated, fmt.Errorf("account %d %s: %w", ac.AccountID, ac.Currency, err)
+		}
+
+		created, err := s.repo.Create(ctx, st)
+		if err != nil {
+			return generated, fmt.Errorf("account %d %s: %w", ac.AccountID, ac.Currency, err)
+		}
+
+		if created {
+			generated++
+		}
+	}
+
+	return generated, nil
+}
+
+// RunMonthEnd generates the statements of the last month that has closed.
+func (s *Service) RunMonthEnd(ctx context.Context, limit int) (int, error) {
+	return s.GenerateMonth(ctx, ClosedMonth(s.now()), limit)
+}
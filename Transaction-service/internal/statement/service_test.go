@@ -0,0 +1,238 @@
+package statement_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/url"
+	"os"
+	"testing"
+	"time"
+	"transaction/internal/statement"
+	"transaction/internal/transaction"
+
+	"github.com/joho/godotenv"
+)
+
+func setupTestDB(t *testing.T) *sql.DB {
+	_ = godotenv.Load("../../.env")
+	rawURL := os.Getenv("DATABASE_URL")
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		t.Fatalf("Invalid DATABASE_URL: %v", err)
+	}
+
+	// Force usage of transaction_test database for tests
+	u.Path = "/transaction_test"
+
+	db, err := sql.Open("postgres", u.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	db.Exec(`
+CREATE TABLE IF NOT EXISTS accounts (
+    id BIGSERIAL PRIMARY KEY,
+    name TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT now(),
+    updated_at TIMESTAMP NOT NULL DEFAULT now()
+);
+
+CREATE TABLE IF NOT EXISTS journal_entries (
+    id BIGSERIAL PRIMARY KEY,
+    type TEXT NOT NULL,
+    reverses_journal_id BIGINT REFERENCES journal_entries(id),
+    batch_id UUID,
+    currency TEXT NOT NULL DEFAULT 'USD',
+    note TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT now()
+);
+
+CREATE TABLE IF NOT EXISTS transactions (
+    id BIGSERIAL PRIMARY KEY,
+    account_id BIGINT NOT NULL REFERENCES accounts(id) ON DELETE CASCADE,
+    journal_id BIGINT REFERENCES journal_entries(id),
+    type TEXT NOT NULL CHECK (type IN ('DEPOSIT', 'WITHDRAW', 'TRANSFER_IN', 'TRANSFER_OUT', 'REVERSAL_IN', 'REVERSAL_OUT', 'FEE', 'INTEREST')),
+    amount BIGINT NOT NULL CHECK (amount > 0),
+    currency TEXT NOT NULL DEFAULT 'USD',
+    balance_after BIGINT NOT NULL,
+    reversal_of BIGINT REFERENCES transactions(id),
+    counterparty_account_id BIGINT REFERENCES accounts(id),
+    fee_for BIGINT REFERENCES transactions(id),
+    note TEXT,
+    metadata JSONB NOT NULL DEFAULT '{}',
+    tags TEXT[] NOT NULL DEFAULT '{}',
+    created_at TIMESTAMP NOT NULL DEFAULT now(),
+    chain_seq BIGINT,
+    prev_hash BYTEA,
+    hash BYTEA,
+    UNIQUE (account_id, chain_seq)
+);
+
+CREATE TABLE IF NOT EXISTS account_balances (
+    account_id BIGINT NOT NULL REFERENCES accounts(id) ON DELETE CASCADE,
+    currency TEXT NOT NULL DEFAULT 'USD',
+    balance BIGINT NOT NULL DEFAULT 0,
+    updated_at TIMESTAMP NOT NULL DEFAULT now(),
+    PRIMARY KEY (account_id, currency)
+);
+
+CREATE TABLE IF NOT EXISTS statements (
+    id BIGSERIAL PRIMARY KEY,
+    account_id BIGINT NOT NULL REFERENCES accounts(id),
+    currency TEXT NOT NULL,
+    period_start TIMESTAMP NOT NULL,
+    period_end TIMESTAMP NOT NULL,
+    opening_balance BIGINT NOT NULL,
+    closing_balance BIGINT NOT NULL,
+    totals JSONB NOT NULL,
+    entries JSONB NOT NULL,
+    generated_at TIMESTAMP NOT NULL DEFAULT now(),
+    CHECK (period_end > period_start),
+    UNIQUE (account_id, currency, period_start, period_end)
+);
+
+CREATE OR REPLACE FUNCTION reject_statement_change() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'statement % is immutable', OLD.id;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS statements_immutable ON statements;
+CREATE TRIGGER statements_immutable
+    BEFORE UPDATE OR DELETE ON statements
+    FOR EACH ROW EXECUTE FUNCTION reject_statement_change();
+`)
+
+	t.Cleanup(func() {
+		// TRUNCATE does not fire the row triggers that keep statements immutable
+		db.Exec("TRUNCATE accounts, transactions, account_balances, journal_entries, statements RESTART IDENTITY CASCADE")
+		db.Close()
+	})
+
+	return db
+}
+
+func createAccount(t *testing.T, db *sql.DB, name string) int64 {
+	var id int64
+	if err := db.QueryRow("INSERT INTO accounts (name) VALUES ($1) RETURNING id", name).Scan(&id); err != nil {
+		t.Fatalf("Failed to create test account: %v", err)
+	}
+	return id
+}
+
+// post writes a ledger entry at a given time, bypassing the service so the
+// test controls which period it falls in.
+func post(t *testing.T, db *sql.DB, accountID int64, typ transaction.Type, amount int64, at time.Time) {
+	t.Helper()
+
+	if _, err := db.Exec(`
+		INSERT INTO transactions (account_id, type, amount, currency, balance_after, created_at)
+		VALUES ($1, $2, $3, 'USD', 0, $4)
+	`, accountID, typ, amount, at); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := db.Exec(`
+		INSERT INTO account_balances (account_id, currency)
+		VALUES ($1, 'USD')
+		ON CONFLICT DO NOTHING
+	`, accountID); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func day(month time.Month, d int) time.Time {
+	return time.Date(2025, month, d, 12, 0, 0, 0, time.UTC)
+}
+
+func TestBuild_OpeningBalanceFromLedger(t *testing.T) {
+	db := setupTestDB(t)
+	ctx := context.Background()
+	service := statement.NewService(db)
+
+	acc := createAccount(t, db, "Saver")
+	post(t, db, acc, transaction.TypeDeposit, 5_000, day(time.January, 10))
+	post(t, db, acc, transaction.TypeWithdraw, 1_000, day(time.January, 20))
+	post(t, db, acc, transaction.TypeDeposit, 2_000, day(time.February, 3))
+	post(t, db, acc, transaction.TypeTransferOut, 500, day(time.February, 17))
+	post(t, db, acc, transaction.TypeDeposit, 100, day(time.March, 1))
+
+	start, end := statement.Month(day(time.February, 1))
+	st, err := service.Build(ctx, acc, "USD", start, end)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if st.OpeningBalance != 4_000 || st.ClosingBalance != 5_500 || len(st.Entries) != 2 {
+		t.Fatalf("expected 4000 to 5500 over 2 entries, got %+v", st)
+	}
+}
+
+func TestGenerateMonth_GeneratesOnce(t *testing.T) {
+	db := setupTestDB(t)
+	ctx := context.Background()
+	service := statement.NewService(db)
+
+	active := createAccount(t, db, "Active")
+	later := createAccount(t, db, "Opened Later")
+	post(t, db, active, transaction.TypeDeposit, 5_000, day(time.January, 10))
+	post(t, db, active, transaction.TypeWithdraw, 1_000, day(time.February, 10))
+	post(t, db, later, transaction.TypeDeposit, 1_000, day(time.March, 5))
+
+	// only accounts with history before the month ends are due
+	n, err := service.GenerateMonth(ctx, day(time.February, 1), 100)
+	if err != nil || n != 1 {
+		t.Fatalf("expected 1 statement, got %d, %v", n, err)
+	}
+
+	n, err = service.GenerateMonth(ctx, day(time.February, 1), 100)
+	if err != nil || n != 0 {
+		t.Fatalf("expected nothing left to generate, got %d, %v", n, err)
+	}
+
+	statements, err := service.List(ctx, active)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(statements) != 1 || statements[0].OpeningBalance != 5_000 || statements[0].ClosingBalance != 4_000 {
+		t.Fatalf("expected one February statement from 5000 to 4000, got %+v", statements)
+	}
+
+	if statements, _ := service.List(ctx, later); len(statements) != 0 {
+		t.Fatalf("expected no statement for an account opened later, got %+v", statements)
+	}
+
+	stored, err := service.Get(ctx, active, statements[0].ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stored.Entries) != 1 || stored.Entries[0].Balance != 4_000 {
+		t.Fatalf("expected the stored entries with running balances, got %+v", stored.Entries)
+	}
+
+	if _, err := service.Get(ctx, later, statements[0].ID); !errors.Is(err, statement.ErrStatementNotFound) {
+		t.Fatalf("expected another account's statement to be hidden, got %v", err)
+	}
+}
+
+func TestStatements_Immutable(t *testing.T) {
+	db := setupTestDB(t)
+	ctx := context.Background()
+	service := statement.NewService(db)
+
+	acc := createAccount(t, db, "Saver")
+	post(t, db, acc, transaction.TypeDeposit, 5_000, day(time.January, 10))
+
+	if n, err := service.GenerateMonth(ctx, day(time.January, 1), 100); err != nil || n != 1 {
+		t.Fatalf("expected 1 statement, got %d, %v", n, err)
+	}
+
+	if _, err := db.Exec(`UPDATE statements SET closing_balance = 0`); err == nil {
+		t.Fatal("expected a stored statement to reject updates")
+	}
+
+	if _, err := db.Exec(`DELETE FROM statements`); err == nil {
+		t.Fatal("expected a stored statement to reject deletes")
+	}
+}
@@ -0,0 +1,136 @@
+package statement_test
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+	"transaction/internal/statement"
+	"transaction/internal/transaction"
+)
+
+func TestNewStatement_RunningBalanceAndTotals(t *testing.T) {
+	start, end := statement.Month(time.Date(2025, 3, 14, 9, 0, 0, 0, time.UTC))
+
+	st := statement.NewStatement(7, "USD", start, end, 5_000, []statement.Entry{
+		{TransactionID: 1, Type: transaction.TypeDeposit, Amount: 2_000},
+		{TransactionID: 2, Type: transaction.TypeWithdraw, Amount: 1_500},
+		{TransactionID: 3, Type: transaction.TypeTransferOut, Amount: 500},
+		{TransactionID: 4, Type: transaction.TypeDeposit, Amount: 1_000},
+		{TransactionID: 5, Type: transaction.TypeReversalIn, Amount: 500},
+	})
+
+	wantBalances := []int64{7_000, 5_500, 5_000, 6_000, 6_500}
+	for i, e := range st.Entries {
+		if e.Balance != wantBalances[i] {
+			t.Fatalf("entry %d: expected balance %d, got %d", i, wantBalances[i], e.Balance)
+		}
+	}
+
+	if st.ClosingBalance != 6_500 {
+		t.Fatalf("expected closing balance 6500, got %d", st.ClosingBalance)
+	}
+
+	wantTotals := map[transaction.Type]int64{
+		transaction.TypeDeposit:     3_000,
+		transaction.TypeWithdraw:    1_500,
+		transaction.TypeTransferOut: 500,
+		transaction.TypeReversalIn:  500,
+	}
+	for typ, want := range wantTotals {
+		if st.Totals[typ] != want {
+			t.Fatalf("%s: expected total %d, got %d", typ, want, st.Totals[typ])
+		}
+	}
+}
+
+func TestNewStatement_EmptyPeriod(t *testing.T) {
+	start, end := statement.Month(time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC))
+
+	st := statement.NewStatement(7, "USD", start, end, 1_234, nil)
+	if st.OpeningBalance != 1_234 || st.ClosingBalance != 1_234 || len(st.Totals) != 0 {
+		t.Fatalf("expected an unchanged balance and no totals, got %+v", st)
+	}
+}
+
+func TestMonth(t *testing.T) {
+	start, end := statement.Month(time.Date(2024, 12, 31, 23, 59, 0, 0, time.UTC))
+
+	if !start.Equal(time.Date(2024, 12, 1, 0, 0, 0, 0, time.UTC)) || !end.Equal(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("unexpected month bounds %v - %v", start, end)
+	}
+}
+
+func TestClosedMonth_WaitsForTheSettleWindow(t *testing.T) {
+	january := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	february := time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)
+
+	// just past midnight, February may still have entries committing
+	if got := statement.ClosedMonth(time.Date(2025, 3, 1, 0, 5, 0, 0, time.UTC)); !got.Equal(january) {
+		t.Fatalf("just past midnight: expected %v, got %v", january, got)
+	}
+
+	if got := statement.ClosedMonth(time.Date(2025, 3, 1, 1, 5, 0, 0, time.UTC)); !got.Equal(february) {
+		t.Fatalf("after the settle window: expected %v, got %v", february, got)
+	}
+}
+
+func TestPreview_RejectsLongPeriod(t *testing.T) {
+	// the period is checked before anything is read
+	service := statement.NewService(nil)
+
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(0, 0, statement.MaxPreviewDays+1)
+
+	if _, err := service.Preview(context.Background(), 7, "USD", start, end); !errors.Is(err, statement.ErrPeriodTooLong) {
+		t.Fatalf("expected ErrPeriodTooLong, got %v", err)
+	}
+}
+
+func getStatements(t *testing.T, service *statement.Service, path string) (int, transaction.ErrorResponse) {
+	t.Helper()
+
+	rec := httptest.NewRecorder()
+	statement.NewHandler(service).Routes().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+
+	var resp transaction.ErrorResponse
+	json.NewDecoder(rec.Body).Decode(&resp)
+	return rec.Code, resp
+}
+
+func TestHandler_MapsPeriodErrors(t *testing.T) {
+	service := statement.NewService(nil)
+
+	for _, path := range []string{
+		"/7/statements/preview?month=March",
+		"/7/statements/preview?from=2024-01-01&to=2025-06-30",
+		"/7/statements/preview?from=2024-02-01&to=2024-01-01",
+	} {
+		code, resp := getStatements(t, service, path)
+		if code != http.StatusBadRequest || resp.Error.Code != "INVALID_PERIOD" {
+			t.Fatalf("%s: expected 400 INVALID_PERIOD, got %d %+v", path, code, resp.Error)
+		}
+	}
+}
+
+func TestHandler_HidesInternalErrors(t *testing.T) {
+	// nothing listens on port 1, so every query fails
+	db, err := sql.Open("postgres", "host=127.0.0.1 port=1 sslmode=disable connect_timeout=1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	code, resp := getStatements(t, statement.NewService(db), "/7/statements")
+	if code != http.StatusInternalServerError || resp.Error.Code != "INTERNAL_ERROR" {
+		t.Fatalf("expected 500 INTERNAL_ERROR, got %d %+v", code, resp.Error)
+	}
+	if strings.Contains(resp.Error.Message, "127.0.0.1") {
+		t.Fatalf("internal error details leaked: %q", resp.Error.Message)
+	}
+}
@@ -0,0 +1,55 @@
+package statement
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// Worker generates month-end statements once a month has closed.
+type Worker struct {
+	service   *Service
+	interval  time.Duration
+	batchSize int
+}
+
+func NewWorker(service *Service) *Worker {
+	return &Worker{
+		service:   service,
+		interval:  time.Hour,
+		batchSize: 100,
+	}
+}
+
+func (w *Worker) Start(ctx context.Context) {
+	log.Println("🚀 Statement worker started")
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Println("🛑 Statement worker stopped")
+			return
+
+		case <-ticker.C:
+			// drain everything that is due before waiting again
+			for {
+				n, err := w.service.RunMonthEnd(ctx, w.batchSize)
+				if err != nil {
+					log.Println("❌ Generate statements failed:", err)
+					break
+				}
+
+				if n > 0 {
+					log.Printf("🧾 Generated %d statements", n)
+				}
+
+				if n < w.batchSize {
+					break
+				}
+			}
+		}
+	}
+}
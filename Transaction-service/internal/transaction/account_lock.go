@@ -0,0 +1,31 @@
+package transaction
+
+import (
+	"context"
+	"slices"
+	"transaction/internal/infrastructure/database"
+)
+
+// lockAccounts serializes writers per account: it takes a
+// transaction-scoped advisory lock on each account, released on commit or
+// rollback. Every path that debits an account takes its lock before reading
+// the balance, so two debits cannot both pass the funds check. Locks are
+// taken in ascending ID order, so transactions locking the same accounts
+// cannot deadlock. The bigint advisory key space is reserved for account
+// IDs.
+//
+// Debit paths run at read committed: a writer that waited for the lock
+// then reads what the previous holder committed. Under serializable
+// isolation its snapshot would predate the wait and it would fail.
+func lockAccounts(ctx context.Context, db database.DBTX, accountIDs ...int64) error {
+	ids := slices.Clone(accountIDs)
+	slices.Sort(ids)
+	ids = slices.Compact(ids)
+
+	for _, id := range ids {
+		if _, err := db.ExecContext(ctx, `SELECT pg_advisory_xact_lock($1)`, id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+package transaction
+
+import (
+	"context"
+	"time"
+)
+
+// SettleDelay keeps checkpoints, and anything else frozen at the end of a
+// period, clear of transactions that started before midnight but had not
+// committed yet when the period closed.
+const SettleDelay = time.Hour
+
+// HistoricalBalance is the ledger balance of one currency at a past moment.
+// Holds are not kept historically, so there is no available balance.
+type HistoricalBalance struct {
+	Currency string    `json:"currency"`
+	Ledger   int64     `json:"ledger"`
+	AsOf     time.Time `json:"as_of"`
+}
+
+// BalanceAsOf returns one balance per currency the account holds, counting
+// every entry created at or before asOf.
+func (s *Service) BalanceAsOf(ctx context.Context, accountID int64, asOf time.Time) ([]HistoricalBalance, error) {
+	resp, err := s.activeAccount(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.balancesAt(ctx, accountID, resp.Currency, asOf.UTC())
+}
+
+func (s *Service) balancesAt(ctx context.Context, accountID int64, accountCurrency string, asOf time.Time) ([]HistoricalBalance, error) {
+	current, err := s.transactionRepo.BalanceByAccount(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(current) == 0 {
+		currency, _ := resolveCurrency("", accountCurrency)
+		current = []Balance{{Currency: currency}}
+	}
+
+	balances := make([]HistoricalBalance, 0, len(current))
+	for _, b := range current {
+		ledger, err := s.transactionRepo.BalanceAt(ctx, accountID, b.Currency, asOf)
+		if err != nil {
+			return nil, err
+		}
+		balances = append(balances, HistoricalBalance{Currency: b.Currency, Ledger: ledger, AsOf: asOf})
+	}
+
+	return balances, nil
+}
+
+// CreateCheckpoints records balances as of the most recent UTC midnight
+// that is at least SettleDelay old, for up to limit account currencies.
+func (s *Service) CreateCheckpoints(ctx context.Context, limit int) (int, error) {
+	at := time.Now().UTC().Add(-SettleDelay).Truncate(24 * time.Hour)
+	return s.transactionRepo.CreateCheckpoints(ctx, at, limit)
+}
@@ -0,0 +1,87 @@
+package transaction_test
+
+import (
+	"context"
+	"testing"
+	"time"
+	"transaction/internal/transaction"
+)
+
+func TestBalanceAsOf_MatchesWithAndWithoutCheckpoints(t *testing.T) {
+	db := setupTestDB(t)
+	ctx := context.Background()
+
+	txRepo := transaction.NewPostgresRepo(db)
+	service := transaction.NewTransactionService(db, &MockAccountClient{}, txRepo)
+
+	acc := createTestAccount(t, db, "Auditor")
+	before := time.Now().UTC().Add(-time.Second)
+
+	if err := service.Deposit(ctx, "", acc.ID, 10_000, "USD", "first", transaction.Labels{}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := service.Withdraw(ctx, "", acc.ID, 2_500, "USD", "second", transaction.Labels{}); err != nil {
+		t.Fatal(err)
+	}
+
+	checkpoint := time.Now().UTC().Add(time.Second)
+	if n, err := txRepo.CreateCheckpoints(ctx, checkpoint, 100); err != nil || n != 1 {
+		t.Fatalf("expected 1 checkpoint, got %d (%v)", n, err)
+	}
+
+	// a second run for the same moment adds nothing
+	if n, err := txRepo.CreateCheckpoints(ctx, checkpoint, 100); err != nil || n != 0 {
+		t.Fatalf("expected no new checkpoints, got %d (%v)", n, err)
+	}
+
+	for _, tt := range []struct {
+		asOf time.Time
+		want int64
+	}{
+		{before, 0},
+		{checkpoint.Add(-time.Microsecond), 7_500},
+		{checkpoint, 7_500},
+		{checkpoint.Add(time.Hour), 7_500},
+	} {
+		balances, err := service.BalanceAsOf(ctx, acc.ID, tt.asOf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(balances) != 1 || balances[0].Ledger != tt.want {
+			t.Fatalf("as of %v: expected %d, got %+v", tt.asOf, tt.want, balances)
+		}
+	}
+}
+
+func TestHistory_AsOf(t *testing.T) {
+	db := setupTestDB(t)
+	ctx := context.Background()
+
+	txRepo := transaction.NewPostgresRepo(db)
+	service := transaction.NewTransactionService(db, &MockAccountClient{}, txRepo)
+
+	acc := createTestAccount(t, db, "Disputer")
+	if err := service.Deposit(ctx, "", acc.ID, 5_000, "USD", "early", transaction.Labels{}); err != nil {
+		t.Fatal(err)
+	}
+
+	asOf := time.Now().UTC()
+	time.Sleep(10 * time.Millisecond)
+
+	if err := service.Deposit(ctx, "", acc.ID, 1_000, "USD", "late", transaction.Labels{}); err != nil {
+		t.Fatal(err)
+	}
+
+	page, err := service.History(ctx, acc.ID, transaction.HistoryFilter{AsOf: &asOf})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(page.Entries) != 1 || page.Entries[0].Note != "early" {
+		t.Fatalf("expected only the early entry, got %+v", page.Entries)
+	}
+
+	if len(page.Balances) != 1 || page.Balances[0].Ledger != 5_000 {
+		t.Fatalf("expected a balance of 5000 as of then, got %+v", page.Balances)
+	}
+}
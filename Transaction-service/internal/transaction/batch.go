@@ -0,0 +1,276 @@
+package transaction
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"transaction/pb"
+
+	"github.com/google/uuid"
+)
+
+// MaxBatchLegs caps how many transfers one batch may carry.
+const MaxBatchLegs = 1000
+
+var (
+	ErrEmptyBatch    = errors.New("batch has no transfers")
+	ErrBatchTooLarge = fmt.Errorf("batch has more than %d transfers", MaxBatchLegs)
+)
+
+// TransferLeg is one transfer of a batch.
+type TransferLeg struct {
+	FromAccountID int64  `json:"from_account_id"`
+	ToAccountID   int64  `json:"to_account_id"`
+	Amount        int64  `json:"amount"`
+	Currency      string `json:"currency"`
+	Note          string `json:"note"`
+	Labels
+}
+
+// BatchResult lists the journal posted for each leg, in request order, and
+// the fees charged across the batch.
+type BatchResult struct {
+	BatchID    uuid.UUID `json:"batch_id"`
+	JournalIDs []int64   `json:"journal_ids"`
+	Fees       []Fee     `json:"fees"`
+}
+
+// LegError names the leg of a batch that was rejected.
+type LegError struct {
+	Index int
+	Err   error
+}
+
+func (e *LegError) Error() string {
+	return fmt.Sprintf("leg %d: %v", e.Index, e.Err)
+}
+
+func (e *LegError) Unwrap() error {
+	return e.Err
+}
+
+// BatchTransfer posts every leg or none of them. Funds are checked once per
+// debited account against the sum of its legs; money a batch pays into an
+// account cannot fund that account's own debits in the same batch. Every
+// leg is charged transfer fees as if it were a transfer of its own, and the
+// sender's funds must cover them too.
+//
+// A retry with the same idempotency key returns the original batch's
+// result; a key reused for different legs returns ErrIdempotencyKeyReused.
+func (s *Service) BatchTransfer(ctx context.Context, idempotencyKey string, legs []TransferLeg) (*BatchResult, error) {
+	if len(legs) == 0 {
+		return nil, ErrEmptyBatch
+	}
+
+	if len(legs) > MaxBatchLegs {
+		return nil, ErrBatchTooLarge
+	}
+
+	// validate every leg before touching the ledger; each account is
+	// looked up once however many legs it appears in
+	accounts := map[int64]*pb.GetAccountResponse{}
+	currencyOf := func(accountID int64) (string, error) {
+		if resp, ok := accounts[accountID]; ok {
+			return resp.Currency, nil
+		}
+
+		resp, err := s.activeAccount(ctx, accountID)
+		if err != nil {
+			return "", err
+		}
+
+		accounts[accountID] = resp
+		return resp.Currency, nil
+	}
+
+	type debitKey struct {
+		accountID int64
+		currency  string
+	}
+	debits := map[debitKey]int64{}
+	legAmounts := map[debitKey][]int64{}
+
+	for i := range legs {
+		leg := &legs[i]
+
+		if leg.Amount <= 0 {
+			return nil, &LegError{Index: i, Err: ErrInvalidAmount}
+		}
+
+		if leg.FromAccountID == leg.ToAccountID {
+			return nil, &LegError{Index: i, Err: ErrSameAccount}
+		}
+
+		labels, err := leg.Labels.normalize()
+		if err != nil {
+			return nil, &LegError{Index: i, Err: err}
+		}
+		leg.Labels = labels
+
+		fromCurrency, err := currencyOf(leg.FromAccountID)
+		if err != nil {
+			return nil, &LegError{Index: i, Err: err}
+		}
+
+		toCurrency, err := currencyOf(leg.ToAccountID)
+		if err != nil {
+			return nil, &LegError{Index: i, Err: err}
+		}
+
+		leg.Currency, err = resolveCurrency(leg.Currency, fromCurrency)
+		if err != nil {
+			return nil, &LegError{Index: i, Err: err}
+		}
+
+		if _, err := resolveCurrency(leg.Currency, toCurrency); err != nil {
+			return nil, &LegError{Index: i, Err: err}
+		}
+
+		k := debitKey{leg.FromAccountID, leg.Currency}
+		debits[k] += leg.Amount
+		legAmounts[k] = append(legAmounts[k], leg.Amount)
+	}
+
+	// every account of the batch, locked for its whole transaction
+	accountIDs := make([]int64, 0, len(accounts))
+	for id := range accounts {
+		accountIDs = append(accountIDs, id)
+	}
+
+	var result *BatchResult
+	err := s.runner.Run(ctx, "batch_transfer", nil, func(tx *sql.Tx) error {
+		result = nil // a retried attempt starts over
+
+		transactionRepo := NewPostgresRepo(tx)
+		journalRepo := NewPostgresJournalRepo(tx)
+		idemRepo := NewPostgresIdempotencyRepoWithRetention(tx, s.retention)
+		outboxRepo := NewPostgresOutboxRepository(tx)
+
+		if err := lockAccounts(ctx, tx, accountIDs...); err != nil {
+			return err
+		}
+
+		if idempotencyKey != "" {
+			hash, err := payloadHash(legs)
+			if err != nil {
+				return err
+			}
+
+			claimed, stored, err := claimKey(ctx, idemRepo, idempotencyKey, "batch_transfer", hash)
+			if err != nil {
+				return err
+			}
+
+			if !claimed {
+				// already proccesed; answer with the original batch
+				return replayResult(stored, &result)
+			}
+		}
+
+		// fees are quoted inside the tx, so they match the rules it sees
+		legFees := make([][]Fee, len(legs))
+		funds := make(map[debitKey]int64, len(debits))
+		for k, amount := range debits {
+			funds[k] = amount
+		}
+		for i, leg := range legs {
+			fees, err := quoteFees(ctx, tx, FeeOnTransfer, accounts[leg.FromAccountID], leg.Currency, leg.Amount)
+			if err != nil {
+				return &LegError{Index: i, Err: err}
+			}
+			legFees[i] = fees
+			funds[debitKey{leg.FromAccountID, leg.Currency}] += totalFees(fees)
+		}
+
+		// check funds in a stable order so errors name the same account
+		// every time
+		keys := make([]debitKey, 0, len(debits))
+		for k := range debits {
+			keys = append(keys, k)
+		}
+		sort.Slice(keys, func(i, j int) bool {
+			if keys[i].accountID != keys[j].accountID {
+				return keys[i].accountID < keys[j].accountID
+			}
+			return keys[i].currency < keys[j].currency
+		})
+
+		for _, k := range keys {
+			err := s.checkFunds(ctx, tx, accounts[k.accountID], k.accountID, k.currency, funds[k])
+			if errors.Is(err, ErrInsufficientFunds) {
+				return &AccountError{AccountID: k.accountID, Err: err}
+			}
+
+			if err != nil {
+				return err
+			}
+
+			// every leg counts against the limits as a debit of its own
+			err = s.checkLimits(ctx, tx, accounts[k.accountID], k.currency, legAmounts[k]...)
+			if errors.Is(err, ErrLimitExceeded) {
+				return &AccountError{AccountID: k.accountID, Err: err}
+			}
+
+			if err != nil {
+				return err
+			}
+		}
+
+		result = &BatchResult{BatchID: uuid.New(), Fees: []Fee{}}
+
+		for i, leg := range legs {
+			journal := transferJournal(leg.FromAccountID, leg.ToAccountID, leg.Amount, leg.Currency, leg.Note)
+			journal.BatchID = &result.BatchID
+
+			debit, err := postTransfer(ctx, journalRepo, transactionRepo, journal, leg.FromAccountID, leg.ToAccountID, leg.Amount, leg.Note, leg.Labels)
+			if err != nil {
+				return &LegError{Index: i, Err: err}
+			}
+
+			if err := postFees(ctx, tx, debit, legFees[i]); err != nil {
+				return &LegError{Index: i, Err: err}
+			}
+			result.Fees = append(result.Fees, legFees[i]...)
+
+			fields := map[string]interface{}{
+				"account_id":    leg.FromAccountID,
+				"to_account_id": leg.ToAccountID,
+				"amount":        leg.Amount,
+				"currency":      leg.Currency,
+				"type":          "transfer",
+				"note":          leg.Note,
+				"batch_id":      result.BatchID,
+				"leg":           i,
+				"journal_id":    journal.ID,
+			}
+			leg.Labels.addTo(fields)
+
+			payload, err := json.Marshal(fields)
+			if err != nil {
+				return err
+			}
+
+			if err := outboxRepo.Add(ctx, &OutboxEvent{
+				ID:            uuid.New(),
+				AggregateType: "account",
+				AggregateID:   leg.FromAccountID,
+				EventType:     "transaction.created",
+				Payload:       payload,
+			}); err != nil {
+				return err
+			}
+
+			result.JournalIDs = append(result.JournalIDs, journal.ID)
+		}
+
+		return saveResult(ctx, idemRepo, idempotencyKey, result)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
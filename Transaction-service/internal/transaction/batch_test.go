@@ -0,0 +1,87 @@
+package transaction_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"transaction/internal/transaction"
+)
+
+func TestBatchTransfer_PostsAllLegs(t *testing.T) {
+	db := setupTestDB(t)
+	ctx := context.Background()
+
+	txRepo := transaction.NewPostgresRepo(db)
+	service := transaction.NewTransactionService(db, &MockAccountClient{}, txRepo)
+
+	employer := createTestAccount(t, db, "Employer")
+	alice := createTestAccount(t, db, "Alice")
+	bob := createTestAccount(t, db, "Bob")
+
+	if err := service.Deposit(ctx, "", employer.ID, 10_000, "USD", "payroll funding", transaction.Labels{}); err != nil {
+		t.Fatalf("seed deposit failed: %v", err)
+	}
+
+	result, err := service.BatchTransfer(ctx, "", []transaction.TransferLeg{
+		{FromAccountID: employer.ID, ToAccountID: alice.ID, Amount: 6_000, Note: "salary"},
+		{FromAccountID: employer.ID, ToAccountID: bob.ID, Amount: 4_000, Note: "salary"},
+	})
+	if err != nil {
+		t.Fatalf("batch failed: %v", err)
+	}
+
+	if len(result.JournalIDs) != 2 {
+		t.Fatalf("expected 2 journals, got %d", len(result.JournalIDs))
+	}
+
+	assertBalance(t, service, employer.ID, 0)
+	assertBalance(t, service, alice.ID, 6_000)
+	assertBalance(t, service, bob.ID, 4_000)
+
+	var journals, events int
+	db.QueryRow(`SELECT COUNT(*) FROM journal_entries WHERE batch_id = $1`, result.BatchID).Scan(&journals)
+	db.QueryRow(`SELECT COUNT(*) FROM outbox_events WHERE payload->>'batch_id' = $1`, result.BatchID.String()).Scan(&events)
+
+	if journals != 2 || events != 2 {
+		t.Fatalf("expected 2 journals and 2 events for the batch, got %d and %d", journals, events)
+	}
+}
+
+func TestBatchTransfer_AllOrNothing(t *testing.T) {
+	db := setupTestDB(t)
+	ctx := context.Background()
+
+	txRepo := transaction.NewPostgresRepo(db)
+	service := transaction.NewTransactionService(db, &MockAccountClient{}, txRepo)
+
+	employer := createTestAccount(t, db, "Employer")
+	alice := createTestAccount(t, db, "Alice")
+	bob := createTestAccount(t, db, "Bob")
+
+	if err := service.Deposit(ctx, "", employer.ID, 10_000, "USD", "payroll funding", transaction.Labels{}); err != nil {
+		t.Fatalf("seed deposit failed: %v", err)
+	}
+
+	// each leg is affordable on its own, together they are not
+	_, err := service.BatchTransfer(ctx, "", []transaction.TransferLeg{
+		{FromAccountID: employer.ID, ToAccountID: alice.ID, Amount: 6_000},
+		{FromAccountID: employer.ID, ToAccountID: bob.ID, Amount: 6_000},
+	})
+	if !errors.Is(err, transaction.ErrInsufficientFunds) {
+		t.Fatalf("expected ErrInsufficientFunds, got %v", err)
+	}
+
+	// an invalid leg names its position
+	_, err = service.BatchTransfer(ctx, "", []transaction.TransferLeg{
+		{FromAccountID: employer.ID, ToAccountID: alice.ID, Amount: 1_000},
+		{FromAccountID: bob.ID, ToAccountID: bob.ID, Amount: 1_000},
+	})
+	var legErr *transaction.LegError
+	if !errors.As(err, &legErr) || legErr.Index != 1 || !errors.Is(err, transaction.ErrSameAccount) {
+		t.Fatalf("expected leg 1 to be rejected as a same-account transfer, got %v", err)
+	}
+
+	assertBalance(t, service, employer.ID, 10_000)
+	assertBalance(t, service, alice.ID, 0)
+	assertBalance(t, service, bob.ID, 0)
+}
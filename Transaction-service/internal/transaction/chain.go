@@ -0,0 +1,320 @@
+package transaction
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"sort"
+	"time"
+)
+
+// Every ledger entry is chained to the previous entry of its account: its
+// hash covers its own content and the previous entry's hash, so editing or
+// deleting a row breaks every hash after it. Chain heads are signed
+// periodically, which also catches entries removed from the end.
+
+var ErrNoChainSigner = errors.New("no chain signing key configured")
+
+// chainVerifyBatch is how many entries verification reads at a time.
+const chainVerifyBatch = 1000
+
+// ChainHead is the last entry of an account's chain.
+type ChainHead struct {
+	AccountID         int64     `json:"account_id"`
+	LastTransactionID int64     `json:"last_transaction_id"`
+	Entries           int64     `json:"entries"`
+	Hash              []byte    `json:"-"`
+	UpdatedAt         time.Time `json:"updated_at"`
+}
+
+// ChainCheckpoint is a signed statement that an account's chain had
+// Entries entries ending in Hash at CreatedAt.
+type ChainCheckpoint struct {
+	ID            int64     `json:"id"`
+	AccountID     int64     `json:"account_id"`
+	TransactionID int64     `json:"transaction_id"`
+	Entries       int64     `json:"entries"`
+	Hash          []byte    `json:"hash"`
+	KeyID         string    `json:"key_id"`
+	Signature     []byte    `json:"signature"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// ChainBreak is the first place an account's chain does not hold.
+type ChainBreak struct {
+	Seq           int64  `json:"seq"`
+	TransactionID int64  `json:"transaction_id,omitempty"`
+	Reason        string `json:"reason"`
+}
+
+// ChainVerification is the result of walking an account's chain.
+type ChainVerification struct {
+	AccountID   int64       `json:"account_id"`
+	Entries     int64       `json:"entries"`
+	Head        string      `json:"head,omitempty"` // hex
+	Checkpoints int         `json:"checkpoints"`    // signed checkpoints matched
+	Valid       bool        `json:"valid"`
+	Break       *ChainBreak `json:"break,omitempty"`
+}
+
+// chainHash returns the hash of entry t following prev. Fields are written
+// length-prefixed in a fixed order, so no two entries encode the same.
+func chainHash(prev []byte, t *Transaction) []byte {
+	h := sha256.New()
+	w := chainWriter{h}
+
+	w.bytes([]byte("ledger-chain-v1"))
+	w.bytes(prev)
+	w.int(t.ID)
+	w.int(t.AccountID)
+	w.int(t.ChainSeq)
+	w.int(t.JournalID)
+	w.bytes([]byte(t.Type))
+	w.int(t.Amount)
+	w.bytes([]byte(t.Currency))
+	w.int(t.BalanceAfter)
+	w.int(t.ReversalOf)
+	w.int(t.CounterpartyID)
+	w.int(t.FeeFor)
+	w.bytes([]byte(t.Note))
+
+	keys := make([]string, 0, len(t.Metadata))
+	for k := range t.Metadata {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	w.int(int64(len(keys)))
+	for _, k := range keys {
+		w.bytes([]byte(k))
+		w.bytes([]byte(t.Metadata[k]))
+	}
+
+	w.int(int64(len(t.Tags)))
+	for _, tag := range t.Tags {
+		w.bytes([]byte(tag))
+	}
+
+	w.int(t.CreatedAt.UnixMicro())
+
+	return h.Sum(nil)
+}
+
+type chainWriter struct {
+	h hash.Hash
+}
+
+func (w chainWriter) int(v int64) {
+	var buf [8]byte
+	binary.BigEndian.PutUint64(buf[:], uint64(v))
+	w.h.Write(buf[:])
+}
+
+func (w chainWriter) bytes(b []byte) {
+	w.int(int64(len(b)))
+	w.h.Write(b)
+}
+
+// VerifyChain walks the account's chain from its first entry and reports
+// the first entry that was edited, removed or written outside the chain,
+// and any signed checkpoint the chain no longer matches. Checkpoint
+// signatures are checked when a signing key is configured. An account whose
+// entries were never sealed into a chain is reported as broken too.
+func (s *Service) VerifyChain(ctx context.Context, accountID int64) (*ChainVerification, error) {
+	// one snapshot for the whole walk, so entries posted meanwhile cannot
+	// make a busy account look tampered with
+	tx, err := s.db.BeginTx(ctx, &sql.TxOptions{
+		Isolation: sql.LevelRepeatableRead,
+		ReadOnly:  true,
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	return s.verifyChain(ctx, NewPostgresChainRepo(tx), accountID)
+}
+
+func (s *Service) verifyChain(ctx context.Context, chainRepo ChainRepository, accountID int64) (*ChainVerification, error) {
+	result := &ChainVerification{AccountID: accountID}
+
+	head, err := chainRepo.Head(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	checkpoints, err := chainRepo.Checkpoints(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	// checkpoints by the chain length they were taken at
+	bySeq := map[int64]*ChainCheckpoint{}
+	for i := range checkpoints {
+		c := &checkpoints[i]
+		if s.chainSigner != nil && !s.chainSigner.Verify(c) {
+			result.Break = &ChainBreak{Seq: c.Entries, TransactionID: c.TransactionID, Reason: fmt.Sprintf("checkpoint %d has an invalid signature", c.ID)}
+			return result, nil
+		}
+		bySeq[c.Entries] = c
+	}
+
+	var (
+		prev   []byte
+		lastID int64
+	)
+	for {
+		entries, err := chainRepo.Entries(ctx, accountID, result.Entries, chainVerifyBatch)
+		if err != nil {
+			return nil, err
+		}
+
+		for i := range entries {
+			t := &entries[i]
+			seq := result.Entries + 1
+
+			if brk := checkEntry(t, seq, prev); brk != nil {
+				result.Break = brk
+				return result, nil
+			}
+
+			if c, ok := bySeq[seq]; ok {
+				if c.TransactionID != t.ID || !bytes.Equal(c.Hash, t.Hash) {
+					result.Break = &ChainBreak{Seq: seq, TransactionID: t.ID, Reason: fmt.Sprintf("entry does not match checkpoint %d", c.ID)}
+					return result, nil
+				}
+				result.Checkpoints++
+			}
+
+			prev = t.Hash
+			lastID = t.ID
+			result.Entries = seq
+		}
+
+		if len(entries) < chainVerifyBatch {
+			break
+		}
+	}
+
+	result.Head = hex.EncodeToString(prev)
+
+	for seq, c := range bySeq {
+		if seq > result.Entries {
+			result.Break = &ChainBreak{Seq: seq, TransactionID: c.TransactionID, Reason: fmt.Sprintf("entries signed in checkpoint %d are missing", c.ID)}
+			return result, nil
+		}
+	}
+
+	if head.Entries != result.Entries || head.LastTransactionID != lastID || !bytes.Equal(head.Hash, prev) {
+		result.Break = &ChainBreak{Seq: head.Entries, TransactionID: head.LastTransactionID, Reason: "chain head does not match the last entry"}
+		return result, nil
+	}
+
+	// every entry must be in the chain; an account without one has entries
+	// from before chaining that were never sealed
+	id, err := chainRepo.Unchained(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+	if id != 0 {
+		reason := "entry is not in the chain"
+		if head.Entries == 0 {
+			reason = "entries are not sealed into a chain yet"
+		}
+		result.Break = &ChainBreak{TransactionID: id, Reason: reason}
+		return result, nil
+	}
+
+	result.Valid = true
+	return result, nil
+}
+
+func checkEntry(t *Transaction, seq int64, prev []byte) *ChainBreak {
+	switch {
+	case t.ChainSeq != seq:
+		return &ChainBreak{Seq: seq, TransactionID: t.ID, Reason: fmt.Sprintf("entry %d is missing", seq)}
+	case !bytes.Equal(t.PrevHash, prev):
+		return &ChainBreak{Seq: seq, TransactionID: t.ID, Reason: "previous hash does not match"}
+	case !bytes.Equal(t.Hash, chainHash(prev, t)):
+		return &ChainBreak{Seq: seq, TransactionID: t.ID, Reason: "entry content does not match its hash"}
+	}
+	return nil
+}
+
+// UseChainSigner sets the key chain checkpoints are signed and verified
+// with.
+func (s *Service) UseChainSigner(signer *ChainSigner) {
+	s.chainSigner = signer
+}
+
+// CreateChainCheckpoints signs the head of up to limit chains that have
+// grown since their last checkpoint, and returns how many it signed.
+func (s *Service) CreateChainCheckpoints(ctx context.Context, limit int) (int, error) {
+	if s.chainSigner == nil {
+		return 0, ErrNoChainSigner
+	}
+
+	heads, err := s.chainRepo.DueForCheckpoint(ctx, limit)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, head := range heads {
+		c := &ChainCheckpoint{
+			AccountID:     head.AccountID,
+			TransactionID: head.LastTransactionID,
+			Entries:       head.Entries,
+			Hash:          head.Hash,
+			CreatedAt:     time.Now().UTC().Truncate(time.Microsecond),
+		}
+		s.chainSigner.Sign(c)
+
+		if err := s.chainRepo.AddCheckpoint(ctx, c); err != nil {
+			return 0, err
+		}
+	}
+
+	return len(heads), nil
+}
+
+// ChainCheckpoints lists the signed checkpoints of an account, oldest first.
+func (s *Service) ChainCheckpoints(ctx context.Context, accountID int64) ([]ChainCheckpoint, error) {
+	return s.chainRepo.Checkpoints(ctx, accountID)
+}
+
+// SealLegacyChains chains the entries of up to limit accounts written
+// before entries were hashed, and returns how many accounts it sealed.
+// Accounts are otherwise sealed by their next entry.
+func (s *Service) SealLegacyChains(ctx context.Context, limit int) (int, error) {
+	accounts, err := s.chainRepo.Unsealed(ctx, limit)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, accountID := range accounts {
+		if err := s.sealChain(ctx, accountID); err != nil {
+			return 0, fmt.Errorf("account %d: %w", accountID, err)
+		}
+	}
+
+	return len(accounts), nil
+}
+
+func (s *Service) sealChain(ctx context.Context, accountID int64) error {
+	return s.runner.Run(ctx, "seal_chain", nil, func(tx *sql.Tx) error {
+		head, err := lockChainHead(ctx, tx, accountID)
+		if err != nil {
+			return err
+		}
+
+		if head.Entries == 0 {
+			return sealLegacy(ctx, tx, head)
+		}
+		return nil
+	})
+}
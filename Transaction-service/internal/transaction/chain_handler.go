@@ -0,0 +1,65 @@
+package transaction
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi"
+)
+
+// VerifyChain walks the account's hash chain; a broken chain is reported
+// in the body, not as an error status.
+func (h *TransactionHandler) VerifyChain(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "INVALID_ACCOUNT_ID", "Account ID must be a number")
+		return
+	}
+
+	result, err := h.service.VerifyChain(r.Context(), id)
+	if err != nil {
+		respondServiceError(w, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, SuccessResponse{
+		Status:  "success",
+		Message: "Chain verified",
+		Data:    result,
+	})
+}
+
+func (h *TransactionHandler) ChainCheckpoints(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "INVALID_ACCOUNT_ID", "Account ID must be a number")
+		return
+	}
+
+	checkpoints, err := h.service.ChainCheckpoints(r.Context(), id)
+	if err != nil {
+		respondServiceError(w, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, SuccessResponse{
+		Status:  "success",
+		Message: "Chain checkpoints retrieved successfully",
+		Data:    checkpoints,
+	})
+}
+
+// ChainKey publishes the public key auditors verify checkpoints with.
+func (h *TransactionHandler) ChainKey(w http.ResponseWriter, r *http.Request) {
+	key, err := h.service.ChainKey()
+	if err != nil {
+		respondServiceError(w, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, SuccessResponse{
+		Status:  "success",
+		Message: "Chain signing key retrieved successfully",
+		Data:    key,
+	})
+}
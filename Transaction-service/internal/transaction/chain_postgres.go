@@ -0,0 +1,268 @@
+package transaction
+
+import (
+	"context"
+	"database/sql"
+	"transaction/internal/infrastructure/database"
+)
+
+var _ ChainRepository = (*PostgresChainRepo)(nil)
+
+type PostgresChainRepo struct {
+	db database.DBTX
+}
+
+func NewPostgresChainRepo(db database.DBTX) *PostgresChainRepo {
+	return &PostgresChainRepo{db: db}
+}
+
+// lockChainHead returns the account's chain head, creating it if needed,
+// and holds its row lock until the transaction ends, so entries join the
+// chain one at a time.
+func lockChainHead(ctx context.Context, db database.DBTX, accountID int64) (*ChainHead, error) {
+	head := &ChainHead{AccountID: accountID}
+	err := db.QueryRowContext(ctx, `
+		INSERT INTO ledger_chain_heads (account_id)
+		VALUES ($1)
+		ON CONFLICT (account_id) DO UPDATE SET account_id = EXCLUDED.account_id
+		RETURNING COALESCE(last_transaction_id, 0), entries, hash, updated_at
+	`, accountID).Scan(&head.LastTransactionID, &head.Entries, &head.Hash, &head.UpdatedAt)
+	return head, err
+}
+
+// appendToChain hashes the stored entry t onto the locked head and saves
+// both.
+func appendToChain(ctx context.Context, db database.DBTX, head *ChainHead, t *Transaction) error {
+	if err := chainEntry(ctx, db, head, t); err != nil {
+		return err
+	}
+	return saveChainHead(ctx, db, head)
+}
+
+func chainEntry(ctx context.Context, db database.DBTX, head *ChainHead, t *Transaction) error {
+	t.ChainSeq = head.Entries + 1
+	t.PrevHash = head.Hash
+	t.Hash = chainHash(t.PrevHash, t)
+
+	if _, err := db.ExecContext(ctx, `
+		UPDATE transactions
+		SET chain_seq = $2, prev_hash = $3, hash = $4
+		WHERE id = $1
+	`, t.ID, t.ChainSeq, t.PrevHash, t.Hash); err != nil {
+		return err
+	}
+
+	head.LastTransactionID = t.ID
+	head.Entries = t.ChainSeq
+	head.Hash = t.Hash
+	return nil
+}
+
+func saveChainHead(ctx context.Context, db database.DBTX, head *ChainHead) error {
+	_, err := db.ExecContext(ctx, `
+		UPDATE ledger_chain_heads
+		SET last_transaction_id = $2, entries = $3, hash = $4, updated_at = now()
+		WHERE account_id = $1
+	`, head.AccountID, nullID(head.LastTransactionID), head.Entries, head.Hash)
+	return err
+}
+
+// sealLegacy chains, in ID order, the entries of the locked head's account
+// that were written before entries were hashed.
+func sealLegacy(ctx context.Context, db database.DBTX, head *ChainHead) error {
+	rows, err := db.QueryContext(ctx, `
+		SELECT `+transactionColumns+`
+		FROM transactions
+		WHERE account_id = $1 AND chain_seq IS NULL
+		ORDER BY id
+	`, head.AccountID)
+	if err != nil {
+		return err
+	}
+
+	var entries []Transaction
+	for rows.Next() {
+		t, err := scanTransaction(rows)
+		if err != nil {
+			rows.Close()
+			return err
+		}
+		entries = append(entries, t)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	if len(entries) == 0 {
+		return nil
+	}
+
+	for i := range entries {
+		if err := chainEntry(ctx, db, head, &entries[i]); err != nil {
+			return err
+		}
+	}
+	return saveChainHead(ctx, db, head)
+}
+
+func (r *PostgresChainRepo) Head(ctx context.Context, accountID int64) (*ChainHead, error) {
+	head := &ChainHead{AccountID: accountID}
+	err := r.db.QueryRowContext(ctx, `
+		SELECT COALESCE(last_transaction_id, 0), entries, hash, updated_at
+		FROM ledger_chain_heads
+		WHERE account_id = $1
+	`, accountID).Scan(&head.LastTransactionID, &head.Entries, &head.Hash, &head.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return head, nil
+	}
+	return head, err
+}
+
+// chainScanner reads the chain columns after transactionColumns.
+type chainScanner struct {
+	row rowScanner
+	t   *Transaction
+}
+
+func (s chainScanner) Scan(dest ...any) error {
+	return s.row.Scan(append(dest, &s.t.ChainSeq, &s.t.PrevHash, &s.t.Hash)...)
+}
+
+func (r *PostgresChainRepo) Entries(ctx context.Context, accountID int64, afterSeq int64, limit int) ([]Transaction, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+transactionColumns+`, chain_seq, prev_hash, hash
+		FROM transactions
+		WHERE account_id = $1 AND chain_seq > $2
+		ORDER BY chain_seq
+		LIMIT $3
+	`, accountID, afterSeq, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []Transaction
+	for rows.Next() {
+		var chain Transaction
+		t, err := scanTransaction(chainScanner{rows, &chain})
+		if err != nil {
+			return nil, err
+		}
+		t.ChainSeq, t.PrevHash, t.Hash = chain.ChainSeq, chain.PrevHash, chain.Hash
+		entries = append(entries, t)
+	}
+
+	return entries, rows.Err()
+}
+
+func (r *PostgresChainRepo) Unchained(ctx context.Context, accountID int64) (int64, error) {
+	var id int64
+	err := r.db.QueryRowContext(ctx, `
+		SELECT id
+		FROM transactions
+		WHERE account_id = $1 AND (chain_seq IS NULL OR hash IS NULL)
+		ORDER BY id
+		LIMIT 1
+	`, accountID).Scan(&id)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	return id, err
+}
+
+func (r *PostgresChainRepo) Unsealed(ctx context.Context, limit int) ([]int64, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT DISTINCT t.account_id
+		FROM transactions t
+		WHERE t.chain_seq IS NULL
+		  AND NOT EXISTS (
+			SELECT 1 FROM ledger_chain_heads h
+			WHERE h.account_id = t.account_id AND h.entries > 0
+		  )
+		ORDER BY t.account_id
+		LIMIT $1
+	`, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var accounts []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		accounts = append(accounts, id)
+	}
+	return accounts, rows.Err()
+}
+
+const chainCheckpointColumns = `id, account_id, transaction_id, entries, hash, key_id, signature, created_at`
+
+func (r *PostgresChainRepo) Checkpoints(ctx context.Context, accountID int64) ([]ChainCheckpoint, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+chainCheckpointColumns+`
+		FROM ledger_chain_checkpoints
+		WHERE account_id = $1
+		ORDER BY entries, id
+	`, accountID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var checkpoints []ChainCheckpoint
+	for rows.Next() {
+		var c ChainCheckpoint
+		if err := rows.Scan(&c.ID, &c.AccountID, &c.TransactionID, &c.Entries, &c.Hash, &c.KeyID, &c.Signature, &c.CreatedAt); err != nil {
+			return nil, err
+		}
+		checkpoints = append(checkpoints, c)
+	}
+	return checkpoints, rows.Err()
+}
+
+func (r *PostgresChainRepo) DueForCheckpoint(ctx context.Context, limit int) ([]ChainHead, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT h.account_id, h.last_transaction_id, h.entries, h.hash, h.updated_at
+		FROM ledger_chain_heads h
+		WHERE h.entries > 0
+		  AND NOT EXISTS (
+			SELECT 1 FROM ledger_chain_checkpoints c
+			WHERE c.account_id = h.account_id AND c.entries = h.entries
+		  )
+		ORDER BY h.account_id
+		LIMIT $1
+	`, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var heads []ChainHead
+	for rows.Next() {
+		var h ChainHead
+		if err := rows.Scan(&h.AccountID, &h.LastTransactionID, &h.Entries, &h.Hash, &h.UpdatedAt); err != nil {
+			return nil, err
+		}
+		heads = append(heads, h)
+	}
+	return heads, rows.Err()
+}
+
+// AddCheckpoint stores c unless the head was already signed at the same
+// length, e.g. by another instance.
+func (r *PostgresChainRepo) AddCheckpoint(ctx context.Context, c *ChainCheckpoint) error {
+	err := r.db.QueryRowContext(ctx, `
+		INSERT INTO ledger_chain_checkpoints (account_id, transaction_id, entries, hash, key_id, signature, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (account_id, entries) DO NOTHING
+		RETURNING id
+	`, c.AccountID, c.TransactionID, c.Entries, c.Hash, c.KeyID, c.Signature, c.CreatedAt).Scan(&c.ID)
+	if err == sql.ErrNoRows {
+		return nil
+	}
+	return err
+}
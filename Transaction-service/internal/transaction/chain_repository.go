@@ -0,0 +1,22 @@
+package transaction
+
+import "context"
+
+type ChainRepository interface {
+	// Head returns the account's chain head, empty if it has none.
+	Head(ctx context.Context, accountID int64) (*ChainHead, error)
+	// Entries returns up to limit chained entries of the account after
+	// position afterSeq, in chain order.
+	Entries(ctx context.Context, accountID int64, afterSeq int64, limit int) ([]Transaction, error)
+	// Unchained returns the ID of an entry of the account outside the
+	// chain, 0 if there is none.
+	Unchained(ctx context.Context, accountID int64) (int64, error)
+	// Unsealed returns up to limit accounts with entries but no chain.
+	Unsealed(ctx context.Context, limit int) ([]int64, error)
+	Checkpoints(ctx context.Context, accountID int64) ([]ChainCheckpoint, error)
+	// DueForCheckpoint returns up to limit heads that have moved since
+	// their last checkpoint.
+	DueForCheckpoint(ctx context.Context, limit int) ([]ChainHead, error)
+	// AddCheckpoint stores c; a checkpoint of the same length is kept.
+	AddCheckpoint(ctx context.Context, c *ChainCheckpoint) error
+}
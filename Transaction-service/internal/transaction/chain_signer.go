@@ -0,0 +1,76 @@
+package transaction
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+)
+
+// ChainSigner signs chain checkpoints with an Ed25519 key. Auditors verify
+// checkpoints with the public key alone.
+type ChainSigner struct {
+	key   ed25519.PrivateKey
+	keyID string
+}
+
+// NewChainSigner builds a signer from a 32-byte Ed25519 seed.
+func NewChainSigner(seed []byte) (*ChainSigner, error) {
+	if len(seed) != ed25519.SeedSize {
+		return nil, fmt.Errorf("chain signing key must be %d bytes, got %d", ed25519.SeedSize, len(seed))
+	}
+
+	key := ed25519.NewKeyFromSeed(seed)
+	sum := sha256.Sum256(key.Public().(ed25519.PublicKey))
+
+	return &ChainSigner{key: key, keyID: hex.EncodeToString(sum[:8])}, nil
+}
+
+// ParseChainSigner reads a base64 encoded seed, e.g. from
+// LEDGER_SIGNING_KEY.
+func ParseChainSigner(v string) (*ChainSigner, error) {
+	seed, err := base64.StdEncoding.DecodeString(v)
+	if err != nil {
+		return nil, fmt.Errorf("chain signing key must be base64: %w", err)
+	}
+	return NewChainSigner(seed)
+}
+
+func (s *ChainSigner) KeyID() string {
+	return s.keyID
+}
+
+func (s *ChainSigner) PublicKey() ed25519.PublicKey {
+	return s.key.Public().(ed25519.PublicKey)
+}
+
+func (s *ChainSigner) Sign(c *ChainCheckpoint) {
+	c.KeyID = s.keyID
+	c.Signature = ed25519.Sign(s.key, checkpointMessage(c))
+}
+
+// Verify reports whether c was signed with this key.
+func (s *ChainSigner) Verify(c *ChainCheckpoint) bool {
+	return c.KeyID == s.keyID && ed25519.Verify(s.PublicKey(), checkpointMessage(c), c.Signature)
+}
+
+// checkpointMessage is what a checkpoint signature covers.
+func checkpointMessage(c *ChainCheckpoint) []byte {
+	return []byte(fmt.Sprintf("ledger-chain-checkpoint-v1:%d:%d:%d:%x:%d",
+		c.AccountID, c.TransactionID, c.Entries, c.Hash, c.CreatedAt.UnixMicro()))
+}
+
+// ChainKey is the public half of the chain signing key.
+type ChainKey struct {
+	KeyID     string `json:"key_id"`
+	PublicKey []byte `json:"public_key"` // Ed25519, base64 in JSON
+}
+
+// ChainKey returns the key checkpoints are signed with.
+func (s *Service) ChainKey() (*ChainKey, error) {
+	if s.chainSigner == nil {
+		return nil, ErrNoChainSigner
+	}
+	return &ChainKey{KeyID: s.chainSigner.KeyID(), PublicKey: s.chainSigner.PublicKey()}, nil
+}
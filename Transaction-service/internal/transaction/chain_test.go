@@ -0,0 +1,189 @@
+package transaction_test
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+	"transaction/internal/transaction"
+)
+
+func testSigner(t *testing.T, b byte) *transaction.ChainSigner {
+	signer, err := transaction.NewChainSigner(bytes.Repeat([]byte{b}, 32))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signer
+}
+
+func TestChainSigner_SignAndVerify(t *testing.T) {
+	signer := testSigner(t, 1)
+
+	c := &transaction.ChainCheckpoint{AccountID: 1, TransactionID: 7, Entries: 3, Hash: []byte{1, 2, 3}, CreatedAt: time.Now()}
+	signer.Sign(c)
+
+	if !signer.Verify(c) {
+		t.Fatal("expected the checkpoint to verify")
+	}
+
+	if testSigner(t, 2).Verify(c) {
+		t.Fatal("expected another key to reject the checkpoint")
+	}
+
+	c.Entries = 2
+	if signer.Verify(c) {
+		t.Fatal("expected an edited checkpoint to be rejected")
+	}
+}
+
+func TestNewChainSigner_RejectsShortKey(t *testing.T) {
+	if _, err := transaction.NewChainSigner([]byte("short")); err == nil {
+		t.Fatal("expected an error")
+	}
+}
+
+func TestVerifyChain_ReportsEditedEntry(t *testing.T) {
+	db := setupTestDB(t)
+	ctx := context.Background()
+
+	txRepo := transaction.NewPostgresRepo(db)
+	service := transaction.NewTransactionService(db, &MockAccountClient{}, txRepo)
+	service.UseChainSigner(testSigner(t, 1))
+
+	from := createTestAccount(t, db, "From")
+	to := createTestAccount(t, db, "To")
+
+	if err := service.Deposit(ctx, "", from.ID, 10_000, "USD", "funding", transaction.Labels{Metadata: map[string]string{"ref": "a"}}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := service.Withdraw(ctx, "", from.ID, 1_000, "USD", "atm", transaction.Labels{Tags: []string{"cash"}}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := service.Transfer(ctx, "", from.ID, to.ID, 2_000, "USD", "rent", transaction.Labels{}); err != nil {
+		t.Fatal(err)
+	}
+
+	if n, err := service.CreateChainCheckpoints(ctx, 100); err != nil || n != 2 {
+		t.Fatalf("expected 2 checkpoints, got %d, %v", n, err)
+	}
+
+	result, err := service.VerifyChain(ctx, from.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.Valid || result.Entries != 3 || result.Checkpoints != 1 {
+		t.Fatalf("expected a valid chain of 3 entries, got %+v", result)
+	}
+
+	// rewrite the withdrawal behind the service's back
+	if _, err := db.Exec(`UPDATE transactions SET amount = 10 WHERE account_id = $1 AND type = 'WITHDRAW'`, from.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err = service.VerifyChain(ctx, from.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Valid || result.Break == nil || result.Break.Seq != 2 {
+		t.Fatalf("expected the chain to break at entry 2, got %+v", result)
+	}
+
+	// the other side of the transfer is untouched
+	result, err = service.VerifyChain(ctx, to.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.Valid || result.Entries != 1 {
+		t.Fatalf("expected a valid chain of 1 entry, got %+v", result)
+	}
+}
+
+// deleteEntry removes a ledger row the way someone bypassing the service
+// would, with foreign keys switched off.
+func deleteEntry(t *testing.T, db *sql.DB, accountID, seq int64) {
+	t.Helper()
+
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`SET LOCAL session_replication_role = replica`); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tx.Exec(`DELETE FROM transactions WHERE account_id = $1 AND chain_seq = $2`, accountID, seq); err != nil {
+		t.Fatal(err)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestVerifyChain_ReportsDeletedEntries(t *testing.T) {
+	db := setupTestDB(t)
+	ctx := context.Background()
+
+	txRepo := transaction.NewPostgresRepo(db)
+	service := transaction.NewTransactionService(db, &MockAccountClient{}, txRepo)
+
+	middle := createTestAccount(t, db, "Middle")
+	last := createTestAccount(t, db, "Last")
+
+	for _, acc := range []int64{middle.ID, last.ID} {
+		for i := 0; i < 3; i++ {
+			if err := service.Deposit(ctx, "", acc, 1_000, "USD", "funding", transaction.Labels{}); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	deleteEntry(t, db, middle.ID, 2)
+	deleteEntry(t, db, last.ID, 3)
+
+	result, err := service.VerifyChain(ctx, middle.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Valid || result.Break == nil || result.Break.Seq != 2 {
+		t.Fatalf("expected the chain to break at the deleted entry 2, got %+v", result)
+	}
+
+	// the chain itself still holds; only the head knows about entry 3
+	result, err = service.VerifyChain(ctx, last.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Valid || result.Break == nil || result.Break.Seq != 3 || result.Entries != 2 {
+		t.Fatalf("expected the head to expose the deleted last entry, got %+v", result)
+	}
+}
+
+func TestVerifyChain_ReportsUnsealedEntries(t *testing.T) {
+	db := setupTestDB(t)
+	ctx := context.Background()
+
+	txRepo := transaction.NewPostgresRepo(db)
+	service := transaction.NewTransactionService(db, &MockAccountClient{}, txRepo)
+
+	acc := createTestAccount(t, db, "Legacy")
+
+	// a row from before chaining, never sealed
+	var id int64
+	if err := db.QueryRow(`
+		INSERT INTO transactions (account_id, type, amount, currency, balance_after)
+		VALUES ($1, 'DEPOSIT', 500, 'USD', 500)
+		RETURNING id
+	`, acc.ID).Scan(&id); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := service.VerifyChain(ctx, acc.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Valid || result.Break == nil || result.Break.TransactionID != id {
+		t.Fatalf("expected the unsealed entry to be reported, got %+v", result)
+	}
+}
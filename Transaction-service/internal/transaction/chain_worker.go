@@ -0,0 +1,64 @@
+package transaction
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// ChainWorker chains accounts written before entries were hashed and, when
+// a signing key is configured, signs chain heads that have moved.
+type ChainWorker struct {
+	service   *Service
+	interval  time.Duration
+	batchSize int
+}
+
+func NewChainWorker(service *Service) *ChainWorker {
+	return &ChainWorker{
+		service:   service,
+		interval:  time.Hour,
+		batchSize: 100,
+	}
+}
+
+func (w *ChainWorker) Start(ctx context.Context) {
+	log.Println("🚀 Ledger chain worker started")
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Println("🛑 Ledger chain worker stopped")
+			return
+
+		case <-ticker.C:
+			w.drain(ctx, "Sealed %d legacy ledger chains", w.service.SealLegacyChains)
+
+			if w.service.chainSigner != nil {
+				w.drain(ctx, "Signed %d ledger chain checkpoints", w.service.CreateChainCheckpoints)
+			}
+		}
+	}
+}
+
+// drain runs step until it has nothing left to do.
+func (w *ChainWorker) drain(ctx context.Context, done string, step func(context.Context, int) (int, error)) {
+	for {
+		n, err := step(ctx, w.batchSize)
+		if err != nil {
+			log.Println("❌ Ledger chain step failed:", err)
+			return
+		}
+
+		if n > 0 {
+			log.Printf("🔗 "+done, n)
+		}
+
+		if n < w.batchSize {
+			return
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package transaction
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// CheckpointWorker writes the daily balance checkpoints point-in-time
+// balance queries start from.
+type CheckpointWorker struct {
+	service   *Service
+	interval  time.Duration
+	batchSize int
+}
+
+func NewCheckpointWorker(service *Service) *CheckpointWorker {
+	return &CheckpointWorker{
+		service:   service,
+		interval:  time.Hour,
+		batchSize: 500,
+	}
+}
+
+func (w *CheckpointWorker) Start(ctx context.Context) {
+	log.Println("🚀 Balance checkpoint worker started")
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Println("🛑 Balance checkpoint worker stopped")
+			return
+
+		case <-ticker.C:
+			// drain everything that is due before waiting again
+			for {
+				n, err := w.service.CreateCheckpoints(ctx, w.batchSize)
+				if err != nil {
+					log.Println("❌ Create balance checkpoints failed:", err)
+					break
+				}
+
+				if n > 0 {
+					log.Printf("📌 Created %d balance checkpoints", n)
+				}
+
+				if n < w.batchSize {
+					break
+				}
+			}
+		}
+	}
+}
@@ -2,8 +2,12 @@ package transaction_test
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 	"transaction/internal/transaction"
 )
 
@@ -16,14 +20,14 @@ func TestConcurrentWithdraw(t *testing.T) {
 	service := transaction.NewTransactionService(db, &MockAccountClient{}, txRepo)
 
 	acc := createTestAccount(t, db, "Concurrent User")
-	service.Deposit(ctx, key, acc.ID, 10_000, "initial")
+	service.Deposit(ctx, key, acc.ID, 10_000, "USD", "initial", transaction.Labels{})
 
 	var wg sync.WaitGroup
 	errors := make(chan error, 2)
 
 	withdraw := func() {
 		defer wg.Done()
-		err := service.Withdraw(ctx, key, acc.ID, 8_000, "race")
+		_, err := service.Withdraw(ctx, key, acc.ID, 8_000, "USD", "race", transaction.Labels{})
 		errors <- err
 	}
 
@@ -60,22 +64,275 @@ func TestConcurrentTransfers(t *testing.T) {
 	from := createTestAccount(t, db, "From")
 	to := createTestAccount(t, db, "To")
 
-	service.Deposit(ctx, key, from.ID, 50_000, "fund")
+	service.Deposit(ctx, key, from.ID, 50_000, "USD", "fund", transaction.Labels{})
 
 	var wg sync.WaitGroup
+	errs := make(chan error, 10)
 	for i := 0; i < 10; i++ {
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
-			service.Transfer(ctx, from.ID, to.ID, 10_000, "parallel")
+			_, err := service.Transfer(ctx, "", from.ID, to.ID, 10_000, "USD", "parallel", transaction.Labels{})
+			errs <- err
 		}()
 	}
 
 	wg.Wait()
+	close(errs)
+
+	// conflicts are retried, so every transfer the funds cover goes through
+	success := 0
+	for err := range errs {
+		switch {
+		case err == nil:
+			success++
+		case !errors.Is(err, transaction.ErrInsufficientFunds):
+			t.Errorf("unexpected error: %v", err)
+		}
+	}
+
+	if success != 5 {
+		t.Fatalf("expected all 5 affordable transfers to succeed, got %d", success)
+	}
 
 	entries, _ := txRepo.ListByAccount(ctx, from.ID)
 
 	if len(entries) > 6 { // 1 deposit + 5 transfers max
 		t.Fatal("double-spend detected")
 	}
+
+	assertBalance(t, service, from.ID, 0)
+	assertBalance(t, service, to.ID, 50_000)
+}
+
+func TestConcurrentWithdraw_NeverOverdraws(t *testing.T) {
+	db := setupTestDB(t)
+	ctx := context.Background()
+
+	txRepo := transaction.NewPostgresRepo(db)
+	service := transaction.NewTransactionService(db, &MockAccountClient{}, txRepo)
+
+	acc := createTestAccount(t, db, "Parallel User")
+	if err := service.Deposit(ctx, "", acc.ID, 10_000, "USD", "initial", transaction.Labels{}); err != nil {
+		t.Fatalf("seed deposit failed: %v", err)
+	}
+
+	const workers = 50
+	var wg sync.WaitGroup
+	errs := make(chan error, workers)
+
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, err := service.Withdraw(ctx, fmt.Sprintf("withdraw-%d", i), acc.ID, 1_000, "USD", "race", transaction.Labels{})
+			errs <- err
+		}(i)
+	}
+
+	wg.Wait()
+	close(errs)
+
+	success := 0
+	for err := range errs {
+		switch {
+		case err == nil:
+			success++
+		case !errors.Is(err, transaction.ErrInsufficientFunds):
+			t.Errorf("unexpected error: %v", err)
+		}
+	}
+
+	if success != 10 {
+		t.Fatalf("expected exactly 10 withdrawals to succeed, got %d", success)
+	}
+
+	assertBalance(t, service, acc.ID, 0)
+}
+
+func TestConcurrentTransfers_OpposingDirections(t *testing.T) {
+	db := setupTestDB(t)
+	ctx := context.Background()
+
+	txRepo := transaction.NewPostgresRepo(db)
+	service := transaction.NewTransactionService(db, &MockAccountClient{}, txRepo)
+
+	alice := createTestAccount(t, db, "Alice")
+	bob := createTestAccount(t, db, "Bob")
+
+	for _, acc := range []int64{alice.ID, bob.ID} {
+		if err := service.Deposit(ctx, "", acc, 5_000, "USD", "fund", transaction.Labels{}); err != nil {
+			t.Fatalf("seed deposit failed: %v", err)
+		}
+	}
+
+	// opposite lock orders would deadlock without ordered locking
+	var wg sync.WaitGroup
+	for i := 0; i < 40; i++ {
+		from, to := alice.ID, bob.ID
+		if i%2 == 1 {
+			from, to = to, from
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := service.Transfer(ctx, "", from, to, 700, "USD", "ping-pong", transaction.Labels{})
+			if err != nil && !errors.Is(err, transaction.ErrInsufficientFunds) {
+				t.Errorf("unexpected error: %v", err)
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	var total int64
+	for _, acc := range []int64{alice.ID, bob.ID} {
+		balance, err := txRepo.BalanceOf(ctx, acc, "USD")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if balance < 0 {
+			t.Fatalf("account %d overdrawn: %d", acc, balance)
+		}
+		total += balance
+	}
+
+	if total != 10_000 {
+		t.Fatalf("transfers must conserve money: expected 10000, got %d", total)
+	}
+}
+
+func TestConcurrentDebits_MixedPaths(t *testing.T) {
+	db := setupTestDB(t)
+	ctx := context.Background()
+
+	txRepo := transaction.NewPostgresRepo(db)
+	service := transaction.NewTransactionService(db, &MockAccountClient{}, txRepo)
+
+	acc := createTestAccount(t, db, "Busy Account")
+	sink := createTestAccount(t, db, "Sink")
+
+	if err := service.Deposit(ctx, "", acc.ID, 10_000, "USD", "initial", transaction.Labels{}); err != nil {
+		t.Fatalf("seed deposit failed: %v", err)
+	}
+
+	// each path tries to take 500, 80 times over, against 10000 of funds
+	debits := []func(i int) error{
+		func(i int) error {
+			_, err := service.Withdraw(ctx, fmt.Sprintf("mixed-w-%d", i), acc.ID, 500, "USD", "withdraw", transaction.Labels{})
+			return err
+		},
+		func(i int) error {
+			_, err := service.Transfer(ctx, fmt.Sprintf("mixed-t-%d", i), acc.ID, sink.ID, 500, "USD", "transfer", transaction.Labels{})
+			return err
+		},
+		func(i int) error {
+			hold, err := service.PlaceHold(ctx, acc.ID, 500, "USD", time.Hour, "hold")
+			if err != nil {
+				return err
+			}
+			_, err = service.CaptureHold(ctx, hold.ID, 0, "capture")
+			return err
+		},
+		func(i int) error {
+			_, err := service.BatchTransfer(ctx, fmt.Sprintf("mixed-b-%d", i), []transaction.TransferLeg{
+				{FromAccountID: acc.ID, ToAccountID: sink.ID, Amount: 250, Note: "batch"},
+				{FromAccountID: acc.ID, ToAccountID: sink.ID, Amount: 250, Note: "batch"},
+			})
+			return err
+		},
+	}
+
+	var (
+		wg      sync.WaitGroup
+		debited atomic.Int64
+	)
+	for i := 0; i < 80; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			err := debits[i%len(debits)](i)
+			switch {
+			case err == nil:
+				debited.Add(500)
+			case !errors.Is(err, transaction.ErrInsufficientFunds):
+				t.Errorf("unexpected error: %v", err)
+			}
+		}(i)
+	}
+
+	wg.Wait()
+
+	balance, err := txRepo.BalanceOf(ctx, acc.ID, "USD")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if balance < 0 {
+		t.Fatalf("account overdrawn: %d", balance)
+	}
+
+	if balance != 10_000-debited.Load() {
+		t.Fatalf("expected balance %d after %d debited, got %d", 10_000-debited.Load(), debited.Load(), balance)
+	}
+}
+
+func TestConcurrentReversals_RefundOnce(t *testing.T) {
+	db := setupTestDB(t)
+	ctx := context.Background()
+
+	txRepo := transaction.NewPostgresRepo(db)
+	service := transaction.NewTransactionService(db, &MockAccountClient{}, txRepo)
+
+	acc := createTestAccount(t, db, "Refund User")
+	if err := service.Deposit(ctx, "", acc.ID, 10_000, "USD", "initial", transaction.Labels{}); err != nil {
+		t.Fatalf("seed deposit failed: %v", err)
+	}
+	if _, err := service.Withdraw(ctx, "", acc.ID, 4_000, "USD", "cash", transaction.Labels{}); err != nil {
+		t.Fatalf("withdraw failed: %v", err)
+	}
+
+	entries, _ := txRepo.ListByAccount(ctx, acc.ID)
+	var withdrawal *transaction.Transaction
+	for i := range entries {
+		if entries[i].Type == transaction.TypeWithdraw {
+			withdrawal = &entries[i]
+		}
+	}
+	if withdrawal == nil {
+		t.Fatal("withdrawal entry not found")
+	}
+
+	const workers = 10
+	var wg sync.WaitGroup
+	errs := make(chan error, workers)
+
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := service.Reverse(ctx, withdrawal.ID, 0, "refund")
+			errs <- err
+		}()
+	}
+
+	wg.Wait()
+	close(errs)
+
+	success := 0
+	for err := range errs {
+		switch {
+		case err == nil:
+			success++
+		case !errors.Is(err, transaction.ErrAlreadyReversed):
+			t.Errorf("unexpected error: %v", err)
+		}
+	}
+
+	if success != 1 {
+		t.Fatalf("expected exactly one reversal to succeed, got %d", success)
+	}
+
+	assertBalance(t, service, acc.ID, 10_000)
 }
@@ -0,0 +1,46 @@
+package transaction
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+)
+
+// DefaultCurrency is assumed for accounts created before currencies existed.
+const DefaultCurrency = "USD"
+
+var (
+	ErrInvalidCurrency  = errors.New("currency must be a 3-letter ISO 4217 code")
+	ErrCurrencyMismatch = errors.New("account does not hold this currency")
+)
+
+var currencyCode = regexp.MustCompile(`^[A-Z]{3}$`)
+
+type Balance struct {
+	Currency  string `json:"currency"`
+	Ledger    int64  `json:"ledger"`    // posted balance in cents
+	Available int64  `json:"available"` // ledger minus active holds
+}
+
+// resolveCurrency checks a requested currency against the one the account
+// is held in. An empty request means "the account's currency".
+func resolveCurrency(requested, accountCurrency string) (string, error) {
+	if accountCurrency == "" {
+		accountCurrency = DefaultCurrency
+	}
+
+	requested = strings.ToUpper(strings.TrimSpace(requested))
+	if requested == "" {
+		return accountCurrency, nil
+	}
+
+	if !currencyCode.MatchString(requested) {
+		return "", ErrInvalidCurrency
+	}
+
+	if requested != accountCurrency {
+		return "", ErrCurrencyMismatch
+	}
+
+	return requested, nil
+}
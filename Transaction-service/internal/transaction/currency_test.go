@@ -0,0 +1,47 @@
+package transaction_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"transaction/internal/transaction"
+)
+
+func TestCurrency_RejectsMismatch(t *testing.T) {
+	db := setupTestDB(t)
+	ctx := context.Background()
+
+	eur := createTestAccount(t, db, "Euro User")
+	gbp := createTestAccount(t, db, "Sterling User")
+
+	txRepo := transaction.NewPostgresRepo(db)
+	service := transaction.NewTransactionService(db, &MockAccountClient{
+		Currencies: map[int64]string{eur.ID: "EUR", gbp.ID: "GBP"},
+	}, txRepo)
+
+	if err := service.Deposit(ctx, "cur-1", eur.ID, 5_000, "USD", "wrong currency", transaction.Labels{}); !errors.Is(err, transaction.ErrCurrencyMismatch) {
+		t.Fatalf("expected ErrCurrencyMismatch, got %v", err)
+	}
+
+	if err := service.Deposit(ctx, "cur-2", eur.ID, 5_000, "EUR", "salary", transaction.Labels{}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := service.Transfer(ctx, "", eur.ID, gbp.ID, 1_000, "EUR", "cross currency", transaction.Labels{}); !errors.Is(err, transaction.ErrCurrencyMismatch) {
+		t.Fatalf("expected ErrCurrencyMismatch, got %v", err)
+	}
+
+	balances, err := service.Balance(ctx, eur.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(balances) != 1 || balances[0].Currency != "EUR" || balances[0].Ledger != 5_000 {
+		t.Fatalf("expected a single EUR balance of 5000, got %+v", balances)
+	}
+
+	entries, _ := txRepo.ListByAccount(ctx, eur.ID)
+	if len(entries) != 1 || entries[0].Currency != "EUR" {
+		t.Fatalf("expected one EUR ledger entry, got %+v", entries)
+	}
+}
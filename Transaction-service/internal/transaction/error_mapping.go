@@ -0,0 +1,116 @@
+package transaction
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"transaction/internal/infrastructure/database"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// errorMapping is how a domain error is presented to API clients.
+type errorMapping struct {
+	err    error
+	status int
+	grpc   codes.Code
+	code   string
+}
+
+// errorMappings is the one place domain errors are translated. The first
+// match wins; errors not listed are internal errors.
+var errorMappings = []errorMapping{
+	{ErrInvalidAmount, http.StatusBadRequest, codes.InvalidArgument, "INVALID_AMOUNT"},
+	{ErrSameAccount, http.StatusBadRequest, codes.InvalidArgument, "SAME_ACCOUNT_TRANSFER"},
+	{ErrInvalidCurrency, http.StatusBadRequest, codes.InvalidArgument, "INVALID_CURRENCY"},
+	{ErrUnsupportedFormat, http.StatusNotAcceptable, codes.InvalidArgument, "UNSUPPORTED_FORMAT"},
+	{ErrInvalidCursor, http.StatusBadRequest, codes.InvalidArgument, "INVALID_CURSOR"},
+	{ErrInvalidTTL, http.StatusBadRequest, codes.InvalidArgument, "INVALID_TTL"},
+	{ErrEmptyBatch, http.StatusBadRequest, codes.InvalidArgument, "INVALID_BATCH"},
+	{ErrBatchTooLarge, http.StatusBadRequest, codes.InvalidArgument, "INVALID_BATCH"},
+	{database.ErrRetriesExhausted, http.StatusServiceUnavailable, codes.Aborted, "CONFLICT_RETRY_LATER"},
+	{ErrUpstreamUnavailable, http.StatusServiceUnavailable, codes.Unavailable, "UPSTREAM_UNAVAILABLE"},
+	{ErrAccountNotFound, http.StatusNotFound, codes.NotFound, "ACCOUNT_NOT_FOUND"},
+	{ErrAccountInactive, http.StatusUnprocessableEntity, codes.FailedPrecondition, "ACCOUNT_INACTIVE"},
+	{ErrCurrencyMismatch, http.StatusUnprocessableEntity, codes.FailedPrecondition, "CURRENCY_MISMATCH"},
+	{ErrInsufficientFunds, http.StatusUnprocessableEntity, codes.FailedPrecondition, "INSUFFICIENT_FUNDS"},
+	{ErrLimitExceeded, http.StatusUnprocessableEntity, codes.ResourceExhausted, "LIMIT_EXCEEDED"},
+	{ErrInvalidLabels, http.StatusBadRequest, codes.InvalidArgument, "INVALID_LABELS"},
+	{ErrInvalidFeeRule, http.StatusBadRequest, codes.InvalidArgument, "INVALID_FEE_RULE"},
+	{ErrFeeRuleNotFound, http.StatusNotFound, codes.NotFound, "FEE_RULE_NOT_FOUND"},
+	{ErrInvalidInterestRate, http.StatusBadRequest, codes.InvalidArgument, "INVALID_INTEREST_RATE"},
+	{ErrInterestRateNotFound, http.StatusNotFound, codes.NotFound, "INTEREST_RATE_NOT_FOUND"},
+	{ErrInvalidWindow, http.StatusBadRequest, codes.InvalidArgument, "INVALID_WINDOW"},
+	{ErrNoChainSigner, http.StatusNotFound, codes.NotFound, "CHAIN_SIGNING_DISABLED"},
+	{ErrInvalidLimits, http.StatusBadRequest, codes.InvalidArgument, "INVALID_LIMITS"},
+	{ErrInvalidScope, http.StatusBadRequest, codes.InvalidArgument, "INVALID_LIMITS"},
+	{ErrTransactionNotFound, http.StatusNotFound, codes.NotFound, "TRANSACTION_NOT_FOUND"},
+	{ErrAlreadyReversed, http.StatusConflict, codes.FailedPrecondition, "ALREADY_REVERSED"},
+	{ErrReversalOfReversal, http.StatusBadRequest, codes.InvalidArgument, "INVALID_REVERSAL"},
+	{ErrReversalExceedsAmount, http.StatusBadRequest, codes.InvalidArgument, "INVALID_REVERSAL"},
+	{ErrHoldNotFound, http.StatusNotFound, codes.NotFound, "HOLD_NOT_FOUND"},
+	{ErrHoldNotActive, http.StatusConflict, codes.FailedPrecondition, "HOLD_NOT_ACTIVE"},
+	{ErrCaptureExceedsHold, http.StatusBadRequest, codes.InvalidArgument, "INVALID_AMOUNT"},
+	{ErrIdempotencyKeyReused, http.StatusUnprocessableEntity, codes.AlreadyExists, "IDEMPOTENCY_KEY_REUSED"},
+	{ErrStatementNotFound, http.StatusNotFound, codes.NotFound, "STATEMENT_NOT_FOUND"},
+	{ErrInvalidPeriod, http.StatusBadRequest, codes.InvalidArgument, "INVALID_PERIOD"},
+	{ErrScheduleNotFound, http.StatusNotFound, codes.NotFound, "SCHEDULE_NOT_FOUND"},
+	{ErrScheduleClosed, http.StatusConflict, codes.FailedPrecondition, "SCHEDULE_CLOSED"},
+	{ErrInvalidSchedule, http.StatusBadRequest, codes.InvalidArgument, "INVALID_SCHEDULE"},
+}
+
+func lookupError(err error) errorMapping {
+	for _, m := range errorMappings {
+		if errors.Is(err, m.err) {
+			return m
+		}
+	}
+	return errorMapping{err: err, status: http.StatusInternalServerError, grpc: codes.Internal, code: "INTERNAL_ERROR"}
+}
+
+// HTTPError returns the HTTP status and machine-readable code for err.
+func HTTPError(err error) (int, string) {
+	m := lookupError(err)
+	return m.status, m.code
+}
+
+// GRPCError converts err into a gRPC status error.
+func GRPCError(err error) error {
+	m := lookupError(err)
+	if m.grpc == codes.Internal {
+		log.Printf("❌ %v", err)
+		return status.Error(codes.Internal, "internal error")
+	}
+	return status.Error(m.grpc, err.Error())
+}
+
+// RespondServiceError writes err using the shared mapping, for handlers
+// outside this package.
+func RespondServiceError(w http.ResponseWriter, err error) {
+	respondServiceError(w, err)
+}
+
+// respondServiceError writes err using the shared mapping. Details of
+// internal errors are logged, not returned; a hit limit is described in the
+// error details.
+func respondServiceError(w http.ResponseWriter, err error) {
+	statusCode, code := HTTPError(err)
+
+	message := err.Error()
+	if statusCode == http.StatusInternalServerError {
+		log.Printf("❌ %v", err)
+		message = "internal server error"
+	}
+
+	resp := ErrorResponse{Status: "error"}
+	resp.Error.Code = code
+	resp.Error.Message = message
+
+	var limitErr *LimitError
+	if errors.As(err, &limitErr) {
+		resp.Error.Details = limitErr
+	}
+
+	respondJSON(w, statusCode, resp)
+}
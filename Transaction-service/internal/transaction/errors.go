@@ -0,0 +1,104 @@
+package transaction
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"transaction/pb"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var (
+	ErrInvalidAmount       = errors.New("amount must be positive")
+	ErrInsufficientFunds   = errors.New("insufficient funds")
+	ErrAccountNotFound     = errors.New("account not found")
+	ErrAccountInactive     = errors.New("account is inactive")
+	ErrSameAccount         = errors.New("cannot transfer to the same account")
+	ErrUpstreamUnavailable = errors.New("account service unavailable")
+)
+
+// Errors of packages built on this one are declared here so the shared
+// error mapping can translate them; those packages re-export them.
+var (
+	ErrStatementNotFound = errors.New("statement not found")
+	ErrInvalidPeriod     = errors.New("invalid period")
+	ErrScheduleNotFound  = errors.New("schedule not found")
+	ErrScheduleClosed    = errors.New("schedule is completed or cancelled")
+	ErrInvalidSchedule   = errors.New("invalid schedule")
+)
+
+// AccountError ties an account failure to the account it concerns, so a
+// transfer can say which side was rejected.
+type AccountError struct {
+	AccountID int64
+	Err       error
+}
+
+func (e *AccountError) Error() string {
+	return fmt.Sprintf("account %d: %v", e.AccountID, e.Err)
+}
+
+func (e *AccountError) Unwrap() error {
+	return e.Err
+}
+
+// activeAccount asks the account service for an account and returns it only
+// if it exists and is active.
+func (s *Service) activeAccount(ctx context.Context, accountID int64) (*pb.GetAccountResponse, error) {
+	return ActiveAccount(ctx, s.accountClient, accountID)
+}
+
+// ActiveAccount asks the account service for an account and returns it only
+// if it exists and is active. An unreachable account service is reported as
+// ErrUpstreamUnavailable, never as a missing account.
+func ActiveAccount(ctx context.Context, client pb.AccountServiceClient, accountID int64) (*pb.GetAccountResponse, error) {
+	resp, err := client.GetAccount(ctx, &pb.GetAccountRequest{
+		AccountId: accountID,
+	})
+
+	if status.Code(err) == codes.NotFound {
+		return nil, &AccountError{AccountID: accountID, Err: ErrAccountNotFound}
+	}
+
+	if err != nil {
+		return nil, &AccountError{AccountID: accountID, Err: fmt.Errorf("%w: %v", ErrUpstreamUnavailable, err)}
+	}
+
+	if !resp.IsExists {
+		return nil, &AccountError{AccountID: accountID, Err: ErrAccountNotFound}
+	}
+
+	if !resp.IsActive {
+		return nil, &AccountError{AccountID: accountID, Err: ErrAccountInactive}
+	}
+
+	return resp, nil
+}
+
+// CheckTransfer asks the account service whether a transfer between the
+// accounts could be made, and returns the currency it would be made in.
+func CheckTransfer(ctx context.Context, client pb.AccountServiceClient, fromAccountID, toAccountID int64, currency string) (string, error) {
+	from, err := ActiveAccount(ctx, client, fromAccountID)
+	if err != nil {
+		return "", err
+	}
+
+	to, err := ActiveAccount(ctx, client, toAccountID)
+	if err != nil {
+		return "", err
+	}
+
+	// both sides must hold the transferred currency
+	currency, err = resolveCurrency(currency, from.Currency)
+	if err != nil {
+		return "", err
+	}
+
+	if _, err := resolveCurrency(currency, to.Currency); err != nil {
+		return "", err
+	}
+
+	return currency, nil
+}
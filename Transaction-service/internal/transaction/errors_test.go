@@ -0,0 +1,82 @@
+package transaction_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"transaction/internal/transaction"
+	"transaction/pb"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// failingAccountClient answers every lookup with the given error.
+type failingAccountClient struct {
+	err error
+}
+
+func (f *failingAccountClient) GetAccount(ctx context.Context, in *pb.GetAccountRequest, opts ...grpc.CallOption) (*pb.GetAccountResponse, error) {
+	return nil, f.err
+}
+
+func TestAccountLookupErrors(t *testing.T) {
+	tests := []struct {
+		name       string
+		upstream   error
+		want       error
+		wantStatus int
+		wantCode   string
+	}{
+		{"missing account", status.Error(codes.NotFound, "account not found"), transaction.ErrAccountNotFound, http.StatusNotFound, "ACCOUNT_NOT_FOUND"},
+		{"account service down", status.Error(codes.Unavailable, "connection refused"), transaction.ErrUpstreamUnavailable, http.StatusServiceUnavailable, "UPSTREAM_UNAVAILABLE"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// the account lookup happens before any database access
+			service := transaction.NewTransactionService(nil, &failingAccountClient{err: tt.upstream}, nil)
+
+			err := service.Deposit(context.Background(), "", 1, 1_000, "USD", "lookup", transaction.Labels{})
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("expected %v, got %v", tt.want, err)
+			}
+
+			var accErr *transaction.AccountError
+			if !errors.As(err, &accErr) || accErr.AccountID != 1 {
+				t.Fatalf("expected an AccountError for account 1, got %v", err)
+			}
+
+			statusCode, code := transaction.HTTPError(err)
+			if statusCode != tt.wantStatus || code != tt.wantCode {
+				t.Fatalf("expected %d %s, got %d %s", tt.wantStatus, tt.wantCode, statusCode, code)
+			}
+		})
+	}
+}
+
+func TestHTTPError_UnknownIsInternal(t *testing.T) {
+	statusCode, code := transaction.HTTPError(errors.New("connection reset"))
+	if statusCode != http.StatusInternalServerError || code != "INTERNAL_ERROR" {
+		t.Fatalf("expected 500 INTERNAL_ERROR, got %d %s", statusCode, code)
+	}
+
+	if status.Code(transaction.GRPCError(transaction.ErrInsufficientFunds)) != codes.FailedPrecondition {
+		t.Fatal("expected insufficient funds to map to FailedPrecondition")
+	}
+}
+
+func TestHTTPError_LimitExceeded(t *testing.T) {
+	err := &transaction.LimitError{Limit: transaction.LimitDaily, Max: 100_000, Used: 90_000}
+
+	statusCode, code := transaction.HTTPError(err)
+	if statusCode != http.StatusUnprocessableEntity || code != "LIMIT_EXCEEDED" {
+		t.Fatalf("expected 422 LIMIT_EXCEEDED, got %d %s", statusCode, code)
+	}
+
+	if status.Code(transaction.GRPCError(err)) != codes.ResourceExhausted {
+		t.Fatal("expected a hit limit to map to ResourceExhausted")
+	}
+}
@@ -0,0 +1,251 @@
+package transaction
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ErrUnsupportedFormat = errors.New("unsupported export format")
+
+// Export formats.
+const (
+	FormatCSV = "csv"
+	FormatOFX = "ofx"
+	FormatQIF = "qif"
+)
+
+// ExportFilter selects the entries of an export. From is inclusive, To is
+// exclusive and an empty Currency means the account's currency.
+type ExportFilter struct {
+	From     *time.Time
+	To       *time.Time
+	Currency string
+}
+
+// ExportInfo describes an export before its first entry is written.
+type ExportInfo struct {
+	AccountID int64
+	Currency  string
+	From      *time.Time
+	To        *time.Time
+	Balance   int64     // ledger balance at the end of the range
+	BalanceAt time.Time // when Balance applies
+}
+
+// Exporter writes one export format. Begin is called once the account has
+// been checked, then Entry for every entry oldest first, then End.
+type Exporter interface {
+	ContentType() string
+	Extension() string
+	Begin(info ExportInfo) error
+	Entry(t *Transaction) error
+	End() error
+}
+
+// NewExporter returns the exporter for format writing to w.
+func NewExporter(format string, w io.Writer) (Exporter, error) {
+	switch format {
+	case FormatCSV:
+		return &csvExporter{w: csv.NewWriter(w)}, nil
+	case FormatOFX:
+		return &ofxExporter{w: w}, nil
+	case FormatQIF:
+		return &qifExporter{w: w}, nil
+	}
+	return nil, ErrUnsupportedFormat
+}
+
+// Export streams the account's history in one currency to exporter.
+func (s *Service) Export(ctx context.Context, accountID int64, filter ExportFilter, exporter Exporter) error {
+	resp, err := s.activeAccount(ctx, accountID)
+	if err != nil {
+		return err
+	}
+
+	filter.Currency = strings.ToUpper(strings.TrimSpace(filter.Currency))
+	if filter.Currency == "" {
+		filter.Currency, _ = resolveCurrency("", resp.Currency)
+	} else if !currencyCode.MatchString(filter.Currency) {
+		return ErrInvalidCurrency
+	}
+
+	info := ExportInfo{
+		AccountID: accountID,
+		Currency:  filter.Currency,
+		From:      filter.From,
+		To:        filter.To,
+		BalanceAt: time.Now().UTC(),
+	}
+	if filter.To != nil && filter.To.Before(info.BalanceAt) {
+		info.BalanceAt = filter.To.Add(-time.Microsecond)
+	}
+
+	info.Balance, err = s.transactionRepo.BalanceAt(ctx, accountID, filter.Currency, info.BalanceAt)
+	if err != nil {
+		return err
+	}
+
+	if err := exporter.Begin(info); err != nil {
+		return err
+	}
+
+	if err := s.transactionRepo.EachByAccount(ctx, accountID, filter, func(t Transaction) error {
+		return exporter.Entry(&t)
+	}); err != nil {
+		return err
+	}
+
+	return exporter.End()
+}
+
+// formatAmount renders cents as a decimal with two places.
+func formatAmount(cents int64) string {
+	sign := ""
+	if cents < 0 {
+		sign = "-"
+		cents = -cents
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, cents/100, cents%100)
+}
+
+type csvExporter struct {
+	w *csv.Writer
+}
+
+func (e *csvExporter) ContentType() string { return "text/csv; charset=utf-8" }
+func (e *csvExporter) Extension() string   { return "csv" }
+
+func (e *csvExporter) Begin(ExportInfo) error {
+	return e.w.Write([]string{"id", "date", "type", "amount", "currency", "balance", "note"})
+}
+
+func (e *csvExporter) Entry(t *Transaction) error {
+	return e.w.Write([]string{
+		strconv.FormatInt(t.ID, 10),
+		t.CreatedAt.UTC().Format(time.RFC3339),
+		string(t.Type),
+		formatAmount(t.SignedAmount()),
+		t.Currency,
+		formatAmount(t.BalanceAfter),
+		t.Note,
+	})
+}
+
+func (e *csvExporter) End() error {
+	e.w.Flush()
+	return e.w.Error()
+}
+
+// ofxExporter writes an OFX 2.2 bank statement.
+type ofxExporter struct {
+	w       io.Writer
+	info    ExportInfo
+	started time.Time
+}
+
+// ofxTime is the OFX date-time format, always in UTC.
+func ofxTime(t time.Time) string {
+	return t.UTC().Format("20060102150405") + "[0:GMT]"
+}
+
+func ofxText(s string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
+
+// ofxType maps an entry to the closest OFX transaction type.
+func ofxType(t Type) string {
+	switch t {
+	case TypeDeposit:
+		return "DEP"
+	case TypeTransferIn, TypeTransferOut:
+		return "XFER"
+	case TypeFee:
+		return "FEE"
+	case TypeInterest:
+		return "INT"
+	}
+	if t.Credit() {
+		return "CREDIT"
+	}
+	return "DEBIT"
+}
+
+func (e *ofxExporter) ContentType() string { return "application/x-ofx" }
+func (e *ofxExporter) Extension() string   { return "ofx" }
+
+func (e *ofxExporter) Begin(info ExportInfo) error {
+	e.info = info
+	e.started = time.Now()
+
+	start, end := e.started, e.started
+	if info.From != nil {
+		start = *info.From
+	}
+	if info.To != nil {
+		end = *info.To
+	}
+
+	_, err := fmt.Fprintf(e.w, `<?xml version="1.0" encoding="UTF-8" standalone="no"?>
+<?OFX OFXHEADER="200" VERSION="220" SECURITY="NONE" OLDFILEUID="NONE" NEWFILEUID="NONE"?>
+<OFX>
+<SIGNONMSGSRSV1><SONRS><STATUS><CODE>0</CODE><SEVERITY>INFO</SEVERITY></STATUS><DTSERVER>%s</DTSERVER><LANGUAGE>ENG</LANGUAGE></SONRS></SIGNONMSGSRSV1>
+<BANKMSGSRSV1><STMTTRNRS><TRNUID>0</TRNUID><STATUS><CODE>0</CODE><SEVERITY>INFO</SEVERITY></STATUS>
+<STMTRS><CURDEF>%s</CURDEF>
+<BANKACCTFROM><BANKID>000000000</BANKID><ACCTID>%d</ACCTID><ACCTTYPE>CHECKING</ACCTTYPE></BANKACCTFROM>
+<BANKTRANLIST><DTSTART>%s</DTSTART><DTEND>%s</DTEND>
+`, ofxTime(e.started), info.Currency, info.AccountID, ofxTime(start), ofxTime(end))
+	return err
+}
+
+func (e *ofxExporter) Entry(t *Transaction) error {
+	_, err := fmt.Fprintf(e.w, "<STMTTRN><TRNTYPE>%s</TRNTYPE><DTPOSTED>%s</DTPOSTED><TRNAMT>%s</TRNAMT><FITID>%d</FITID><NAME>%s</NAME><MEMO>%s</MEMO></STMTTRN>\n",
+		ofxType(t.Type), ofxTime(t.CreatedAt), formatAmount(t.SignedAmount()), t.ID, ofxText(string(t.Type)), ofxText(t.Note))
+	return err
+}
+
+// End closes the statement with the ledger balance at the end of the range.
+func (e *ofxExporter) End() error {
+	_, err := fmt.Fprintf(e.w, `</BANKTRANLIST>
+<LEDGERBAL><BALAMT>%s</BALAMT><DTASOF>%s</DTASOF></LEDGERBAL>
+</STMTRS></STMTTRNRS></BANKMSGSRSV1>
+</OFX>
+`, formatAmount(e.info.Balance), ofxTime(e.info.BalanceAt))
+	return err
+}
+
+// qifExporter writes a Quicken Interchange Format bank register.
+type qifExporter struct {
+	w io.Writer
+}
+
+// qifLine strips line breaks, which would end a QIF field early.
+func qifLine(s string) string {
+	return strings.NewReplacer("\r", " ", "\n", " ").Replace(s)
+}
+
+func (e *qifExporter) ContentType() string { return "application/qif" }
+func (e *qifExporter) Extension() string   { return "qif" }
+
+func (e *qifExporter) Begin(ExportInfo) error {
+	_, err := io.WriteString(e.w, "!Type:Bank\n")
+	return err
+}
+
+func (e *qifExporter) Entry(t *Transaction) error {
+	_, err := fmt.Fprintf(e.w, "D%s\nT%s\nN%d\nP%s\nM%s\n^\n",
+		t.CreatedAt.UTC().Format("01/02/2006"), formatAmount(t.SignedAmount()), t.ID, t.Type, qifLine(t.Note))
+	return err
+}
+
+func (e *qifExporter) End() error {
+	return nil
+}
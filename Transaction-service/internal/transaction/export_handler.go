@@ -0,0 +1,205 @@
+package transaction
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi"
+)
+
+// exportFormats lists the media types each format is served as. When the
+// client likes several formats equally, the earlier one wins.
+var exportFormats = []struct {
+	format     string
+	mediaTypes []string
+}{
+	{FormatCSV, []string{"text/csv", "application/csv"}},
+	{FormatOFX, []string{"application/x-ofx", "application/ofx", "application/vnd.intu.qfx"}},
+	{FormatQIF, []string{"application/qif", "application/x-qif"}},
+}
+
+// exportUnsupportedFilters are the history parameters an export cannot
+// apply: it always covers every entry of its range, so its balance adds up.
+var exportUnsupportedFilters = []string{"cursor", "limit", "type", "as_of", "min_amount", "max_amount", "note", "tag"}
+
+// acceptRange is one media range of an Accept header.
+type acceptRange struct {
+	mediaType string
+	q         float64
+	index     int // position in the header
+}
+
+// parseAccept reads the media ranges of an Accept header, skipping ranges
+// that do not parse.
+func parseAccept(header string) []acceptRange {
+	var ranges []acceptRange
+	for i, part := range strings.Split(header, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+
+		q := 1.0
+		if v, ok := params["q"]; ok {
+			q, err = strconv.ParseFloat(v, 64)
+			if err != nil || q < 0 || q > 1 {
+				continue
+			}
+		}
+
+		ranges = append(ranges, acceptRange{mediaType: mediaType, q: q, index: i})
+	}
+	return ranges
+}
+
+// matchAccept returns the most specific range that covers mediaType.
+func matchAccept(ranges []acceptRange, mediaType string) (acceptRange, bool) {
+	var best acceptRange
+	bestRank := -1
+	for _, r := range ranges {
+		rank := -1
+		switch {
+		case r.mediaType == mediaType:
+			rank = 2
+		case r.mediaType == "*/*":
+			rank = 0
+		case strings.HasSuffix(r.mediaType, "/*") && strings.HasPrefix(mediaType, strings.TrimSuffix(r.mediaType, "*")):
+			rank = 1
+		}
+		if rank > bestRank {
+			best, bestRank = r, rank
+		}
+	}
+	return best, bestRank >= 0
+}
+
+// exportFormat picks the format from ?format=, then the one the Accept
+// header gives the highest quality, preferring the range listed first.
+// Without either the export is CSV.
+func exportFormat(r *http.Request) (string, bool) {
+	if v := r.URL.Query().Get("format"); v != "" {
+		format := strings.ToLower(v)
+		return format, format == FormatCSV || format == FormatOFX || format == FormatQIF
+	}
+
+	accept := r.Header.Get("Accept")
+	if accept == "" {
+		return FormatCSV, true
+	}
+
+	ranges := parseAccept(accept)
+
+	var (
+		format string
+		best   acceptRange
+	)
+	for _, f := range exportFormats {
+		for _, mediaType := range f.mediaTypes {
+			r, ok := matchAccept(ranges, mediaType)
+			if !ok || r.q <= 0 {
+				continue
+			}
+			if format == "" || r.q > best.q || (r.q == best.q && r.index < best.index) {
+				format, best = f.format, r
+			}
+		}
+	}
+
+	return format, format != ""
+}
+
+// exportFilterError names a history filter the request set that an export
+// cannot apply.
+func exportFilterError(r *http.Request) error {
+	q := r.URL.Query()
+	for _, name := range exportUnsupportedFilters {
+		if q.Has(name) {
+			return fmt.Errorf("%s cannot be used with an export", name)
+		}
+	}
+	for name := range q {
+		if strings.HasPrefix(name, "metadata.") {
+			return fmt.Errorf("%s cannot be used with an export", name)
+		}
+	}
+	return nil
+}
+
+// countingWriter remembers whether anything reached the client, after which
+// an error can no longer be reported as a JSON response.
+type countingWriter struct {
+	http.ResponseWriter
+	written int64
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	n, err := w.ResponseWriter.Write(p)
+	w.written += int64(n)
+	return n, err
+}
+
+// Export streams the account's history as CSV, OFX or QIF. It accepts the
+// same from/to parameters as History plus an optional currency; History's
+// other filters are rejected.
+func (h *TransactionHandler) Export(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "INVALID_ACCOUNT_ID", "Account ID must be a number")
+		return
+	}
+
+	format, ok := exportFormat(r)
+	if !ok {
+		respondError(w, http.StatusNotAcceptable, "UNSUPPORTED_FORMAT", "format must be csv, ofx or qif")
+		return
+	}
+
+	history, err := parseHistoryFilter(r)
+	if err == nil {
+		err = exportFilterError(r)
+	}
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "INVALID_QUERY", err.Error())
+		return
+	}
+
+	out := &countingWriter{ResponseWriter: w}
+	exporter, err := NewExporter(format, out)
+	if err != nil {
+		respondServiceError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", exporter.ContentType())
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="account-%d.%s"`, id, exporter.Extension()))
+
+	err = h.service.Export(r.Context(), id, ExportFilter{
+		From:     history.From,
+		To:       history.To,
+		Currency: r.URL.Query().Get("currency"),
+	}, exporter)
+	if err == nil {
+		return
+	}
+
+	if out.written == 0 {
+		w.Header().Del("Content-Disposition")
+		respondServiceError(w, err)
+		return
+	}
+
+	// the status line is gone; drop the connection so a truncated export
+	// is not mistaken for a complete one
+	if !errors.Is(err, r.Context().Err()) {
+		log.Printf("❌ export of account %d failed after %d bytes: %v", id, out.written, err)
+	}
+
+	if conn, _, err := http.NewResponseController(w).Hijack(); err == nil {
+		conn.Close()
+	}
+}
@@ -0,0 +1,181 @@
+package transaction_test
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+	"transaction/internal/transaction"
+)
+
+var exportEntries = []transaction.Transaction{
+	{ID: 1, AccountID: 7, Type: transaction.TypeDeposit, Amount: 10_000, Currency: "USD", BalanceAfter: 10_000, Note: "salary", CreatedAt: time.Date(2025, 3, 1, 9, 0, 0, 0, time.UTC)},
+	{ID: 2, AccountID: 7, Type: transaction.TypeWithdraw, Amount: 2_505, Currency: "USD", BalanceAfter: 7_495, Note: "rent, \"march\"", CreatedAt: time.Date(2025, 3, 2, 10, 30, 0, 0, time.UTC)},
+}
+
+func runExport(t *testing.T, format string) string {
+	t.Helper()
+
+	var buf bytes.Buffer
+	exporter, err := transaction.NewExporter(format, &buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := exporter.Begin(transaction.ExportInfo{
+		AccountID: 7,
+		Currency:  "USD",
+		Balance:   7_495,
+		BalanceAt: time.Date(2025, 3, 31, 23, 59, 59, 0, time.UTC),
+	}); err != nil {
+		t.Fatal(err)
+	}
+	for i := range exportEntries {
+		if err := exporter.Entry(&exportEntries[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := exporter.End(); err != nil {
+		t.Fatal(err)
+	}
+
+	return buf.String()
+}
+
+func TestExport_CSV(t *testing.T) {
+	got := runExport(t, transaction.FormatCSV)
+
+	want := "id,date,type,amount,currency,balance,note\n" +
+		"1,2025-03-01T09:00:00Z,DEPOSIT,100.00,USD,100.00,salary\n" +
+		"2,2025-03-02T10:30:00Z,WITHDRAW,-25.05,USD,74.95,\"rent, \"\"march\"\"\"\n"
+	if got != want {
+		t.Fatalf("unexpected CSV:\n%s", got)
+	}
+}
+
+func TestExport_OFX(t *testing.T) {
+	got := runExport(t, transaction.FormatOFX)
+
+	for _, want := range []string{
+		`<?OFX OFXHEADER="200" VERSION="220"`,
+		"<CURDEF>USD</CURDEF>",
+		"<ACCTID>7</ACCTID>",
+		"<TRNTYPE>DEP</TRNTYPE><DTPOSTED>20250301090000[0:GMT]</DTPOSTED><TRNAMT>100.00</TRNAMT><FITID>1</FITID>",
+		"<TRNAMT>-25.05</TRNAMT>",
+		"<MEMO>rent, &#34;march&#34;</MEMO>",
+		"<LEDGERBAL><BALAMT>74.95</BALAMT><DTASOF>20250331235959[0:GMT]</DTASOF></LEDGERBAL>",
+		"</OFX>",
+	} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("OFX is missing %q:\n%s", want, got)
+		}
+	}
+}
+
+func TestExport_QIF(t *testing.T) {
+	got := runExport(t, transaction.FormatQIF)
+
+	want := "!Type:Bank\n" +
+		"D03/01/2025\nT100.00\nN1\nPDEPOSIT\nMsalary\n^\n" +
+		"D03/02/2025\nT-25.05\nN2\nPWITHDRAW\nMrent, \"march\"\n^\n"
+	if got != want {
+		t.Fatalf("unexpected QIF:\n%s", got)
+	}
+}
+
+// exportService streams exportEntries and records the filter it was given.
+type exportService struct {
+	transaction.TransactionService
+	filter transaction.ExportFilter
+}
+
+func (s *exportService) Export(ctx context.Context, accountID int64, filter transaction.ExportFilter, exporter transaction.Exporter) error {
+	s.filter = filter
+	if err := exporter.Begin(transaction.ExportInfo{AccountID: accountID, Currency: "USD"}); err != nil {
+		return err
+	}
+	for i := range exportEntries {
+		if err := exporter.Entry(&exportEntries[i]); err != nil {
+			return err
+		}
+	}
+	return exporter.End()
+}
+
+func TestExportHandler_Negotiation(t *testing.T) {
+	tests := []struct {
+		name       string
+		query      string
+		accept     string
+		wantStatus int
+		wantType   string
+		wantPrefix string
+	}{
+		{"default", "", "", http.StatusOK, "text/csv; charset=utf-8", "id,date"},
+		{"query wins", "?format=qif", "application/x-ofx", http.StatusOK, "application/qif", "!Type:Bank"},
+		{"accept", "", "application/x-ofx, text/csv;q=0.5", http.StatusOK, "application/x-ofx", "<?xml"},
+		{"quality beats order", "", "text/csv;q=0.1, application/x-ofx", http.StatusOK, "application/x-ofx", "<?xml"},
+		{"wildcard", "", "application/pdf, */*;q=0.8", http.StatusOK, "text/csv; charset=utf-8", "id,date"},
+		{"excluded by q=0", "", "text/csv;q=0, application/csv;q=0, */*", http.StatusOK, "application/x-ofx", "<?xml"},
+		{"nothing acceptable", "", "text/csv;q=0, application/pdf", http.StatusNotAcceptable, "application/json", "{"},
+		{"unknown", "", "application/pdf", http.StatusNotAcceptable, "application/json", "{"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := &exportService{}
+			handler := transaction.NewTransactionHandler(service, nil).Routes()
+
+			req := httptest.NewRequest(http.MethodGet, "/7/export"+tt.query, nil)
+			if tt.accept != "" {
+				req.Header.Set("Accept", tt.accept)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus || rec.Header().Get("Content-Type") != tt.wantType {
+				t.Fatalf("expected %d %s, got %d %s", tt.wantStatus, tt.wantType, rec.Code, rec.Header().Get("Content-Type"))
+			}
+			if !strings.HasPrefix(rec.Body.String(), tt.wantPrefix) {
+				t.Fatalf("unexpected body %q", rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestExportHandler_Range(t *testing.T) {
+	service := &exportService{}
+	handler := transaction.NewTransactionHandler(service, nil).Routes()
+
+	req := httptest.NewRequest(http.MethodGet, "/7/export?from=2025-03-01&to=2025-03-31&currency=eur", nil)
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	if service.filter.From == nil || !service.filter.From.Equal(time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("unexpected from %v", service.filter.From)
+	}
+	if service.filter.To == nil || !service.filter.To.Equal(time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("expected a date-only to to include the whole day, got %v", service.filter.To)
+	}
+	if service.filter.Currency != "eur" {
+		t.Fatalf("expected the currency to be passed through, got %q", service.filter.Currency)
+	}
+}
+
+func TestExportHandler_RejectsHistoryFilters(t *testing.T) {
+	for _, query := range []string{"?type=deposit", "?min_amount=100", "?tag=rent", "?metadata.ref=a", "?note=x"} {
+		t.Run(query, func(t *testing.T) {
+			service := &exportService{}
+			handler := transaction.NewTransactionHandler(service, nil).Routes()
+
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/7/export"+query, nil))
+
+			if rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), "INVALID_QUERY") {
+				t.Fatalf("expected 400 INVALID_QUERY, got %d %s", rec.Code, rec.Body.String())
+			}
+		})
+	}
+}
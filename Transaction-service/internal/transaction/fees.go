@@ -0,0 +1,295 @@
+package transaction
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+	"transaction/internal/infrastructure/database"
+	"transaction/pb"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrFeeRuleNotFound = errors.New("fee rule not found")
+	ErrInvalidFeeRule  = errors.New("invalid fee rule")
+)
+
+// FeeOperation is the kind of debit a fee rule prices.
+type FeeOperation string
+
+const (
+	FeeOnWithdraw FeeOperation = "WITHDRAW"
+	FeeOnTransfer FeeOperation = "TRANSFER"
+)
+
+// FeeKind is how a rule computes its fee from the debited amount.
+type FeeKind string
+
+const (
+	FeeFlat       FeeKind = "flat"
+	FeePercentage FeeKind = "percentage"
+	FeeTiered     FeeKind = "tiered"
+)
+
+// FeeTier charges Fee for amounts up to and including UpTo. Only the last
+// tier may leave UpTo unset, covering every larger amount.
+type FeeTier struct {
+	UpTo *int64 `json:"up_to,omitempty"`
+	Fee  int64  `json:"fee"`
+}
+
+// FeeRule prices withdrawals or outgoing transfers. Every enabled rule that
+// matches the operation and currency is charged, unless the account's tier
+// is waived. Amounts are cents of the debited currency.
+type FeeRule struct {
+	ID          int64        `json:"id"`
+	Name        string       `json:"name"`
+	Operation   FeeOperation `json:"operation"`
+	Currency    string       `json:"currency,omitempty"` // empty matches every currency
+	Kind        FeeKind      `json:"kind"`
+	Amount      int64        `json:"amount,omitempty"`       // flat
+	BasisPoints int64        `json:"basis_points,omitempty"` // percentage, 100 = 1%
+	Tiers       []FeeTier    `json:"tiers,omitempty"`        // tiered, ascending
+	Min         *int64       `json:"min,omitempty"`
+	Max         *int64       `json:"max,omitempty"`
+	WaivedTiers []string     `json:"waived_tiers,omitempty"`
+	Disabled    bool         `json:"disabled"`
+	CreatedAt   time.Time    `json:"created_at"`
+	UpdatedAt   time.Time    `json:"updated_at"`
+}
+
+// Fee is a fee charged with a debit. TransactionID is the FEE entry once it
+// has been posted.
+type Fee struct {
+	RuleID        int64  `json:"rule_id"`
+	Name          string `json:"name"`
+	Amount        int64  `json:"amount"`
+	Currency      string `json:"currency"`
+	TransactionID int64  `json:"transaction_id,omitempty"`
+}
+
+func invalidFeeRule(format string, args ...any) error {
+	return fmt.Errorf("%w: %s", ErrInvalidFeeRule, fmt.Sprintf(format, args...))
+}
+
+// normalize validates the rule and returns it with its currency upper-cased
+// and its waived tiers lower-cased.
+func (r FeeRule) normalize() (FeeRule, error) {
+	r.Name = strings.TrimSpace(r.Name)
+	if r.Name == "" {
+		return r, invalidFeeRule("name is required")
+	}
+
+	if r.Operation != FeeOnWithdraw && r.Operation != FeeOnTransfer {
+		return r, invalidFeeRule("operation must be %s or %s", FeeOnWithdraw, FeeOnTransfer)
+	}
+
+	r.Currency = strings.ToUpper(strings.TrimSpace(r.Currency))
+	if r.Currency != "" && !currencyCode.MatchString(r.Currency) {
+		return r, ErrInvalidCurrency
+	}
+
+	switch r.Kind {
+	case FeeFlat:
+		if r.Amount <= 0 {
+			return r, invalidFeeRule("a flat fee needs a positive amount")
+		}
+	case FeePercentage:
+		if r.BasisPoints <= 0 || r.BasisPoints > 10_000 {
+			return r, invalidFeeRule("basis_points must be between 1 and 10000")
+		}
+	case FeeTiered:
+		if len(r.Tiers) == 0 {
+			return r, invalidFeeRule("a tiered fee needs at least one tier")
+		}
+		var last int64
+		for i, t := range r.Tiers {
+			if t.Fee < 0 {
+				return r, invalidFeeRule("tier %d has a negative fee", i)
+			}
+			if t.UpTo == nil {
+				if i != len(r.Tiers)-1 {
+					return r, invalidFeeRule("only the last tier may be open-ended")
+				}
+				continue
+			}
+			if *t.UpTo <= last {
+				return r, invalidFeeRule("tier bounds must be positive and ascending")
+			}
+			last = *t.UpTo
+		}
+	default:
+		return r, invalidFeeRule("kind must be %s, %s or %s", FeeFlat, FeePercentage, FeeTiered)
+	}
+
+	if (r.Min != nil && *r.Min < 0) || (r.Max != nil && *r.Max < 0) {
+		return r, invalidFeeRule("min and max cannot be negative")
+	}
+	if r.Min != nil && r.Max != nil && *r.Min > *r.Max {
+		return r, invalidFeeRule("min is greater than max")
+	}
+
+	for i, tier := range r.WaivedTiers {
+		r.WaivedTiers[i] = strings.ToLower(strings.TrimSpace(tier))
+	}
+
+	return r, nil
+}
+
+// Compute returns the fee the rule charges on amount, clamped to Min and
+// Max. Percentages round half up to the cent. An amount above every tier
+// bound of a closed tiered rule is charged nothing.
+func (r *FeeRule) Compute(amount int64) int64 {
+	var fee int64
+	switch r.Kind {
+	case FeeFlat:
+		fee = r.Amount
+	case FeePercentage:
+		fee = (amount*r.BasisPoints + 5_000) / 10_000
+	case FeeTiered:
+		for _, t := range r.Tiers {
+			if t.UpTo == nil || amount <= *t.UpTo {
+				fee = t.Fee
+				break
+			}
+		}
+	}
+
+	if r.Min != nil && fee < *r.Min {
+		fee = *r.Min
+	}
+	if r.Max != nil && fee > *r.Max {
+		fee = *r.Max
+	}
+	return fee
+}
+
+// waives reports whether accounts of tier are exempt from the rule.
+func (r *FeeRule) waives(tier string) bool {
+	return slices.Contains(r.WaivedTiers, strings.ToLower(tier))
+}
+
+// quoteFees evaluates the enabled rules for a debit of amount from account.
+// It reads the rules through db, so the fees match what the transaction
+// that posts the debit sees.
+func quoteFees(ctx context.Context, db database.DBTX, op FeeOperation, account *pb.GetAccountResponse, currency string, amount int64) ([]Fee, error) {
+	rules, err := NewPostgresFeeRuleRepo(db).Enabled(ctx, op, currency)
+	if err != nil {
+		return nil, err
+	}
+
+	fees := []Fee{}
+	for _, rule := range rules {
+		if rule.waives(account.GetTier()) {
+			continue
+		}
+
+		if fee := rule.Compute(amount); fee > 0 {
+			fees = append(fees, Fee{RuleID: rule.ID, Name: rule.Name, Amount: fee, Currency: currency})
+		}
+	}
+	return fees, nil
+}
+
+func totalFees(fees []Fee) int64 {
+	var total int64
+	for _, f := range fees {
+		total += f.Amount
+	}
+	return total
+}
+
+// postFees charges every fee as its own FEE entry against the account of
+// debit, linked to it through FeeFor and credited to fee income.
+func postFees(ctx context.Context, db database.DBTX, debit *Transaction, fees []Fee) error {
+	transactionRepo := NewPostgresRepo(db)
+	journalRepo := NewPostgresJournalRepo(db)
+	outboxRepo := NewPostgresOutboxRepository(db)
+
+	for i := range fees {
+		fee := &fees[i]
+
+		journal := feeJournal(debit.AccountID, fee.Amount, fee.Currency, fee.Name)
+		if err := journalRepo.Create(ctx, journal); err != nil {
+			return err
+		}
+
+		entry := &Transaction{
+			AccountID: debit.AccountID,
+			JournalID: journal.ID,
+			Type:      TypeFee,
+			Amount:    fee.Amount,
+			Currency:  fee.Currency,
+			FeeFor:    debit.ID,
+			Note:      fee.Name,
+		}
+		if err := transactionRepo.Create(ctx, entry); err != nil {
+			return err
+		}
+		fee.TransactionID = entry.ID
+
+		payload, err := json.Marshal(map[string]interface{}{
+			"account_id": debit.AccountID,
+			"amount":     fee.Amount,
+			"currency":   fee.Currency,
+			"type":       "fee",
+			"reason":     "rule",
+			"rule_id":    fee.RuleID,
+			"fee_for":    debit.ID,
+			"note":       fee.Name,
+			"journal_id": journal.ID,
+		})
+		if err != nil {
+			return err
+		}
+
+		if err := outboxRepo.Add(ctx, &OutboxEvent{
+			ID:            uuid.New(),
+			AggregateType: "account",
+			AggregateID:   debit.AccountID,
+			EventType:     "transaction.created",
+			Payload:       payload,
+		}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *Service) ListFeeRules(ctx context.Context) ([]FeeRule, error) {
+	return s.feeRuleRepo.List(ctx)
+}
+
+func (s *Service) GetFeeRule(ctx context.Context, id int64) (*FeeRule, error) {
+	return s.feeRuleRepo.GetByID(ctx, id)
+}
+
+func (s *Service) CreateFeeRule(ctx context.Context, rule *FeeRule) error {
+	normalized, err := rule.normalize()
+	if err != nil {
+		return err
+	}
+	*rule = normalized
+
+	return s.feeRuleRepo.Create(ctx, rule)
+}
+
+func (s *Service) UpdateFeeRule(ctx context.Context, rule *FeeRule) error {
+	normalized, err := rule.normalize()
+	if err != nil {
+		return err
+	}
+	*rule = normalized
+
+	return s.feeRuleRepo.Update(ctx, rule)
+}
+
+func (s *Service) DeleteFeeRule(ctx context.Context, id int64) error {
+	return s.feeRuleRepo.Delete(ctx, id)
+}
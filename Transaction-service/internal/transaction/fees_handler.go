@@ -0,0 +1,113 @@
+package transaction
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi"
+)
+
+func feeRuleID(w http.ResponseWriter, r *http.Request) (int64, bool) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "ruleID"), 10, 64)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "INVALID_FEE_RULE_ID", "Fee rule ID must be a number")
+		return 0, false
+	}
+	return id, true
+}
+
+func (h *TransactionHandler) ListFeeRules(w http.ResponseWriter, r *http.Request) {
+	rules, err := h.service.ListFeeRules(r.Context())
+	if err != nil {
+		respondServiceError(w, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, SuccessResponse{
+		Status:  "success",
+		Message: "Fee rules retrieved successfully",
+		Data:    rules,
+	})
+}
+
+func (h *TransactionHandler) GetFeeRule(w http.ResponseWriter, r *http.Request) {
+	id, ok := feeRuleID(w, r)
+	if !ok {
+		return
+	}
+
+	rule, err := h.service.GetFeeRule(r.Context(), id)
+	if err != nil {
+		respondServiceError(w, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, SuccessResponse{
+		Status:  "success",
+		Message: "Fee rule retrieved successfully",
+		Data:    rule,
+	})
+}
+
+func (h *TransactionHandler) CreateFeeRule(w http.ResponseWriter, r *http.Request) {
+	var rule FeeRule
+	if err := json.NewDecoder(r.Body).Decode(&rule); err != nil {
+		respondError(w, http.StatusBadRequest, "INVALID_REQUEST", "Invalid JSON payload")
+		return
+	}
+
+	if err := h.service.CreateFeeRule(r.Context(), &rule); err != nil {
+		respondServiceError(w, err)
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, SuccessResponse{
+		Status:  "success",
+		Message: "Fee rule created successfully",
+		Data:    rule,
+	})
+}
+
+// UpdateFeeRule replaces every field of the rule.
+func (h *TransactionHandler) UpdateFeeRule(w http.ResponseWriter, r *http.Request) {
+	id, ok := feeRuleID(w, r)
+	if !ok {
+		return
+	}
+
+	var rule FeeRule
+	if err := json.NewDecoder(r.Body).Decode(&rule); err != nil {
+		respondError(w, http.StatusBadRequest, "INVALID_REQUEST", "Invalid JSON payload")
+		return
+	}
+	rule.ID = id
+
+	if err := h.service.UpdateFeeRule(r.Context(), &rule); err != nil {
+		respondServiceError(w, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, SuccessResponse{
+		Status:  "success",
+		Message: "Fee rule updated successfully",
+		Data:    rule,
+	})
+}
+
+func (h *TransactionHandler) DeleteFeeRule(w http.ResponseWriter, r *http.Request) {
+	id, ok := feeRuleID(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeleteFeeRule(r.Context(), id); err != nil {
+		respondServiceError(w, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, SuccessResponse{
+		Status:  "success",
+		Message: "Fee rule deleted successfully",
+	})
+}
@@ -0,0 +1,187 @@
+package transaction
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"transaction/internal/infrastructure/database"
+
+	"github.com/lib/pq"
+)
+
+var _ FeeRuleRepository = (*PostgresFeeRuleRepo)(nil)
+
+const feeRuleColumns = `id, name, operation, COALESCE(currency, ''), kind, amount, basis_points, tiers,
+	min_amount, max_amount, waived_tiers, disabled, created_at, updated_at`
+
+type PostgresFeeRuleRepo struct {
+	db database.DBTX
+}
+
+func NewPostgresFeeRuleRepo(db database.DBTX) *PostgresFeeRuleRepo {
+	return &PostgresFeeRuleRepo{db: db}
+}
+
+func scanFeeRule(row rowScanner) (*FeeRule, error) {
+	var (
+		rule     FeeRule
+		tiers    []byte
+		min, max sql.NullInt64
+	)
+	err := row.Scan(
+		&rule.ID,
+		&rule.Name,
+		&rule.Operation,
+		&rule.Currency,
+		&rule.Kind,
+		&rule.Amount,
+		&rule.BasisPoints,
+		&tiers,
+		&min,
+		&max,
+		(*pq.StringArray)(&rule.WaivedTiers),
+		&rule.Disabled,
+		&rule.CreatedAt,
+		&rule.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if min.Valid {
+		rule.Min = &min.Int64
+	}
+	if max.Valid {
+		rule.Max = &max.Int64
+	}
+
+	return &rule, json.Unmarshal(tiers, &rule.Tiers)
+}
+
+// feeRuleArgs returns the stored columns of rule from name to disabled.
+func feeRuleArgs(rule *FeeRule) ([]any, error) {
+	tiers := []FeeTier{}
+	if rule.Tiers != nil {
+		tiers = rule.Tiers
+	}
+	rawTiers, err := json.Marshal(tiers)
+	if err != nil {
+		return nil, err
+	}
+
+	waived := []string{}
+	if rule.WaivedTiers != nil {
+		waived = rule.WaivedTiers
+	}
+
+	return []any{
+		rule.Name,
+		rule.Operation,
+		sql.NullString{String: rule.Currency, Valid: rule.Currency != ""},
+		rule.Kind,
+		rule.Amount,
+		rule.BasisPoints,
+		string(rawTiers),
+		nullLimit(rule.Min),
+		nullLimit(rule.Max),
+		pq.Array(waived),
+		rule.Disabled,
+	}, nil
+}
+
+func (r *PostgresFeeRuleRepo) Create(ctx context.Context, rule *FeeRule) error {
+	args, err := feeRuleArgs(rule)
+	if err != nil {
+		return err
+	}
+
+	return r.db.QueryRowContext(ctx, `
+		INSERT INTO fee_rules (name, operation, currency, kind, amount, basis_points, tiers,
+			min_amount, max_amount, waived_tiers, disabled)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		RETURNING id, created_at, updated_at
+	`, args...).Scan(&rule.ID, &rule.CreatedAt, &rule.UpdatedAt)
+}
+
+func (r *PostgresFeeRuleRepo) Update(ctx context.Context, rule *FeeRule) error {
+	args, err := feeRuleArgs(rule)
+	if err != nil {
+		return err
+	}
+
+	err = r.db.QueryRowContext(ctx, `
+		UPDATE fee_rules
+		SET name = $1, operation = $2, currency = $3, kind = $4, amount = $5, basis_points = $6,
+		    tiers = $7, min_amount = $8, max_amount = $9, waived_tiers = $10, disabled = $11,
+		    updated_at = now()
+		WHERE id = $12
+		RETURNING created_at, updated_at
+	`, append(args, rule.ID)...).Scan(&rule.CreatedAt, &rule.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return ErrFeeRuleNotFound
+	}
+	return err
+}
+
+func (r *PostgresFeeRuleRepo) Delete(ctx context.Context, id int64) error {
+	res, err := r.db.ExecContext(ctx, `DELETE FROM fee_rules WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrFeeRuleNotFound
+	}
+	return nil
+}
+
+func (r *PostgresFeeRuleRepo) GetByID(ctx context.Context, id int64) (*FeeRule, error) {
+	rule, err := scanFeeRule(r.db.QueryRowContext(ctx, `
+		SELECT `+feeRuleColumns+`
+		FROM fee_rules
+		WHERE id = $1
+	`, id))
+	if err == sql.ErrNoRows {
+		return nil, ErrFeeRuleNotFound
+	}
+	return rule, err
+}
+
+func (r *PostgresFeeRuleRepo) List(ctx context.Context) ([]FeeRule, error) {
+	return r.list(ctx, `
+		SELECT `+feeRuleColumns+`
+		FROM fee_rules
+		ORDER BY id
+	`)
+}
+
+func (r *PostgresFeeRuleRepo) Enabled(ctx context.Context, op FeeOperation, currency string) ([]FeeRule, error) {
+	return r.list(ctx, `
+		SELECT `+feeRuleColumns+`
+		FROM fee_rules
+		WHERE NOT disabled AND operation = $1 AND (currency IS NULL OR currency = $2)
+		ORDER BY id
+	`, op, currency)
+}
+
+func (r *PostgresFeeRuleRepo) list(ctx context.Context, query string, args ...any) ([]FeeRule, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	rules := []FeeRule{}
+	for rows.Next() {
+		rule, err := scanFeeRule(rows)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, *rule)
+	}
+	return rules, rows.Err()
+}
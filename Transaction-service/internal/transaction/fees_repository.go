@@ -0,0 +1,14 @@
+package transaction
+
+import "context"
+
+type FeeRuleRepository interface {
+	Create(ctx context.Context, rule *FeeRule) error
+	Update(ctx context.Context, rule *FeeRule) error
+	Delete(ctx context.Context, id int64) error
+	GetByID(ctx context.Context, id int64) (*FeeRule, error)
+	List(ctx context.Context) ([]FeeRule, error)
+	// Enabled lists the enabled rules for op that apply to currency, in ID
+	// order.
+	Enabled(ctx context.Context, op FeeOperation, currency string) ([]FeeRule, error)
+}
@@ -0,0 +1,205 @@
+package transaction_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+	"transaction/internal/transaction"
+)
+
+func ptr(v int64) *int64 { return &v }
+
+func TestFeeRule_Compute(t *testing.T) {
+	tests := []struct {
+		name   string
+		rule   transaction.FeeRule
+		amount int64
+		want   int64
+	}{
+		{"flat", transaction.FeeRule{Kind: transaction.FeeFlat, Amount: 150}, 10_000, 150},
+		{"percentage rounds half up", transaction.FeeRule{Kind: transaction.FeePercentage, BasisPoints: 150}, 1_010, 15},
+		{"percentage below min", transaction.FeeRule{Kind: transaction.FeePercentage, BasisPoints: 100, Min: ptr(50)}, 1_000, 50},
+		{"percentage above max", transaction.FeeRule{Kind: transaction.FeePercentage, BasisPoints: 100, Max: ptr(500)}, 100_000, 500},
+		{"first tier", tiered(), 1_000, 0},
+		{"tier bound is inclusive", tiered(), 10_000, 0},
+		{"middle tier", tiered(), 10_001, 200},
+		{"open-ended tier", tiered(), 1_000_000, 500},
+		{"above a closed last tier", transaction.FeeRule{Kind: transaction.FeeTiered, Tiers: []transaction.FeeTier{{UpTo: ptr(100), Fee: 10}}}, 101, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.rule.Compute(tt.amount); got != tt.want {
+				t.Fatalf("expected %d, got %d", tt.want, got)
+			}
+		})
+	}
+}
+
+func tiered() transaction.FeeRule {
+	return transaction.FeeRule{Kind: transaction.FeeTiered, Tiers: []transaction.FeeTier{
+		{UpTo: ptr(10_000), Fee: 0},
+		{UpTo: ptr(100_000), Fee: 200},
+		{Fee: 500},
+	}}
+}
+
+func TestFeeRule_RejectsInvalid(t *testing.T) {
+	// rules are validated before they are stored
+	service := transaction.NewTransactionService(nil, nil, nil)
+
+	for name, rule := range map[string]transaction.FeeRule{
+		"no name":        {Operation: transaction.FeeOnWithdraw, Kind: transaction.FeeFlat, Amount: 100},
+		"bad operation":  {Name: "x", Operation: "DEPOSIT", Kind: transaction.FeeFlat, Amount: 100},
+		"zero flat":      {Name: "x", Operation: transaction.FeeOnWithdraw, Kind: transaction.FeeFlat},
+		"over 100%":      {Name: "x", Operation: transaction.FeeOnWithdraw, Kind: transaction.FeePercentage, BasisPoints: 10_001},
+		"min above max":  {Name: "x", Operation: transaction.FeeOnWithdraw, Kind: transaction.FeePercentage, BasisPoints: 100, Min: ptr(10), Max: ptr(5)},
+		"unordered tier": {Name: "x", Operation: transaction.FeeOnTransfer, Kind: transaction.FeeTiered, Tiers: []transaction.FeeTier{{UpTo: ptr(100)}, {UpTo: ptr(50)}}},
+		"open mid tier":  {Name: "x", Operation: transaction.FeeOnTransfer, Kind: transaction.FeeTiered, Tiers: []transaction.FeeTier{{Fee: 1}, {UpTo: ptr(50)}}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			if err := service.CreateFeeRule(context.Background(), &rule); !errors.Is(err, transaction.ErrInvalidFeeRule) {
+				t.Fatalf("expected ErrInvalidFeeRule, got %v", err)
+			}
+		})
+	}
+}
+
+func TestFees_ChargedWithWithdrawAndTransfer(t *testing.T) {
+	db := setupTestDB(t)
+	ctx := context.Background()
+
+	txRepo := transaction.NewPostgresRepo(db)
+	accounts := &MockAccountClient{Tiers: map[int64]string{}}
+	service := transaction.NewTransactionService(db, accounts, txRepo)
+
+	customer := createTestAccount(t, db, "Customer")
+	premium := createTestAccount(t, db, "Premium")
+	accounts.Tiers[premium.ID] = "premium"
+
+	for _, rule := range []transaction.FeeRule{
+		{Name: "ATM fee", Operation: transaction.FeeOnWithdraw, Kind: transaction.FeeFlat, Amount: 250},
+		{Name: "Transfer fee", Operation: transaction.FeeOnTransfer, Kind: transaction.FeePercentage, BasisPoints: 100, Min: ptr(50), WaivedTiers: []string{"Premium"}},
+		{Name: "Euro transfer fee", Operation: transaction.FeeOnTransfer, Currency: "EUR", Kind: transaction.FeeFlat, Amount: 1_000},
+	} {
+		if err := service.CreateFeeRule(ctx, &rule); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, id := range []int64{customer.ID, premium.ID} {
+		if err := service.Deposit(ctx, "", id, 10_000, "USD", "funding", transaction.Labels{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	fees, err := service.Withdraw(ctx, "", customer.ID, 2_000, "USD", "atm", transaction.Labels{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(fees) != 1 || fees[0].Amount != 250 || fees[0].TransactionID == 0 {
+		t.Fatalf("expected the ATM fee, got %+v", fees)
+	}
+
+	fees, err = service.Transfer(ctx, "", customer.ID, premium.ID, 3_000, "USD", "rent", transaction.Labels{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(fees) != 1 || fees[0].Amount != 50 {
+		t.Fatalf("expected the minimum transfer fee, got %+v", fees)
+	}
+
+	// premium accounts are waived
+	fees, err = service.Transfer(ctx, "", premium.ID, customer.ID, 3_000, "USD", "refund", transaction.Labels{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(fees) != 0 {
+		t.Fatalf("expected no fees, got %+v", fees)
+	}
+
+	// 10000 - 2000 - 250 - 3000 - 50 + 3000
+	balance, err := txRepo.BalanceOf(ctx, customer.ID, "USD")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if balance != 7_700 {
+		t.Fatalf("expected 7700, got %d", balance)
+	}
+
+	page, err := service.History(ctx, customer.ID, transaction.HistoryFilter{Types: []transaction.Type{transaction.TypeFee}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(page.Entries) != 2 || page.Entries[0].FeeFor == 0 || page.Entries[1].FeeFor == 0 {
+		t.Fatalf("expected 2 linked fee entries, got %+v", page.Entries)
+	}
+
+	// the fee must be covered as well as the amount
+	if _, err := service.Withdraw(ctx, "", customer.ID, 7_500, "USD", "everything", transaction.Labels{}); !errors.Is(err, transaction.ErrInsufficientFunds) {
+		t.Fatalf("expected ErrInsufficientFunds, got %v", err)
+	}
+}
+
+func TestFees_ChargedOnBatchLegsAndCapture(t *testing.T) {
+	db := setupTestDB(t)
+	ctx := context.Background()
+
+	txRepo := transaction.NewPostgresRepo(db)
+	service := transaction.NewTransactionService(db, &MockAccountClient{}, txRepo)
+
+	payer := createTestAccount(t, db, "Payer")
+	alice := createTestAccount(t, db, "Alice")
+	bob := createTestAccount(t, db, "Bob")
+
+	for _, rule := range []transaction.FeeRule{
+		{Name: "Card fee", Operation: transaction.FeeOnWithdraw, Kind: transaction.FeeFlat, Amount: 100},
+		{Name: "Transfer fee", Operation: transaction.FeeOnTransfer, Kind: transaction.FeeFlat, Amount: 50},
+	} {
+		if err := service.CreateFeeRule(ctx, &rule); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := service.Deposit(ctx, "", payer.ID, 10_000, "USD", "funding", transaction.Labels{}); err != nil {
+		t.Fatal(err)
+	}
+
+	// each leg is charged like a transfer of its own
+	result, err := service.BatchTransfer(ctx, "", []transaction.TransferLeg{
+		{FromAccountID: payer.ID, ToAccountID: alice.ID, Amount: 2_000},
+		{FromAccountID: payer.ID, ToAccountID: bob.ID, Amount: 1_000},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Fees) != 2 || result.Fees[0].Amount != 50 || result.Fees[1].Amount != 50 {
+		t.Fatalf("expected a transfer fee per leg, got %+v", result.Fees)
+	}
+
+	hold, err := service.PlaceHold(ctx, payer.ID, 3_000, "USD", time.Hour, "hotel")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	captured, err := service.CaptureHold(ctx, hold.ID, 0, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(captured.Fees) != 1 || captured.Fees[0].Amount != 100 {
+		t.Fatalf("expected the card fee on capture, got %+v", captured.Fees)
+	}
+
+	// 10000 - 2000 - 50 - 1000 - 50 - 3000 - 100
+	assertBalance(t, service, payer.ID, 3_800)
+
+	// the fees of every leg must be covered too
+	_, err = service.BatchTransfer(ctx, "", []transaction.TransferLeg{
+		{FromAccountID: payer.ID, ToAccountID: alice.ID, Amount: 1_900},
+		{FromAccountID: payer.ID, ToAccountID: bob.ID, Amount: 1_900},
+	})
+	if !errors.Is(err, transaction.ErrInsufficientFunds) {
+		t.Fatalf("expected ErrInsufficientFunds, got %v", err)
+	}
+}
@@ -2,8 +2,12 @@ package transaction
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
 	//"strconv"
 
@@ -11,11 +15,12 @@ import (
 )
 
 type TransactionHandler struct {
-	service TransactionService
+	service     TransactionService
+	idempotency IdempotencyRepository
 }
 
-func NewTransactionHandler(service TransactionService) *TransactionHandler {
-	return &TransactionHandler{service: service}
+func NewTransactionHandler(service TransactionService, idempotency IdempotencyRepository) *TransactionHandler {
+	return &TransactionHandler{service: service, idempotency: idempotency}
 }
 
 func (h *TransactionHandler) Deposit(w http.ResponseWriter, r *http.Request) {
@@ -25,7 +30,9 @@ func (h *TransactionHandler) Deposit(w http.ResponseWriter, r *http.Request) {
 	var req struct {
 		AccountID int64  `json:"account_id"`
 		Amount    int64  `json:"amount"`
+		Currency  string `json:"currency"`
 		Note      string `json:"note"`
+		Labels
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -43,9 +50,11 @@ func (h *TransactionHandler) Deposit(w http.ResponseWriter, r *http.Request) {
 		key,
 		req.AccountID,
 		req.Amount,
+		req.Currency,
 		req.Note,
+		req.Labels,
 	); err != nil {
-		respondError(w, http.StatusBadRequest, "DEPOSIT_FAILED", err.Error())
+		respondServiceError(w, err)
 		return
 	}
 
@@ -59,7 +68,9 @@ func (h *TransactionHandler) Withdraw(w http.ResponseWriter, r *http.Request) {
 	var req struct {
 		AccountID int64  `json:"account_id"`
 		Amount    int64  `json:"amount"`
+		Currency  string `json:"currency"`
 		Note      string `json:"note"`
+		Labels
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -74,27 +85,29 @@ func (h *TransactionHandler) Withdraw(w http.ResponseWriter, r *http.Request) {
 
 	key := r.Header.Get("Idempotency-Key")
 
-	if err := h.service.Withdraw(r.Context(), key, req.AccountID, req.Amount, req.Note); err != nil {
-		code := "WITHDRAW_FAILED"
-		if err.Error() == "insufficient funds" {
-			code = "INSUFFICIENT_FUNDS"
-		}
-		respondError(w, http.StatusBadRequest, code, err.Error())
+	fees, err := h.service.Withdraw(r.Context(), key, req.AccountID, req.Amount, req.Currency, req.Note, req.Labels)
+	if err != nil {
+		respondServiceError(w, err)
 		return
 	}
 
 	respondJSON(w, http.StatusCreated, SuccessResponse{
 		Status:  "success",
 		Message: "Withdrawal completed successfully",
+		Data:    map[string][]Fee{"fees": fees},
 	})
 }
 
 func (h *TransactionHandler) Transfer(w http.ResponseWriter, r *http.Request) {
+	key := r.Header.Get("Idempotency-Key")
+
 	var req struct {
 		FromAccountID int64  `json:"from_account_id"`
 		ToAccountID   int64  `json:"to_account_id"`
 		Amount        int64  `json:"amount"`
+		Currency      string `json:"currency"`
 		Note          string `json:"note"`
+		Labels
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -103,7 +116,7 @@ func (h *TransactionHandler) Transfer(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if req.FromAccountID == req.ToAccountID {
-		respondError(w, http.StatusBadRequest, "INVALID_TRANSFER", "Cannot transfer to the same account")
+		respondServiceError(w, ErrSameAccount)
 		return
 	}
 
@@ -112,20 +125,88 @@ func (h *TransactionHandler) Transfer(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := h.service.Transfer(
+	fees, err := h.service.Transfer(
 		r.Context(),
+		key,
 		req.FromAccountID,
 		req.ToAccountID,
 		req.Amount,
+		req.Currency,
 		req.Note,
-	); err != nil {
-		respondError(w, http.StatusBadRequest, "TRANSFER_FAILED", err.Error())
+		req.Labels,
+	)
+	if err != nil {
+		respondServiceError(w, err)
 		return
 	}
 
 	respondJSON(w, http.StatusCreated, SuccessResponse{
 		Status:  "success",
 		Message: "Transfer completed successfully",
+		Data:    map[string][]Fee{"fees": fees},
+	})
+}
+
+func (h *TransactionHandler) BatchTransfer(w http.ResponseWriter, r *http.Request) {
+	key := r.Header.Get("Idempotency-Key")
+
+	var req struct {
+		Transfers []TransferLeg `json:"transfers"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "INVALID_REQUEST", "Invalid JSON payload")
+		return
+	}
+
+	result, err := h.service.BatchTransfer(r.Context(), key, req.Transfers)
+	if err != nil {
+		respondServiceError(w, err)
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, SuccessResponse{
+		Status:  "success",
+		Message: "Batch transfer completed successfully",
+		Data:    result,
+	})
+}
+
+func (h *TransactionHandler) Reverse(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "INVALID_TRANSACTION_ID", "Transaction ID must be a number")
+		return
+	}
+
+	// amount is optional; omitting it reverses whatever is left
+	var req struct {
+		Amount int64  `json:"amount"`
+		Note   string `json:"note"`
+	}
+
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			respondError(w, http.StatusBadRequest, "INVALID_REQUEST", "Invalid JSON payload")
+			return
+		}
+	}
+
+	if req.Amount < 0 {
+		respondError(w, http.StatusBadRequest, "INVALID_AMOUNT", "Refund amount must be greater than zero")
+		return
+	}
+
+	journal, err := h.service.Reverse(r.Context(), id, req.Amount, req.Note)
+	if err != nil {
+		respondServiceError(w, err)
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, SuccessResponse{
+		Status:  "success",
+		Message: "Transaction reversed successfully",
+		Data:    journal,
 	})
 }
 
@@ -136,44 +217,205 @@ func (h *TransactionHandler) History(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	entries, err := h.service.History(r.Context(), id)
+	filter, err := parseHistoryFilter(r)
 	if err != nil {
-		respondError(w, http.StatusNotFound, "ACCOUNT_NOT_FOUND", "Account not found")
+		respondError(w, http.StatusBadRequest, "INVALID_QUERY", err.Error())
+		return
+	}
+
+	page, err := h.service.History(r.Context(), id, filter)
+	if err != nil {
+		respondServiceError(w, err)
 		return
 	}
 
 	respondJSON(w, http.StatusOK, SuccessResponse{
 		Status:  "success",
 		Message: "Transaction history retrieved successfully",
-		Data:    entries,
+		Data:    page,
 	})
 }
 
+// parseHistoryFilter reads the history query string:
+//
+//	cursor, limit, type (repeatable or comma separated), from, to, as_of,
+//	min_amount, max_amount, note, tag (repeatable), metadata.<key>
+//
+// from/to/as_of accept RFC 3339 timestamps or YYYY-MM-DD dates; a date-only
+// "to" or "as_of" includes that whole day.
+func parseHistoryFilter(r *http.Request) (HistoryFilter, error) {
+	q := r.URL.Query()
+	filter := HistoryFilter{
+		Cursor:       q.Get("cursor"),
+		NoteContains: q.Get("note"),
+	}
+
+	if v := q.Get("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit <= 0 {
+			return filter, errors.New("limit must be a positive number")
+		}
+		filter.Limit = limit
+	}
+
+	for _, v := range q["type"] {
+		for _, t := range strings.Split(v, ",") {
+			t := Type(strings.ToUpper(strings.TrimSpace(t)))
+			if !t.Valid() {
+				return filter, fmt.Errorf("unknown transaction type %q", t)
+			}
+			filter.Types = append(filter.Types, t)
+		}
+	}
+
+	if v := q.Get("from"); v != "" {
+		from, _, err := parseHistoryTime(v)
+		if err != nil {
+			return filter, errors.New("from must be an RFC 3339 timestamp or YYYY-MM-DD date")
+		}
+		filter.From = &from
+	}
+
+	if v := q.Get("to"); v != "" {
+		to, dateOnly, err := parseHistoryTime(v)
+		if err != nil {
+			return filter, errors.New("to must be an RFC 3339 timestamp or YYYY-MM-DD date")
+		}
+		if dateOnly {
+			to = to.AddDate(0, 0, 1)
+		}
+		filter.To = &to
+	}
+
+	if v := q.Get("as_of"); v != "" {
+		asOf, err := parseAsOf(v)
+		if err != nil {
+			return filter, err
+		}
+		filter.AsOf = &asOf
+	}
+
+	for _, tag := range q["tag"] {
+		filter.Tags = append(filter.Tags, strings.ToLower(strings.TrimSpace(tag)))
+	}
+
+	for name, values := range q {
+		if key, ok := strings.CutPrefix(name, "metadata."); ok {
+			if filter.Metadata == nil {
+				filter.Metadata = map[string]string{}
+			}
+			filter.Metadata[key] = values[0]
+		}
+	}
+
+	for name, dst := range map[string]**int64{
+		"min_amount": &filter.MinAmount,
+		"max_amount": &filter.MaxAmount,
+	} {
+		if v := q.Get(name); v != "" {
+			amount, err := strconv.ParseInt(v, 10, 64)
+			if err != nil {
+				return filter, fmt.Errorf("%s must be a number of cents", name)
+			}
+			*dst = &amount
+		}
+	}
+
+	return filter, nil
+}
+
+// parseAsOf reads an inclusive as_of bound. A date means the end of that
+// day; timestamps are stored to the microsecond.
+func parseAsOf(v string) (time.Time, error) {
+	asOf, dateOnly, err := parseHistoryTime(v)
+	if err != nil {
+		return asOf, errors.New("as_of must be an RFC 3339 timestamp or YYYY-MM-DD date")
+	}
+	if dateOnly {
+		asOf = asOf.AddDate(0, 0, 1).Add(-time.Microsecond)
+	}
+	return asOf, nil
+}
+
+func parseHistoryTime(v string) (time.Time, bool, error) {
+	if t, err := time.Parse("2006-01-02", v); err == nil {
+		return t, true, nil
+	}
+	t, err := time.Parse(time.RFC3339, v)
+	return t.UTC(), false, err
+}
+
 func (h *TransactionHandler) Routes() http.Handler {
 	r := chi.NewRouter()
-	r.Post("/deposit", h.Deposit)
-	r.Post("/withdraw", h.Withdraw)
-	r.Post("/transfer", h.Transfer)
+	r.Post("/deposit", h.idempotent("deposit", h.Deposit))
+	r.Post("/withdraw", h.idempotent("withdraw", h.Withdraw))
+	r.Post("/transfer", h.idempotent("transfer", h.Transfer))
+	r.Post("/batch", h.idempotent("batch_transfer", h.BatchTransfer))
 	r.Get("/history/{id}", h.History)
 	r.Get("/{id}/balance", h.Balance)
+	r.Get("/{id}/export", h.Export)
+	r.Post("/{id}/reverse", h.Reverse)
+	r.Post("/holds", h.PlaceHold)
+	r.Get("/holds/{holdID}", h.GetHold)
+	r.Post("/holds/{holdID}/capture", h.CaptureHold)
+	r.Post("/holds/{holdID}/release", h.ReleaseHold)
+	r.Get("/limits/global", h.GetLimits)
+	r.Put("/limits/global", h.SetLimits)
+	r.Get("/limits/tiers/{tier}", h.GetLimits)
+	r.Put("/limits/tiers/{tier}", h.SetLimits)
+	r.Get("/limits/accounts/{accountID}", h.GetLimits)
+	r.Put("/limits/accounts/{accountID}", h.SetLimits)
+	r.Get("/fees/rules", h.ListFeeRules)
+	r.Post("/fees/rules", h.CreateFeeRule)
+	r.Get("/fees/rules/{ruleID}", h.GetFeeRule)
+	r.Put("/fees/rules/{ruleID}", h.UpdateFeeRule)
+	r.Delete("/fees/rules/{ruleID}", h.DeleteFeeRule)
+	r.Get("/interest/rates", h.ListInterestRates)
+	r.Put("/interest/rates/{tier}/{currency}", h.SetInterestRate)
+	r.Delete("/interest/rates/{tier}/{currency}", h.DeleteInterestRate)
+	r.Get("/reconciliation", h.Reconcile)
+	r.Post("/reconciliation/backfill", h.BackfillEvents)
+	r.Get("/chain/key", h.ChainKey)
+	r.Get("/{id}/chain/verify", h.VerifyChain)
+	r.Get("/{id}/chain/checkpoints", h.ChainCheckpoints)
 	return r
 }
 
 func (h *TransactionHandler) Balance(w http.ResponseWriter, r *http.Request) {
 	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
 	if err != nil {
-		http.Error(w, "ivalid id", http.StatusBadRequest)
+		respondError(w, http.StatusBadRequest, "INVALID_ACCOUNT_ID", "Account ID must be a number")
+		return
+	}
+
+	if v := r.URL.Query().Get("as_of"); v != "" {
+		asOf, err := parseAsOf(v)
+		if err != nil {
+			respondError(w, http.StatusBadRequest, "INVALID_QUERY", err.Error())
+			return
+		}
+
+		balances, err := h.service.BalanceAsOf(r.Context(), id, asOf)
+		if err != nil {
+			respondServiceError(w, err)
+			return
+		}
+
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"as_of":    asOf,
+			"balances": balances,
+		})
 		return
 	}
 
-	balance, err := h.service.Balance(r.Context(), id)
+	balances, err := h.service.Balance(r.Context(), id)
 	if err != nil {
-		http.Error(w, "account not found", http.StatusNotFound)
+		respondServiceError(w, err)
 		return
 	}
 
-	json.NewEncoder(w).Encode(map[string]int64{
-		"balance": balance,
+	json.NewEncoder(w).Encode(map[string][]Balance{
+		"balances": balances,
 	})
 }
 
@@ -186,11 +428,22 @@ type SuccessResponse struct {
 type ErrorResponse struct {
 	Status string `json:"status"`
 	Error  struct {
-		Code    string `json:"code"`
-		Message string `json:"message"`
+		Code    string      `json:"code"`
+		Message string      `json:"message"`
+		Details interface{} `json:"details,omitempty"`
 	} `json:"error"`
 }
 
+// RespondJSON and RespondError write the API envelope for handlers outside
+// this package.
+func RespondJSON(w http.ResponseWriter, status int, payload interface{}) {
+	respondJSON(w, status, payload)
+}
+
+func RespondError(w http.ResponseWriter, status int, code, message string) {
+	respondError(w, status, code, message)
+}
+
 func respondJSON(w http.ResponseWriter, status int, payload interface{}) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(status)
@@ -0,0 +1,91 @@
+package transaction
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	DefaultHistoryLimit = 50
+	MaxHistoryLimit     = 200
+)
+
+var ErrInvalidCursor = errors.New("invalid history cursor")
+
+// HistoryFilter narrows and pages an account's history. Zero values mean
+// "no filter". From is inclusive and To is exclusive.
+type HistoryFilter struct {
+	Cursor       string
+	Limit        int
+	Types        []Type
+	From         *time.Time
+	To           *time.Time
+	AsOf         *time.Time // history as it stood then, inclusive
+	MinAmount    *int64
+	MaxAmount    *int64
+	NoteContains string
+	Tags         []string          // entries carrying every tag
+	Metadata     map[string]string // entries whose metadata has every pair
+}
+
+type HistoryPage struct {
+	Entries    []Transaction       `json:"entries"`
+	NextCursor string              `json:"next_cursor,omitempty"`
+	Balances   []HistoricalBalance `json:"balances_as_of,omitempty"` // set when filtering by AsOf
+}
+
+// normalize applies the default page size and caps it at MaxHistoryLimit.
+func (f HistoryFilter) normalize() HistoryFilter {
+	if f.Limit <= 0 {
+		f.Limit = DefaultHistoryLimit
+	}
+	if f.Limit > MaxHistoryLimit {
+		f.Limit = MaxHistoryLimit
+	}
+	return f
+}
+
+// historyCursor is the position of the last entry on a page. History is
+// ordered by (created_at, id) descending, so the pair is a stable key.
+type historyCursor struct {
+	CreatedAt time.Time
+	ID        int64
+}
+
+// Cursor returns the cursor that resumes history right after t.
+func (t Transaction) Cursor() string {
+	return encodeCursor(t)
+}
+
+func encodeCursor(t Transaction) string {
+	raw := fmt.Sprintf("%d:%d", t.CreatedAt.UnixNano(), t.ID)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func decodeCursor(s string) (*historyCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	parts := strings.SplitN(string(raw), ":", 2)
+	if len(parts) != 2 {
+		return nil, ErrInvalidCursor
+	}
+
+	nanos, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	id, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	return &historyCursor{CreatedAt: time.Unix(0, nanos).UTC(), ID: id}, nil
+}
@@ -2,6 +2,7 @@ package transaction_test
 
 import (
 	"context"
+	"fmt"
 	"testing"
 	"transaction/internal/transaction"
 )
@@ -15,16 +16,124 @@ func TestTransactionHistory(t *testing.T) {
 	service := transaction.NewTransactionService(db, &MockAccountClient{}, txrepo)
 
 	acc := createTestAccount(t, db, "History User")
-	service.Deposit(ctx, key, acc.ID, 3_000, "income")
-	service.Withdraw(ctx, key, acc.ID, 1_000, "expenses")
+	service.Deposit(ctx, key, acc.ID, 3_000, "USD", "income", transaction.Labels{})
+	service.Withdraw(ctx, key, acc.ID, 1_000, "USD", "expenses", transaction.Labels{})
 
-	history, err := service.History(ctx, acc.ID)
+	history, err := service.History(ctx, acc.ID, transaction.HistoryFilter{})
 	if err != nil {
 		t.Fatal(err)
 	}
 
-	if len(history) != 2 {
-		t.Fatalf("expected 2 entries, got %d", len(history))
+	if len(history.Entries) != 2 {
+		t.Fatalf("expected 2 entries, got %d", len(history.Entries))
 	}
 
 }
+
+func TestTransactionHistory_RunningBalance(t *testing.T) {
+	db := setupTestDB(t)
+	ctx := context.Background()
+
+	txrepo := transaction.NewPostgresRepo(db)
+	service := transaction.NewTransactionService(db, &MockAccountClient{}, txrepo)
+
+	acc := createTestAccount(t, db, "Running Balance User")
+	service.Deposit(ctx, "rb-1", acc.ID, 3_000, "USD", "income", transaction.Labels{})
+	service.Withdraw(ctx, "rb-2", acc.ID, 1_000, "USD", "expenses", transaction.Labels{})
+	service.Deposit(ctx, "rb-3", acc.ID, 500, "USD", "refund", transaction.Labels{})
+
+	history, err := service.History(ctx, acc.ID, transaction.HistoryFilter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// newest first
+	expected := []int64{2_500, 2_000, 3_000}
+	if len(history.Entries) != len(expected) {
+		t.Fatalf("expected %d entries, got %d", len(expected), len(history.Entries))
+	}
+
+	for i, want := range expected {
+		if history.Entries[i].BalanceAfter != want {
+			t.Errorf("entry %d: expected balance_after %d, got %d", i, want, history.Entries[i].BalanceAfter)
+		}
+	}
+
+	assertBalance(t, service, acc.ID, 2_500)
+}
+
+func TestTransactionHistory_Pagination(t *testing.T) {
+	db := setupTestDB(t)
+	ctx := context.Background()
+
+	txrepo := transaction.NewPostgresRepo(db)
+	service := transaction.NewTransactionService(db, &MockAccountClient{}, txrepo)
+
+	acc := createTestAccount(t, db, "Paging User")
+	for i := 0; i < 5; i++ {
+		service.Deposit(ctx, fmt.Sprintf("page-%d", i), acc.ID, int64(1_000*(i+1)), "USD", fmt.Sprintf("deposit %d", i), transaction.Labels{})
+	}
+
+	seen := map[int64]bool{}
+	filter := transaction.HistoryFilter{Limit: 2}
+	pages := 0
+
+	for {
+		page, err := service.History(ctx, acc.ID, filter)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pages++
+
+		for _, e := range page.Entries {
+			if seen[e.ID] {
+				t.Fatalf("entry %d returned twice", e.ID)
+			}
+			seen[e.ID] = true
+		}
+
+		if page.NextCursor == "" {
+			break
+		}
+		filter.Cursor = page.NextCursor
+	}
+
+	if pages != 3 || len(seen) != 5 {
+		t.Fatalf("expected 5 entries over 3 pages, got %d entries over %d pages", len(seen), pages)
+	}
+}
+
+func TestTransactionHistory_Filters(t *testing.T) {
+	db := setupTestDB(t)
+	ctx := context.Background()
+
+	txrepo := transaction.NewPostgresRepo(db)
+	service := transaction.NewTransactionService(db, &MockAccountClient{}, txrepo)
+
+	acc := createTestAccount(t, db, "Filter User")
+	service.Deposit(ctx, "f-1", acc.ID, 10_000, "USD", "salary March", transaction.Labels{})
+	service.Withdraw(ctx, "f-2", acc.ID, 2_000, "USD", "groceries", transaction.Labels{})
+	service.Withdraw(ctx, "f-3", acc.ID, 500, "USD", "coffee", transaction.Labels{})
+
+	minAmount := int64(1_000)
+	page, err := service.History(ctx, acc.ID, transaction.HistoryFilter{
+		Types:     []transaction.Type{transaction.TypeWithdraw},
+		MinAmount: &minAmount,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(page.Entries) != 1 || page.Entries[0].Note != "groceries" {
+		t.Fatalf("expected only the groceries withdrawal, got %+v", page.Entries)
+	}
+
+	page, err = service.History(ctx, acc.ID, transaction.HistoryFilter{NoteContains: "SALARY"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(page.Entries) != 1 || page.Entries[0].Type != transaction.TypeDeposit {
+		t.Fatalf("expected only the salary deposit, got %+v", page.Entries)
+	}
+}
@@ -0,0 +1,43 @@
+package transaction
+
+import (
+	"errors"
+	"time"
+)
+
+type HoldStatus string
+
+const (
+	HoldActive   HoldStatus = "ACTIVE"
+	HoldCaptured HoldStatus = "CAPTURED"
+	HoldReleased HoldStatus = "RELEASED"
+	HoldExpired  HoldStatus = "EXPIRED"
+)
+
+// DefaultHoldTTL applies when a hold is placed without an explicit TTL.
+const DefaultHoldTTL = 7 * 24 * time.Hour
+
+var (
+	ErrHoldNotFound       = errors.New("hold not found")
+	ErrHoldNotActive      = errors.New("hold is no longer active")
+	ErrCaptureExceedsHold = errors.New("capture exceeds the held amount")
+	ErrInvalidTTL         = errors.New("ttl must be positive")
+)
+
+// Hold reserves funds on an account. An active hold lowers the available
+// balance but not the ledger balance until it is captured.
+type Hold struct {
+	ID             int64      `json:"id"`
+	AccountID      int64      `json:"account_id"`
+	Amount         int64      `json:"amount"` // cents reserved
+	CapturedAmount int64      `json:"captured_amount"`
+	Currency       string     `json:"currency"`
+	Status         HoldStatus `json:"status"`
+	Note           string     `json:"note"`
+	TransactionID  int64      `json:"transaction_id,omitempty"` // WITHDRAW posted on capture
+	Fees           []Fee      `json:"fees,omitempty"`           // charged on capture, not stored
+	ExpiresAt      time.Time  `json:"expires_at"`
+	Expired        bool       `json:"-"` // past expires_at by the database clock
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
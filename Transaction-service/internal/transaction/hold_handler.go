@@ -0,0 +1,138 @@
+package transaction
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi"
+)
+
+func (h *TransactionHandler) PlaceHold(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		AccountID  int64  `json:"account_id"`
+		Amount     int64  `json:"amount"`
+		Currency   string `json:"currency"`
+		TTLSeconds int64  `json:"ttl_seconds"`
+		Note       string `json:"note"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "INVALID_REQUEST", "Invalid JSON payload")
+		return
+	}
+
+	if req.Amount <= 0 {
+		respondError(w, http.StatusBadRequest, "INVALID_AMOUNT", "Hold amount must be greater than zero")
+		return
+	}
+
+	if req.TTLSeconds < 0 {
+		respondError(w, http.StatusBadRequest, "INVALID_TTL", "ttl_seconds must be positive")
+		return
+	}
+
+	hold, err := h.service.PlaceHold(
+		r.Context(),
+		req.AccountID,
+		req.Amount,
+		req.Currency,
+		time.Duration(req.TTLSeconds)*time.Second,
+		req.Note,
+	)
+	if err != nil {
+		respondServiceError(w, err)
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, SuccessResponse{
+		Status:  "success",
+		Message: "Hold placed successfully",
+		Data:    hold,
+	})
+}
+
+func (h *TransactionHandler) GetHold(w http.ResponseWriter, r *http.Request) {
+	id, ok := holdID(w, r)
+	if !ok {
+		return
+	}
+
+	hold, err := h.service.GetHold(r.Context(), id)
+	if err != nil {
+		respondServiceError(w, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, SuccessResponse{
+		Status:  "success",
+		Message: "Hold retrieved successfully",
+		Data:    hold,
+	})
+}
+
+func (h *TransactionHandler) CaptureHold(w http.ResponseWriter, r *http.Request) {
+	id, ok := holdID(w, r)
+	if !ok {
+		return
+	}
+
+	// amount is optional; omitting it captures the full hold
+	var req struct {
+		Amount int64  `json:"amount"`
+		Note   string `json:"note"`
+	}
+
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			respondError(w, http.StatusBadRequest, "INVALID_REQUEST", "Invalid JSON payload")
+			return
+		}
+	}
+
+	if req.Amount < 0 {
+		respondError(w, http.StatusBadRequest, "INVALID_AMOUNT", "Capture amount must be greater than zero")
+		return
+	}
+
+	hold, err := h.service.CaptureHold(r.Context(), id, req.Amount, req.Note)
+	if err != nil {
+		respondServiceError(w, err)
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, SuccessResponse{
+		Status:  "success",
+		Message: "Hold captured successfully",
+		Data:    hold,
+	})
+}
+
+func (h *TransactionHandler) ReleaseHold(w http.ResponseWriter, r *http.Request) {
+	id, ok := holdID(w, r)
+	if !ok {
+		return
+	}
+
+	hold, err := h.service.ReleaseHold(r.Context(), id)
+	if err != nil {
+		respondServiceError(w, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, SuccessResponse{
+		Status:  "success",
+		Message: "Hold released successfully",
+		Data:    hold,
+	})
+}
+
+func holdID(w http.ResponseWriter, r *http.Request) (int64, bool) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "holdID"), 10, 64)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "INVALID_HOLD_ID", "Hold ID must be a number")
+		return 0, false
+	}
+	return id, true
+}
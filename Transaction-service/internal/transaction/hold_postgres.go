@@ -0,0 +1,156 @@
+package transaction
+
+import (
+	"context"
+	"database/sql"
+	"time"
+	"transaction/internal/infrastructure/database"
+)
+
+var _ HoldRepository = (*PostgresHoldRepo)(nil)
+
+type PostgresHoldRepo struct {
+	db database.DBTX
+}
+
+func NewPostgresHoldRepo(db database.DBTX) *PostgresHoldRepo {
+	return &PostgresHoldRepo{db: db}
+}
+
+const holdColumns = `id, account_id, amount, captured_amount, currency, status, note,
+	COALESCE(transaction_id, 0), expires_at, expires_at <= now(), created_at, updated_at`
+
+func scanHold(row rowScanner) (*Hold, error) {
+	h := &Hold{}
+	err := row.Scan(
+		&h.ID,
+		&h.AccountID,
+		&h.Amount,
+		&h.CapturedAmount,
+		&h.Currency,
+		&h.Status,
+		&h.Note,
+		&h.TransactionID,
+		&h.ExpiresAt,
+		&h.Expired,
+		&h.CreatedAt,
+		&h.UpdatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, ErrHoldNotFound
+	}
+	return h, err
+}
+
+// Create stores an active hold. Expiry is computed from the database clock so
+// it compares consistently with the expiry sweep.
+func (r *PostgresHoldRepo) Create(ctx context.Context, hold *Hold, ttl time.Duration) error {
+	created, err := scanHold(r.db.QueryRowContext(ctx, `
+		INSERT INTO holds (account_id, amount, currency, status, note, expires_at)
+		VALUES ($1, $2, $3, $4, $5, now() + make_interval(secs => $6))
+		RETURNING `+holdColumns,
+		hold.AccountID, hold.Amount, hold.Currency, HoldActive, hold.Note, ttl.Seconds(),
+	))
+	if err != nil {
+		return err
+	}
+	*hold = *created
+	return nil
+}
+
+func (r *PostgresHoldRepo) GetByID(ctx context.Context, id int64) (*Hold, error) {
+	return scanHold(r.db.QueryRowContext(ctx, `
+		SELECT `+holdColumns+`
+		FROM holds
+		WHERE id = $1
+	`, id))
+}
+
+// LockByID reads a hold and locks it until the surrounding DB transaction
+// ends, so capture and release cannot race each other.
+func (r *PostgresHoldRepo) LockByID(ctx context.Context, id int64) (*Hold, error) {
+	return scanHold(r.db.QueryRowContext(ctx, `
+		SELECT `+holdColumns+`
+		FROM holds
+		WHERE id = $1
+		FOR UPDATE
+	`, id))
+}
+
+func (r *PostgresHoldRepo) Update(ctx context.Context, hold *Hold) error {
+	return r.db.QueryRowContext(ctx, `
+		UPDATE holds
+		SET status = $2,
+		    captured_amount = $3,
+		    transaction_id = $4,
+		    updated_at = now()
+		WHERE id = $1
+		RETURNING updated_at
+	`, hold.ID, hold.Status, hold.CapturedAmount, nullID(hold.TransactionID)).Scan(&hold.UpdatedAt)
+}
+
+// HeldAmount sums active holds that have not expired yet. Holds past their
+// expiry stop counting even before the sweeper marks them.
+func (r *PostgresHoldRepo) HeldAmount(ctx context.Context, accountID int64, currency string) (int64, error) {
+	var held int64
+	err := r.db.QueryRowContext(ctx, `
+		SELECT COALESCE(SUM(amount), 0)
+		FROM holds
+		WHERE account_id = $1
+		  AND currency = $2
+		  AND status = $3
+		  AND expires_at > now()
+	`, accountID, currency, HoldActive).Scan(&held)
+	return held, err
+}
+
+func (r *PostgresHoldRepo) HeldByAccount(ctx context.Context, accountID int64) (map[string]int64, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT currency, SUM(amount)
+		FROM holds
+		WHERE account_id = $1
+		  AND status = $2
+		  AND expires_at > now()
+		GROUP BY currency
+	`, accountID, HoldActive)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	held := map[string]int64{}
+	for rows.Next() {
+		var currency string
+		var amount int64
+		if err := rows.Scan(&currency, &amount); err != nil {
+			return nil, err
+		}
+		held[currency] = amount
+	}
+	return held, rows.Err()
+}
+
+// ExpireDue marks up to limit overdue active holds as expired. Rows locked by
+// an in-flight capture or release are skipped and picked up next time.
+func (r *PostgresHoldRepo) ExpireDue(ctx context.Context, limit int) (int, error) {
+	res, err := r.db.ExecContext(ctx, `
+		UPDATE holds
+		SET status = $1,
+		    updated_at = now()
+		WHERE id IN (
+			SELECT id
+			FROM holds
+			WHERE status = $2
+			  AND expires_at <= now()
+			ORDER BY expires_at
+			LIMIT $3
+			FOR UPDATE SKIP LOCKED
+		)
+	`, HoldExpired, HoldActive, limit)
+	if err != nil {
+		return 0, err
+	}
+
+	n, err := res.RowsAffected()
+	return int(n), err
+}
@@ -0,0 +1,16 @@
+package transaction
+
+import (
+	"context"
+	"time"
+)
+
+type HoldRepository interface {
+	Create(ctx context.Context, hold *Hold, ttl time.Duration) error
+	GetByID(ctx context.Context, id int64) (*Hold, error)
+	LockByID(ctx context.Context, id int64) (*Hold, error)
+	Update(ctx context.Context, hold *Hold) error
+	HeldAmount(ctx context.Context, accountID int64, currency string) (int64, error)
+	HeldByAccount(ctx context.Context, accountID int64) (map[string]int64, error)
+	ExpireDue(ctx context.Context, limit int) (int, error)
+}
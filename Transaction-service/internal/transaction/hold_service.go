@@ -0,0 +1,251 @@
+package transaction
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+	"transaction/internal/infrastructure/database"
+
+	"github.com/google/uuid"
+)
+
+// availableBalance is the ledger balance minus active holds. Debits are
+// checked against it so held funds cannot be spent twice.
+func availableBalance(ctx context.Context, db database.DBTX, accountID int64, currency string) (int64, error) {
+	balance, err := NewPostgresRepo(db).BalanceOf(ctx, accountID, currency)
+	if err != nil {
+		return 0, err
+	}
+
+	held, err := NewPostgresHoldRepo(db).HeldAmount(ctx, accountID, currency)
+	if err != nil {
+		return 0, err
+	}
+
+	return balance - held, nil
+}
+
+// PlaceHold reserves amount on the account for ttl (DefaultHoldTTL if zero).
+func (s *Service) PlaceHold(ctx context.Context, accountID int64, amount int64, currency string, ttl time.Duration, note string) (*Hold, error) {
+	if amount <= 0 {
+		return nil, ErrInvalidAmount
+	}
+
+	if ttl < 0 {
+		return nil, ErrInvalidTTL
+	}
+
+	if ttl == 0 {
+		ttl = DefaultHoldTTL
+	}
+
+	resp, err := s.activeAccount(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	currency, err = resolveCurrency(currency, resp.Currency)
+	if err != nil {
+		return nil, err
+	}
+
+	var hold *Hold
+	err = s.runner.Run(ctx, "place_hold", nil, func(tx *sql.Tx) error {
+		// a hold reserves funds like a debit
+		if err := lockAccounts(ctx, tx, accountID); err != nil {
+			return err
+		}
+
+		if err := s.checkFunds(ctx, tx, resp, accountID, currency, amount); err != nil {
+			return err
+		}
+
+		hold = &Hold{
+			AccountID: accountID,
+			Amount:    amount,
+			Currency:  currency,
+			Note:      note,
+		}
+
+		return NewPostgresHoldRepo(tx).Create(ctx, hold, ttl)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return hold, nil
+}
+
+// CaptureHold settles an active hold as a WITHDRAW. Capturing less than the
+// held amount releases the remainder; a hold can only be captured once. An
+// amount of zero captures the full hold.
+func (s *Service) CaptureHold(ctx context.Context, holdID int64, amount int64, note string) (*Hold, error) {
+	if amount < 0 {
+		return nil, ErrInvalidAmount
+	}
+
+	var hold *Hold
+	err := s.runner.Run(ctx, "capture_hold", nil, func(tx *sql.Tx) error {
+		var err error
+		hold, err = s.captureHold(ctx, tx, holdID, amount, note)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return hold, nil
+}
+
+func (s *Service) captureHold(ctx context.Context, tx *sql.Tx, holdID int64, amount int64, note string) (*Hold, error) {
+	holdRepo := NewPostgresHoldRepo(tx)
+	transactionRepo := NewPostgresRepo(tx)
+	journalRepo := NewPostgresJournalRepo(tx)
+	outboxRepo := NewPostgresOutboxRepository(tx)
+
+	// lock the account before the hold, the order every debit takes
+	unlocked, err := holdRepo.GetByID(ctx, holdID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := lockAccounts(ctx, tx, unlocked.AccountID); err != nil {
+		return nil, err
+	}
+
+	hold, err := holdRepo.LockByID(ctx, holdID)
+	if err != nil {
+		return nil, err
+	}
+
+	if hold.Status != HoldActive || hold.Expired {
+		return nil, ErrHoldNotActive
+	}
+
+	if amount == 0 {
+		amount = hold.Amount
+	}
+
+	if amount > hold.Amount {
+		return nil, ErrCaptureExceedsHold
+	}
+
+	if note == "" {
+		note = hold.Note
+	}
+
+	account, err := s.activeAccount(ctx, hold.AccountID)
+	if err != nil {
+		return nil, err
+	}
+
+	// a capture is a withdrawal and is charged like one
+	fees, err := quoteFees(ctx, tx, FeeOnWithdraw, account, hold.Currency, amount)
+	if err != nil {
+		return nil, err
+	}
+
+	// the hold itself is part of the held total, so only what is captured
+	// beyond it, and the fees, need covering
+	if err := s.checkFunds(ctx, tx, account, hold.AccountID, hold.Currency, amount-hold.Amount+totalFees(fees)); err != nil {
+		return nil, err
+	}
+
+	// the captured amount goes out as a withdrawal, so it counts against
+	// the limits when it is captured
+	if err := s.checkLimits(ctx, tx, account, hold.Currency, amount); err != nil {
+		return nil, err
+	}
+
+	journal := withdrawJournal(hold.AccountID, amount, hold.Currency, note)
+	if err := journalRepo.Create(ctx, journal); err != nil {
+		return nil, err
+	}
+
+	entry := &Transaction{
+		AccountID: hold.AccountID,
+		JournalID: journal.ID,
+		Type:      TypeWithdraw,
+		Amount:    amount,
+		Currency:  hold.Currency,
+		Note:      note,
+	}
+
+	if err := transactionRepo.Create(ctx, entry); err != nil {
+		return nil, err
+	}
+
+	if err := postFees(ctx, tx, entry, fees); err != nil {
+		return nil, err
+	}
+
+	payload, err := json.Marshal(map[string]interface{}{
+		"account_id": hold.AccountID,
+		"amount":     amount,
+		"currency":   hold.Currency,
+		"type":       "withdraw",
+		"hold_id":    hold.ID,
+		"note":       note,
+		"journal_id": entry.JournalID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := outboxRepo.Add(ctx, &OutboxEvent{
+		ID:            uuid.New(),
+		AggregateType: "account",
+		AggregateID:   hold.AccountID,
+		EventType:     "transaction.created",
+		Payload:       payload,
+	}); err != nil {
+		return nil, err
+	}
+
+	hold.Status = HoldCaptured
+	hold.CapturedAmount = amount
+	hold.TransactionID = entry.ID
+	hold.Fees = fees
+
+	if err := holdRepo.Update(ctx, hold); err != nil {
+		return nil, err
+	}
+
+	return hold, nil
+}
+
+// ReleaseHold gives the reserved funds back without posting anything.
+func (s *Service) ReleaseHold(ctx context.Context, holdID int64) (*Hold, error) {
+	var hold *Hold
+	err := s.runner.Run(ctx, "release_hold", nil, func(tx *sql.Tx) error {
+		holdRepo := NewPostgresHoldRepo(tx)
+
+		var err error
+		hold, err = holdRepo.LockByID(ctx, holdID)
+		if err != nil {
+			return err
+		}
+
+		if hold.Status != HoldActive {
+			return ErrHoldNotActive
+		}
+
+		hold.Status = HoldReleased
+		return holdRepo.Update(ctx, hold)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return hold, nil
+}
+
+func (s *Service) GetHold(ctx context.Context, holdID int64) (*Hold, error) {
+	return s.holdRepo.GetByID(ctx, holdID)
+}
+
+// ExpireHolds marks up to limit overdue holds as expired.
+func (s *Service) ExpireHolds(ctx context.Context, limit int) (int, error) {
+	return s.holdRepo.ExpireDue(ctx, limit)
+}
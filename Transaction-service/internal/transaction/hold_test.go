@@ -0,0 +1,95 @@
+package transaction_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+	"transaction/internal/transaction"
+)
+
+func availableUSD(t *testing.T, s transaction.TransactionService, accountID int64) int64 {
+	t.Helper()
+	balances, err := s.Balance(context.Background(), accountID)
+	if err != nil {
+		t.Fatalf("❌ Failed to get balance: %v", err)
+	}
+	for _, b := range balances {
+		if b.Currency == "USD" {
+			return b.Available
+		}
+	}
+	return 0
+}
+
+func TestHold_ReducesAvailableBalance(t *testing.T) {
+	db := setupTestDB(t)
+	ctx := context.Background()
+
+	txRepo := transaction.NewPostgresRepo(db)
+	service := transaction.NewTransactionService(db, &MockAccountClient{}, txRepo)
+
+	acc := createTestAccount(t, db, "Card Holder")
+	service.Deposit(ctx, "hold-1", acc.ID, 10_000, "USD", "funding", transaction.Labels{})
+
+	hold, err := service.PlaceHold(ctx, acc.ID, 7_000, "USD", time.Hour, "hotel")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assertBalance(t, service, acc.ID, 10_000)
+	if got := availableUSD(t, service, acc.ID); got != 3_000 {
+		t.Fatalf("expected available 3000, got %d", got)
+	}
+
+	if _, err := service.Withdraw(ctx, "hold-2", acc.ID, 5_000, "USD", "atm", transaction.Labels{}); err == nil {
+		t.Fatal("withdraw of held funds should fail")
+	}
+
+	if _, err := service.ReleaseHold(ctx, hold.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := availableUSD(t, service, acc.ID); got != 10_000 {
+		t.Fatalf("expected available 10000 after release, got %d", got)
+	}
+
+	if _, err := service.CaptureHold(ctx, hold.ID, 0, ""); !errors.Is(err, transaction.ErrHoldNotActive) {
+		t.Fatalf("expected ErrHoldNotActive, got %v", err)
+	}
+}
+
+func TestHold_PartialCapture(t *testing.T) {
+	db := setupTestDB(t)
+	ctx := context.Background()
+
+	txRepo := transaction.NewPostgresRepo(db)
+	service := transaction.NewTransactionService(db, &MockAccountClient{}, txRepo)
+
+	acc := createTestAccount(t, db, "Diner")
+	service.Deposit(ctx, "hold-3", acc.ID, 10_000, "USD", "funding", transaction.Labels{})
+
+	hold, err := service.PlaceHold(ctx, acc.ID, 6_000, "USD", time.Hour, "restaurant")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	captured, err := service.CaptureHold(ctx, hold.ID, 4_500, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if captured.Status != transaction.HoldCaptured || captured.TransactionID == 0 {
+		t.Fatalf("expected captured hold linked to a transaction, got %+v", captured)
+	}
+
+	assertBalance(t, service, acc.ID, 5_500)
+	if got := availableUSD(t, service, acc.ID); got != 5_500 {
+		t.Fatalf("expected remainder released, available 5500, got %d", got)
+	}
+
+	entries, _ := txRepo.ListByAccount(ctx, acc.ID)
+	if entries[0].Type != transaction.TypeWithdraw || entries[0].Amount != 4_500 {
+		t.Fatalf("expected WITHDRAW of 4500, got %s of %d", entries[0].Type, entries[0].Amount)
+	}
+}
@@ -0,0 +1,55 @@
+package transaction
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// HoldExpiryWorker periodically expires holds whose TTL has passed.
+type HoldExpiryWorker struct {
+	service   *Service
+	interval  time.Duration
+	batchSize int
+}
+
+func NewHoldExpiryWorker(service *Service) *HoldExpiryWorker {
+	return &HoldExpiryWorker{
+		service:   service,
+		interval:  30 * time.Second,
+		batchSize: 100,
+	}
+}
+
+func (w *HoldExpiryWorker) Start(ctx context.Context) {
+	log.Println("🚀 Hold expiry worker started")
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Println("🛑 Hold expiry worker stopped")
+			return
+
+		case <-ticker.C:
+			// drain everything that is due before waiting again
+			for {
+				n, err := w.service.ExpireHolds(ctx, w.batchSize)
+				if err != nil {
+					log.Println("❌ Expire holds failed:", err)
+					break
+				}
+
+				if n > 0 {
+					log.Printf("⌛ Expired %d holds", n)
+				}
+
+				if n < w.batchSize {
+					break
+				}
+			}
+		}
+	}
+}
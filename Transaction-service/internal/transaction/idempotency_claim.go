@@ -0,0 +1,74 @@
+package transaction
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+var ErrIdempotencyKeyReused = errors.New("idempotency key was already used for a different request")
+
+// payloadHash fingerprints the validated arguments of an operation, so a
+// retry is told apart from a key reused for something else.
+func payloadHash(args ...interface{}) (string, error) {
+	b, err := json.Marshal(args)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// claimKey marks key as applied for operation in the caller's transaction.
+// It returns claimed=false and the stored result when the same request was
+// already applied, and ErrIdempotencyKeyReused when the key was applied for
+// a different operation or different arguments.
+func claimKey(ctx context.Context, repo IdempotencyRepository, key, operation, hash string) (bool, []byte, error) {
+	inserted, err := repo.TryInsert(ctx, key, operation, hash)
+	if err != nil || inserted {
+		return inserted, nil, err
+	}
+
+	// the conflicting row stays locked by TryInsert until commit
+	rec, err := repo.Get(ctx, key)
+	if err != nil {
+		return false, nil, err
+	}
+	if rec == nil {
+		return false, nil, fmt.Errorf("idempotency key %s disappeared", key)
+	}
+
+	// keys applied before fingerprinting can only be checked by operation
+	if rec.Operation != operation || rec.PayloadHash != "" && rec.PayloadHash != hash {
+		idempotencyMetrics.Add("key_reused", 1)
+		return false, nil, ErrIdempotencyKeyReused
+	}
+
+	idempotencyMetrics.Add("replayed."+operation, 1)
+	return false, rec.Result, nil
+}
+
+// saveResult stores v as the result replayed to retries of key.
+func saveResult(ctx context.Context, repo IdempotencyRepository, key string, v interface{}) error {
+	if key == "" {
+		return nil
+	}
+
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return repo.SaveResult(ctx, key, b)
+}
+
+// replayResult decodes a stored result into v. Keys applied before results
+// were stored replay as an empty result.
+func replayResult(result []byte, v interface{}) error {
+	if len(result) == 0 {
+		return nil
+	}
+	return json.Unmarshal(result, v)
+}
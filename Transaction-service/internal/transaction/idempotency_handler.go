@@ -0,0 +1,114 @@
+package transaction
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"log"
+	"net/http"
+)
+
+// idempotent wraps a write handler so requests carrying an Idempotency-Key
+// are executed once. Retries get the original status and body back, a retry
+// racing the original gets 409, and reusing a key for a different request
+// gets 422.
+func (h *TransactionHandler) idempotent(operation string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get("Idempotency-Key")
+		if key == "" {
+			next(w, r)
+			return
+		}
+
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			respondError(w, http.StatusBadRequest, "INVALID_REQUEST", "Could not read request body")
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		hash := requestHash(body)
+
+		rec, started, err := h.idempotency.Begin(r.Context(), key, operation, hash)
+		if err != nil {
+			respondError(w, http.StatusInternalServerError, "IDEMPOTENCY_FAILED", err.Error())
+			return
+		}
+
+		if !started {
+			switch {
+			case rec == nil || rec.Status == IdempotencyInProgress && rec.Operation == operation && rec.RequestHash == hash:
+				idempotencyMetrics.Add("in_progress_conflicts", 1)
+				respondError(w, http.StatusConflict, "IDEMPOTENCY_IN_PROGRESS", "A request with this Idempotency-Key is still being processed")
+			case rec.Operation != operation || rec.RequestHash != "" && rec.RequestHash != hash:
+				idempotencyMetrics.Add("key_reused", 1)
+				respondError(w, http.StatusUnprocessableEntity, "IDEMPOTENCY_KEY_REUSED", "Idempotency-Key was already used for a different request")
+			default:
+				idempotencyMetrics.Add("replayed."+operation, 1)
+				replay(w, rec)
+			}
+			return
+		}
+
+		rw := &responseRecorder{ResponseWriter: w, status: http.StatusOK}
+		next(rw, r)
+
+		// the response is already sent, so finish the key even if the
+		// client went away
+		ctx := context.WithoutCancel(r.Context())
+
+		if rw.status >= http.StatusInternalServerError {
+			// failures on our side are not replayed; the client may retry
+			if err := h.idempotency.Release(ctx, key); err != nil {
+				log.Printf("❌ Failed to release idempotency key %s: %v", key, err)
+			}
+			return
+		}
+
+		if err := h.idempotency.Save(ctx, key, rw.status, rw.body.Bytes()); err != nil {
+			log.Printf("❌ Failed to save idempotency key %s: %v", key, err)
+		}
+	}
+}
+
+func replay(w http.ResponseWriter, rec *IdempotencyRecord) {
+	// keys used directly through the service have no HTTP response
+	if rec.ResponseCode == 0 {
+		respondJSON(w, http.StatusOK, SuccessResponse{
+			Status:  "success",
+			Message: "Request already processed",
+		})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Idempotent-Replayed", "true")
+	w.WriteHeader(rec.ResponseCode)
+	w.Write(rec.Response)
+}
+
+// requestHash fingerprints the raw request body, so a retry must resend the
+// same bytes to be recognised as the same request.
+func requestHash(body []byte) string {
+	sum := sha256.Sum256(body)
+	return hex.EncodeToString(sum[:])
+}
+
+// responseRecorder passes a response through while keeping a copy of it.
+type responseRecorder struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (r *responseRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	r.body.Write(b)
+	return r.ResponseWriter.Write(b)
+}
@@ -0,0 +1,7 @@
+package transaction
+
+import "expvar"
+
+// idempotencyMetrics is published at /debug/vars under "idempotency".
+// Replays are counted per operation as "replayed.<operation>".
+var idempotencyMetrics = expvar.NewMap("idempotency")
@@ -3,6 +3,7 @@ package transaction
 import (
 	"context"
 	"database/sql"
+	"time"
 	"transaction/internal/infrastructure/database"
 	//"transaction/Transaction-service/internal/infrastructure/database"
 	//"transaction/internal/infrastructure/database"
@@ -10,56 +11,163 @@ import (
 	//"encoding/json"
 )
 
+type IdempotencyStatus string
+
+const (
+	IdempotencyInProgress IdempotencyStatus = "IN_PROGRESS"
+	IdempotencyCompleted  IdempotencyStatus = "COMPLETED"
+)
+
+// idempotencyLockTimeout is how long a request may hold a key in progress
+// before a retry is allowed to take it over.
+const idempotencyLockTimeout = time.Minute
+
+// IdempotencyRecord is a stored request and, once completed, the response
+// that is replayed to retries.
+type IdempotencyRecord struct {
+	Key          string
+	Operation    string
+	RequestHash  string // empty for keys stored before fingerprinting
+	Status       IdempotencyStatus
+	ResponseCode int // zero when the key was used outside of HTTP
+	Response     []byte
+	PayloadHash  string // fingerprint of the applied operation's arguments
+	Result       []byte // what the applied operation returned, as JSON
+	CreatedAt    time.Time
+}
+
 type IdempotencyRepository interface {
-	Get(ctx context.Context, key string) ([]byte, error)
-	Save(ctx context.Context, key, operation string, response []byte) error
-	TryInsert(ctx context.Context, idempotencyKey string, operation string) (bool, error)
+	Get(ctx context.Context, key string) (*IdempotencyRecord, error)
+	// Begin claims a key for a new request. It returns started=false and the
+	// existing record when the key is already known.
+	Begin(ctx context.Context, key, operation, requestHash string) (*IdempotencyRecord, bool, error)
+	// Save completes a key with the response to replay.
+	Save(ctx context.Context, key string, responseCode int, response []byte) error
+	// Release forgets a key whose request failed without applying anything.
+	Release(ctx context.Context, key string) error
+	// TryInsert marks the operation as applied. It runs in the same DB
+	// transaction as the money movement and reports false if it already was.
+	TryInsert(ctx context.Context, idempotencyKey string, operation string, payloadHash string) (bool, error)
+	// SaveResult stores what the applied operation returned, in the same DB
+	// transaction as TryInsert.
+	SaveResult(ctx context.Context, idempotencyKey string, result []byte) error
+	// DeleteExpired removes up to limit keys past their retention window.
+	DeleteExpired(ctx context.Context, limit int) (int64, error)
 }
 
 type PostgresIdempotencyRepo struct {
-	db database.DBTX
+	db        database.DBTX
+	retention IdempotencyRetention
 }
 
 func NewPostgresIdempotencyRepo(db database.DBTX) *PostgresIdempotencyRepo {
-	return &PostgresIdempotencyRepo{db: db}
+	return NewPostgresIdempotencyRepoWithRetention(db, DefaultIdempotencyRetention)
+}
+
+func NewPostgresIdempotencyRepoWithRetention(db database.DBTX, retention IdempotencyRetention) *PostgresIdempotencyRepo {
+	return &PostgresIdempotencyRepo{db: db, retention: retention}
 }
 
-func (r *PostgresIdempotencyRepo) Get(ctx context.Context, key string) ([]byte, error) {
-	var response []byte
+func (r *PostgresIdempotencyRepo) Get(ctx context.Context, key string) (*IdempotencyRecord, error) {
+	var rec IdempotencyRecord
+	var hash, payloadHash sql.NullString
+	var code sql.NullInt64
+
 	err := r.db.QueryRowContext(
 		ctx,
-		`SELECT response FROM idempotency_keys WHERE key = $1`,
+		`SELECT key, operation, request_hash, status, response_code, response, payload_hash, result, created_at
+		 FROM idempotency_keys WHERE key = $1`,
 		key,
-	).Scan(&response)
+	).Scan(&rec.Key, &rec.Operation, &hash, &rec.Status, &code, &rec.Response, &payloadHash, &rec.Result, &rec.CreatedAt)
 
 	if err == sql.ErrNoRows {
 		return nil, nil
 	}
-	return response, err
+	if err != nil {
+		return nil, err
+	}
+
+	rec.RequestHash = hash.String
+	rec.ResponseCode = int(code.Int64)
+	rec.PayloadHash = payloadHash.String
+	return &rec, nil
 }
 
-func (r *PostgresIdempotencyRepo) Save(ctx context.Context, key string, operation string, response []byte) error {
+func (r *PostgresIdempotencyRepo) Begin(ctx context.Context, key, operation, requestHash string) (*IdempotencyRecord, bool, error) {
+	// an expired key is forgotten, even if the sweeper has not reached it
+	if _, err := r.db.ExecContext(ctx,
+		`DELETE FROM idempotency_keys WHERE key = $1 AND expires_at <= now()`,
+		key,
+	); err != nil {
+		return nil, false, err
+	}
+
+	// a key left in progress by a crashed request is taken over by an
+	// identical retry once the lock times out
+	var insertedKey string
+	err := r.db.QueryRowContext(ctx, `
+		INSERT INTO idempotency_keys (key, operation, request_hash, status, locked_at, expires_at)
+		VALUES ($1, $2, $3, 'IN_PROGRESS', now(), now() + make_interval(secs => $5))
+		ON CONFLICT (key) DO UPDATE
+		SET locked_at = now()
+		WHERE idempotency_keys.status = 'IN_PROGRESS'
+		  AND idempotency_keys.locked_at < now() - make_interval(secs => $4)
+		  AND idempotency_keys.operation = EXCLUDED.operation
+		  AND idempotency_keys.request_hash = EXCLUDED.request_hash
+		RETURNING key
+	`, key, operation, requestHash, idempotencyLockTimeout.Seconds(), r.retention.For(operation).Seconds()).Scan(&insertedKey)
+
+	if err == nil {
+		return nil, true, nil
+	}
+	if err != sql.ErrNoRows {
+		return nil, false, err
+	}
+
+	rec, err := r.Get(ctx, key)
+	return rec, false, err
+}
+
+func (r *PostgresIdempotencyRepo) Save(ctx context.Context, key string, responseCode int, response []byte) error {
 	_, err := r.db.ExecContext(ctx,
-		`INSERT INTO idempotency_keys (key, operation, response)
-		 VALUES ($1, $2, $3)
-		 ON CONFLICT (key) DO UPDATE 
-		 SET response = EXCLUDED.response, operation = EXCLUDED.operation`,
-		key, operation, response,
+		`UPDATE idempotency_keys
+		 SET status = 'COMPLETED', response_code = $2, response = $3
+		 WHERE key = $1`,
+		key, responseCode, response,
+	)
+	return err
+}
+
+func (r *PostgresIdempotencyRepo) Release(ctx context.Context, key string) error {
+	_, err := r.db.ExecContext(ctx,
+		`DELETE FROM idempotency_keys
+		 WHERE key = $1 AND status = 'IN_PROGRESS' AND applied_at IS NULL`,
+		key,
 	)
 	return err
 }
 
-func (r *PostgresIdempotencyRepo) TryInsert(ctx context.Context, key string, operation string) (bool, error) {
+func (r *PostgresIdempotencyRepo) TryInsert(ctx context.Context, key string, operation string, payloadHash string) (bool, error) {
 
+	// either the HTTP layer already began the key, or the service is called
+	// directly and the key is recorded here; an expired key starts over
 	query := `
-	       INSERT INTO idempotency_keys (key, operation, response)
-		   VALUES ($1, $2, '{}' ::jsonb)
-		   ON CONFLICT (key) DO NOTHING
+	       INSERT INTO idempotency_keys (key, operation, status, applied_at, payload_hash, expires_at)
+		   VALUES ($1, $2, 'COMPLETED', now(), $4, now() + make_interval(secs => $3))
+		   ON CONFLICT (key) DO UPDATE
+		   SET applied_at = now(),
+		       operation = EXCLUDED.operation,
+		       payload_hash = EXCLUDED.payload_hash,
+		       result = NULL,
+		       expires_at = GREATEST(idempotency_keys.expires_at, EXCLUDED.expires_at)
+		   WHERE (idempotency_keys.applied_at IS NULL
+		          AND idempotency_keys.operation = EXCLUDED.operation)
+		      OR idempotency_keys.expires_at <= now()
 		   RETURNING key
 	`
 
 	var insertedKey string
-	err := r.db.QueryRowContext(ctx, query, key, operation).Scan(&insertedKey)
+	err := r.db.QueryRowContext(ctx, query, key, operation, r.retention.For(operation).Seconds(), payloadHash).Scan(&insertedKey)
 
 	if err == sql.ErrNoRows {
 		return false, nil // already exist
@@ -71,3 +179,29 @@ func (r *PostgresIdempotencyRepo) TryInsert(ctx context.Context, key string, ope
 
 	return true, nil
 }
+
+func (r *PostgresIdempotencyRepo) SaveResult(ctx context.Context, key string, result []byte) error {
+	_, err := r.db.ExecContext(ctx,
+		`UPDATE idempotency_keys SET result = $2 WHERE key = $1`,
+		key, result,
+	)
+	return err
+}
+
+func (r *PostgresIdempotencyRepo) DeleteExpired(ctx context.Context, limit int) (int64, error) {
+	res, err := r.db.ExecContext(ctx, `
+		DELETE FROM idempotency_keys
+		WHERE key IN (
+			SELECT key
+			FROM idempotency_keys
+			WHERE expires_at <= now()
+			ORDER BY expires_at
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+	`, limit)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
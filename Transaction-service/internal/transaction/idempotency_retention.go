@@ -0,0 +1,71 @@
+package transaction
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// IdempotencyRetention decides how long a key is remembered per operation.
+//
+// Within the window a retry gets the stored response replayed. Once the
+// window has passed the key is forgotten: a request reusing it is treated as
+// a brand new request and executed again, whether or not the sweeper has
+// deleted the old row yet.
+type IdempotencyRetention struct {
+	Default      time.Duration
+	PerOperation map[string]time.Duration
+}
+
+var DefaultIdempotencyRetention = IdempotencyRetention{
+	Default: 24 * time.Hour,
+}
+
+func (p IdempotencyRetention) For(operation string) time.Duration {
+	if d, ok := p.PerOperation[operation]; ok {
+		return d
+	}
+	return p.Default
+}
+
+// UseIdempotencyRetention sets how long keys recorded by the service are
+// remembered. It should match the retention of the HTTP layer, or a key
+// begun there gets its expiry pushed out when the operation is applied.
+func (s *Service) UseIdempotencyRetention(retention IdempotencyRetention) {
+	s.retention = retention
+	s.idempotencyRepo = NewPostgresIdempotencyRepoWithRetention(s.db, retention)
+}
+
+// ParseIdempotencyRetention reads a comma separated list such as
+// "24h,transfer=72h". An entry without an operation sets the default.
+func ParseIdempotencyRetention(s string) (IdempotencyRetention, error) {
+	p := IdempotencyRetention{
+		Default:      DefaultIdempotencyRetention.Default,
+		PerOperation: map[string]time.Duration{},
+	}
+
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		operation, value, found := strings.Cut(part, "=")
+		if !found {
+			operation, value = "", part
+		}
+
+		d, err := time.ParseDuration(strings.TrimSpace(value))
+		if err != nil || d <= 0 {
+			return p, fmt.Errorf("invalid idempotency retention %q", part)
+		}
+
+		if operation == "" {
+			p.Default = d
+		} else {
+			p.PerOperation[strings.TrimSpace(operation)] = d
+		}
+	}
+
+	return p, nil
+}
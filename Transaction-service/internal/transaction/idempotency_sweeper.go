@@ -0,0 +1,53 @@
+package transaction
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// IdempotencySweeper deletes expired idempotency keys. It works in small
+// batches so no delete holds row locks for long.
+type IdempotencySweeper struct {
+	repo      IdempotencyRepository
+	interval  time.Duration
+	batchSize int
+}
+
+func NewIdempotencySweeper(repo IdempotencyRepository) *IdempotencySweeper {
+	return &IdempotencySweeper{
+		repo:      repo,
+		interval:  time.Minute,
+		batchSize: 500,
+	}
+}
+
+func (w *IdempotencySweeper) Start(ctx context.Context) {
+	log.Println("🚀 Idempotency sweeper started")
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Println("🛑 Idempotency sweeper stopped")
+			return
+
+		case <-ticker.C:
+			for {
+				n, err := w.repo.DeleteExpired(ctx, w.batchSize)
+				if err != nil {
+					log.Println("❌ Delete expired idempotency keys failed:", err)
+					break
+				}
+
+				idempotencyMetrics.Add("expired_deleted", n)
+
+				if n < int64(w.batchSize) {
+					break
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,231 @@
+package transaction_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+	"transaction/internal/transaction"
+)
+
+func postWithKey(t *testing.T, h http.Handler, path, key, body string) *httptest.ResponseRecorder {
+	t.Helper()
+
+	req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+	req.Header.Set("Idempotency-Key", key)
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestIdempotency_ReplaysOriginalResponse(t *testing.T) {
+	db := setupTestDB(t)
+
+	txRepo := transaction.NewPostgresRepo(db)
+	service := transaction.NewTransactionService(db, &MockAccountClient{}, txRepo)
+	handler := transaction.NewTransactionHandler(service, transaction.NewPostgresIdempotencyRepo(db)).Routes()
+
+	acc := createTestAccount(t, db, "Replay User")
+	body := `{"account_id":` + strconv.FormatInt(acc.ID, 10) + `,"amount":5000,"currency":"USD","note":"replay"}`
+
+	first := postWithKey(t, handler, "/deposit", "replay-key", body)
+	if first.Code != http.StatusCreated {
+		t.Fatalf("first deposit: expected 201, got %d: %s", first.Code, first.Body)
+	}
+
+	second := postWithKey(t, handler, "/deposit", "replay-key", body)
+	if second.Code != first.Code || second.Body.String() != first.Body.String() {
+		t.Fatalf("replay differs: got %d %s, want %d %s", second.Code, second.Body, first.Code, first.Body)
+	}
+	if second.Header().Get("Idempotent-Replayed") != "true" {
+		t.Fatal("replayed response is not marked as replayed")
+	}
+
+	assertBalance(t, service, acc.ID, 5_000)
+}
+
+func TestIdempotency_RejectsReusedKey(t *testing.T) {
+	db := setupTestDB(t)
+
+	txRepo := transaction.NewPostgresRepo(db)
+	service := transaction.NewTransactionService(db, &MockAccountClient{}, txRepo)
+	handler := transaction.NewTransactionHandler(service, transaction.NewPostgresIdempotencyRepo(db)).Routes()
+
+	acc := createTestAccount(t, db, "Reuse User")
+	id := strconv.FormatInt(acc.ID, 10)
+
+	if rec := postWithKey(t, handler, "/deposit", "reused-key", `{"account_id":`+id+`,"amount":5000}`); rec.Code != http.StatusCreated {
+		t.Fatalf("deposit: expected 201, got %d: %s", rec.Code, rec.Body)
+	}
+
+	// same key, different payload
+	if rec := postWithKey(t, handler, "/deposit", "reused-key", `{"account_id":`+id+`,"amount":9000}`); rec.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("changed payload: expected 422, got %d: %s", rec.Code, rec.Body)
+	}
+
+	// same key, different operation
+	if rec := postWithKey(t, handler, "/withdraw", "reused-key", `{"account_id":`+id+`,"amount":5000}`); rec.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("changed operation: expected 422, got %d: %s", rec.Code, rec.Body)
+	}
+
+	assertBalance(t, service, acc.ID, 5_000)
+}
+
+func TestIdempotency_ExpiredKeyStartsOver(t *testing.T) {
+	db := setupTestDB(t)
+
+	txRepo := transaction.NewPostgresRepo(db)
+	service := transaction.NewTransactionService(db, &MockAccountClient{}, txRepo)
+	idemRepo := transaction.NewPostgresIdempotencyRepo(db)
+	handler := transaction.NewTransactionHandler(service, idemRepo).Routes()
+
+	acc := createTestAccount(t, db, "Expiry User")
+	body := `{"account_id":` + strconv.FormatInt(acc.ID, 10) + `,"amount":5000}`
+
+	if rec := postWithKey(t, handler, "/deposit", "expiring-key", body); rec.Code != http.StatusCreated {
+		t.Fatalf("deposit: expected 201, got %d: %s", rec.Code, rec.Body)
+	}
+
+	if _, err := db.Exec(`UPDATE idempotency_keys SET expires_at = now() - INTERVAL '1 second'`); err != nil {
+		t.Fatal(err)
+	}
+
+	// reused after expiry, the key is a new request
+	if rec := postWithKey(t, handler, "/deposit", "expiring-key", body); rec.Code != http.StatusCreated || rec.Header().Get("Idempotent-Replayed") != "" {
+		t.Fatalf("expired key: expected a fresh 201, got %d: %s", rec.Code, rec.Body)
+	}
+	assertBalance(t, service, acc.ID, 10_000)
+
+	if _, err := db.Exec(`UPDATE idempotency_keys SET expires_at = now() - INTERVAL '1 second'`); err != nil {
+		t.Fatal(err)
+	}
+
+	n, err := idemRepo.DeleteExpired(context.Background(), 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 1 {
+		t.Fatalf("expected 1 expired key deleted, got %d", n)
+	}
+}
+
+func TestIdempotency_ServiceReplaysResult(t *testing.T) {
+	db := setupTestDB(t)
+	ctx := context.Background()
+
+	txRepo := transaction.NewPostgresRepo(db)
+	service := transaction.NewTransactionService(db, &MockAccountClient{}, txRepo)
+
+	if err := service.CreateFeeRule(ctx, &transaction.FeeRule{Name: "Transfer fee", Operation: transaction.FeeOnTransfer, Kind: transaction.FeeFlat, Amount: 50}); err != nil {
+		t.Fatal(err)
+	}
+
+	from := createTestAccount(t, db, "From")
+	to := createTestAccount(t, db, "To")
+	if err := service.Deposit(ctx, "", from.ID, 10_000, "USD", "funding", transaction.Labels{}); err != nil {
+		t.Fatal(err)
+	}
+
+	first, err := service.Transfer(ctx, "replayed-transfer", from.ID, to.ID, 1_000, "USD", "rent", transaction.Labels{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	second, err := service.Transfer(ctx, "replayed-transfer", from.ID, to.ID, 1_000, "USD", "rent", transaction.Labels{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(first) != 1 || len(second) != 1 || second[0] != first[0] {
+		t.Fatalf("expected the retry to return the original fees %+v, got %+v", first, second)
+	}
+	assertBalance(t, service, from.ID, 8_950)
+}
+
+func TestIdempotency_ServiceRejectsReusedKey(t *testing.T) {
+	db := setupTestDB(t)
+	ctx := context.Background()
+
+	txRepo := transaction.NewPostgresRepo(db)
+	service := transaction.NewTransactionService(db, &MockAccountClient{}, txRepo)
+
+	from := createTestAccount(t, db, "From")
+	to := createTestAccount(t, db, "To")
+	if err := service.Deposit(ctx, "reused-service-key", from.ID, 10_000, "USD", "", transaction.Labels{}); err != nil {
+		t.Fatal(err)
+	}
+
+	// same key, different amount
+	if err := service.Deposit(ctx, "reused-service-key", from.ID, 20_000, "USD", "", transaction.Labels{}); !errors.Is(err, transaction.ErrIdempotencyKeyReused) {
+		t.Fatalf("changed payload: expected ErrIdempotencyKeyReused, got %v", err)
+	}
+
+	// same key, different operation
+	if _, err := service.Transfer(ctx, "reused-service-key", from.ID, to.ID, 1_000, "USD", "", transaction.Labels{}); !errors.Is(err, transaction.ErrIdempotencyKeyReused) {
+		t.Fatalf("changed operation: expected ErrIdempotencyKeyReused, got %v", err)
+	}
+
+	assertBalance(t, service, from.ID, 10_000)
+	assertBalance(t, service, to.ID, 0)
+}
+
+func TestIdempotency_ServiceUsesConfiguredRetention(t *testing.T) {
+	db := setupTestDB(t)
+	ctx := context.Background()
+
+	txRepo := transaction.NewPostgresRepo(db)
+	service := transaction.NewTransactionService(db, &MockAccountClient{}, txRepo)
+	service.UseIdempotencyRetention(transaction.IdempotencyRetention{
+		Default:      2 * time.Hour,
+		PerOperation: map[string]time.Duration{"transfer": 72 * time.Hour},
+	})
+
+	from := createTestAccount(t, db, "From")
+	to := createTestAccount(t, db, "To")
+
+	if err := service.Deposit(ctx, "retention-deposit", from.ID, 5_000, "USD", "", transaction.Labels{}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := service.Transfer(ctx, "retention-transfer", from.ID, to.ID, 1_000, "USD", "", transaction.Labels{}); err != nil {
+		t.Fatal(err)
+	}
+
+	for key, want := range map[string]time.Duration{
+		"retention-deposit":  2 * time.Hour,
+		"retention-transfer": 72 * time.Hour,
+	} {
+		var seconds float64
+		if err := db.QueryRow(
+			`SELECT EXTRACT(EPOCH FROM expires_at - now()) FROM idempotency_keys WHERE key = $1`, key,
+		).Scan(&seconds); err != nil {
+			t.Fatal(err)
+		}
+
+		got := time.Duration(seconds) * time.Second
+		if got > want || got < want-time.Minute {
+			t.Fatalf("%s: expected to expire in %s, got %s", key, want, got)
+		}
+	}
+}
+
+func TestParseIdempotencyRetention(t *testing.T) {
+	p, err := transaction.ParseIdempotencyRetention("12h, transfer=72h")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := p.For("deposit"); got != 12*time.Hour {
+		t.Fatalf("deposit retention: expected 12h, got %s", got)
+	}
+	if got := p.For("transfer"); got != 72*time.Hour {
+		t.Fatalf("transfer retention: expected 72h, got %s", got)
+	}
+
+	if _, err := transaction.ParseIdempotencyRetention("transfer=soon"); err == nil {
+		t.Fatal("expected an error for an invalid duration")
+	}
+}
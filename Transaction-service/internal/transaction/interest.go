@@ -0,0 +1,374 @@
+package transaction
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrInvalidInterestRate  = errors.New("invalid interest rate")
+	ErrInterestRateNotFound = errors.New("interest rate not found")
+)
+
+// InterestMethod says what a day's interest is earned on. Simple interest is
+// earned on the end-of-day balance only; compound interest also earns on
+// what has accrued but not been posted yet.
+type InterestMethod string
+
+const (
+	InterestSimple   InterestMethod = "simple"
+	InterestCompound InterestMethod = "compound"
+)
+
+const (
+	// accruals are kept in millionths of a cent, so a day's interest on a
+	// small balance is not rounded away before it is posted
+	microsPerCent = 1_000_000
+	daysPerYear   = 365
+)
+
+// InterestTier pays RateBps a year on end-of-day balances up to and
+// including UpTo. Only the last tier may leave UpTo unset.
+type InterestTier struct {
+	UpTo    *int64 `json:"up_to,omitempty"`
+	RateBps int64  `json:"rate_bps"` // annual, 100 = 1%
+}
+
+// InterestRate is what accounts of one tier earn on balances in one
+// currency. The whole balance earns the rate of the tier it falls in.
+type InterestRate struct {
+	Tier      string         `json:"tier"`
+	Currency  string         `json:"currency"`
+	Method    InterestMethod `json:"method"`
+	Tiers     []InterestTier `json:"tiers"`
+	UpdatedAt time.Time      `json:"updated_at"`
+}
+
+// InterestAccrual is one day's interest on one balance.
+type InterestAccrual struct {
+	AccountID     int64          `json:"account_id"`
+	Currency      string         `json:"currency"`
+	Day           time.Time      `json:"day"`
+	Balance       int64          `json:"balance"` // end of day
+	Basis         int64          `json:"basis"`   // what interest was earned on
+	RateBps       int64          `json:"rate_bps"`
+	Method        InterestMethod `json:"method"`
+	AmountMicros  int64          `json:"amount_micros"` // millionths of a cent
+	PostedAt      *time.Time     `json:"posted_at,omitempty"`
+	TransactionID int64          `json:"transaction_id,omitempty"` // the INTEREST entry it was posted in
+}
+
+// BalanceKey names one currency balance of an account.
+type BalanceKey struct {
+	AccountID int64
+	Currency  string
+}
+
+func invalidInterestRate(format string, args ...any) error {
+	return fmt.Errorf("%w: %s", ErrInvalidInterestRate, fmt.Sprintf(format, args...))
+}
+
+// normalize validates the rate and returns it with its tier lower-cased and
+// its currency upper-cased.
+func (r InterestRate) normalize() (InterestRate, error) {
+	r.Tier = strings.ToLower(strings.TrimSpace(r.Tier))
+	if r.Tier == "" {
+		return r, invalidInterestRate("tier is required")
+	}
+
+	r.Currency = strings.ToUpper(strings.TrimSpace(r.Currency))
+	if !currencyCode.MatchString(r.Currency) {
+		return r, ErrInvalidCurrency
+	}
+
+	if r.Method != InterestSimple && r.Method != InterestCompound {
+		return r, invalidInterestRate("method must be %s or %s", InterestSimple, InterestCompound)
+	}
+
+	if len(r.Tiers) == 0 {
+		return r, invalidInterestRate("at least one tier is required")
+	}
+
+	var last int64
+	for i, t := range r.Tiers {
+		if t.RateBps < 0 || t.RateBps > 10_000 {
+			return r, invalidInterestRate("tier %d rate must be between 0 and 10000 basis points", i)
+		}
+		if t.UpTo == nil {
+			if i != len(r.Tiers)-1 {
+				return r, invalidInterestRate("only the last tier may be open-ended")
+			}
+			continue
+		}
+		if *t.UpTo <= last {
+			return r, invalidInterestRate("tier bounds must be positive and ascending")
+		}
+		last = *t.UpTo
+	}
+
+	return r, nil
+}
+
+// AnnualRate returns the rate in basis points a balance earns. Balances
+// above every bound of a closed schedule earn nothing.
+func (r *InterestRate) AnnualRate(balance int64) int64 {
+	for _, t := range r.Tiers {
+		if t.UpTo == nil || balance <= *t.UpTo {
+			return t.RateBps
+		}
+	}
+	return 0
+}
+
+// DailyInterest returns one day's interest on basis cents at an annual rate
+// of rateBps, in millionths of a cent, rounded down.
+func DailyInterest(basis, rateBps int64) int64 {
+	if basis <= 0 || rateBps <= 0 {
+		return 0
+	}
+
+	micros := new(big.Int).Mul(big.NewInt(basis), big.NewInt(rateBps))
+	micros.Mul(micros, big.NewInt(microsPerCent))
+	micros.Quo(micros, big.NewInt(10_000*daysPerYear))
+	return micros.Int64()
+}
+
+// microsToCents rounds an accrued total half up to whole cents.
+func microsToCents(micros int64) int64 {
+	return (micros + microsPerCent/2) / microsPerCent
+}
+
+func (s *Service) ListInterestRates(ctx context.Context) ([]InterestRate, error) {
+	return s.interestRepo.ListRates(ctx)
+}
+
+// SetInterestRate creates or replaces the rate of a tier and currency.
+func (s *Service) SetInterestRate(ctx context.Context, rate *InterestRate) error {
+	normalized, err := rate.normalize()
+	if err != nil {
+		return err
+	}
+	*rate = normalized
+
+	return s.interestRepo.SetRate(ctx, rate)
+}
+
+func (s *Service) DeleteInterestRate(ctx context.Context, tier, currency string) error {
+	return s.interestRepo.DeleteRate(ctx, strings.ToLower(tier), strings.ToUpper(currency))
+}
+
+// AccrueInterest records day's interest on every positive end-of-day balance
+// whose account tier has a rate for the currency, and returns how many
+// accruals it recorded. Balances already accrued for day are skipped, so
+// re-running a day accrues nothing twice. day must have closed at least
+// SettleDelay ago.
+func (s *Service) AccrueInterest(ctx context.Context, day time.Time) (int, error) {
+	day = day.UTC().Truncate(24 * time.Hour)
+	endOfDay := day.AddDate(0, 0, 1).Add(-time.Microsecond)
+
+	if endOfDay.After(time.Now().Add(-SettleDelay)) {
+		return 0, fmt.Errorf("day %s has not closed yet", day.Format("2006-01-02"))
+	}
+
+	// rates by tier and currency; nil when there is none
+	rates := map[[2]string]*InterestRate{}
+	rateFor := func(tier, currency string) (*InterestRate, error) {
+		key := [2]string{tier, currency}
+		if _, ok := rates[key]; !ok {
+			rate, err := s.interestRepo.Rate(ctx, tier, currency)
+			if err != nil && !errors.Is(err, ErrInterestRateNotFound) {
+				return nil, err
+			}
+			rates[key] = rate
+		}
+		return rates[key], nil
+	}
+
+	accrued := 0
+	var after BalanceKey
+	for {
+		due, err := s.interestRepo.DueForAccrual(ctx, day, after, 500)
+		if err != nil {
+			return accrued, err
+		}
+
+		for _, key := range due {
+			account, err := s.activeAccount(ctx, key.AccountID)
+			if errors.Is(err, ErrAccountNotFound) || errors.Is(err, ErrAccountInactive) {
+				continue
+			}
+			if err != nil {
+				return accrued, err
+			}
+
+			tier := strings.ToLower(account.GetTier())
+			rate, err := rateFor(tier, key.Currency)
+			if err != nil {
+				return accrued, err
+			}
+			if rate == nil {
+				continue
+			}
+
+			ok, err := s.accrueInterest(ctx, key, day, endOfDay, rate)
+			if err != nil {
+				return accrued, fmt.Errorf("account %d: %w", key.AccountID, err)
+			}
+			if ok {
+				accrued++
+			}
+		}
+
+		if len(due) < 500 {
+			return accrued, nil
+		}
+		after = due[len(due)-1]
+	}
+}
+
+func (s *Service) accrueInterest(ctx context.Context, key BalanceKey, day, endOfDay time.Time, rate *InterestRate) (bool, error) {
+	balance, err := s.transactionRepo.BalanceAt(ctx, key.AccountID, key.Currency, endOfDay)
+	if err != nil || balance <= 0 {
+		return false, err
+	}
+
+	basis := balance
+	if rate.Method == InterestCompound {
+		pending, err := s.interestRepo.PendingMicros(ctx, key, day, endOfDay)
+		if err != nil {
+			return false, err
+		}
+		basis += pending / microsPerCent
+	}
+
+	rateBps := rate.AnnualRate(balance)
+
+	return s.interestRepo.AddAccrual(ctx, &InterestAccrual{
+		AccountID:    key.AccountID,
+		Currency:     key.Currency,
+		Day:          day,
+		Balance:      balance,
+		Basis:        basis,
+		RateBps:      rateBps,
+		Method:       rate.Method,
+		AmountMicros: DailyInterest(basis, rateBps),
+	})
+}
+
+// PostInterest credits what each balance accrued during month as one
+// INTEREST entry, and returns how many entries it posted. Accruals are
+// marked posted in the same transaction, so a re-run posts nothing twice.
+// Totals are rounded half up to the cent.
+func (s *Service) PostInterest(ctx context.Context, month time.Time, limit int) (int, error) {
+	start := time.Date(month.Year(), month.Month(), 1, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(0, 1, 0)
+
+	due, err := s.interestRepo.DueForPosting(ctx, start, end, limit)
+	if err != nil {
+		return 0, err
+	}
+
+	posted := 0
+	for _, key := range due {
+		ok, err := s.postInterest(ctx, key, start, end)
+		if err != nil {
+			return posted, fmt.Errorf("account %d: %w", key.AccountID, err)
+		}
+		if ok {
+			posted++
+		}
+	}
+
+	return posted, nil
+}
+
+func (s *Service) postInterest(ctx context.Context, key BalanceKey, start, end time.Time) (bool, error) {
+	var posted bool
+	err := s.runner.Run(ctx, "post_interest", nil, func(tx *sql.Tx) error {
+		var err error
+		posted, err = postInterestIn(ctx, tx, key, start, end)
+		return err
+	})
+	return posted, err
+}
+
+func postInterestIn(ctx context.Context, tx *sql.Tx, key BalanceKey, start, end time.Time) (bool, error) {
+
+	interestRepo := NewPostgresInterestRepo(tx)
+	transactionRepo := NewPostgresRepo(tx)
+	journalRepo := NewPostgresJournalRepo(tx)
+	outboxRepo := NewPostgresOutboxRepository(tx)
+
+	// credit the account under its lock, like every other posting
+	if err := lockAccounts(ctx, tx, key.AccountID); err != nil {
+		return false, err
+	}
+
+	// another run may have posted it since it was listed
+	micros, days, err := interestRepo.LockUnposted(ctx, key, start, end)
+	if err != nil || days == 0 {
+		return false, err
+	}
+
+	var entryID int64
+	amount := microsToCents(micros)
+	if amount > 0 {
+		note := fmt.Sprintf("Interest for %s", start.Format("2006-01"))
+
+		journal := interestJournal(key.AccountID, amount, key.Currency, note)
+		if err := journalRepo.Create(ctx, journal); err != nil {
+			return false, err
+		}
+
+		entry := &Transaction{
+			AccountID: key.AccountID,
+			JournalID: journal.ID,
+			Type:      TypeInterest,
+			Amount:    amount,
+			Currency:  key.Currency,
+			Note:      note,
+		}
+		if err := transactionRepo.Create(ctx, entry); err != nil {
+			return false, err
+		}
+		entryID = entry.ID
+
+		payload, err := json.Marshal(map[string]interface{}{
+			"account_id": key.AccountID,
+			"amount":     amount,
+			"currency":   key.Currency,
+			"type":       "interest",
+			"period":     start.Format("2006-01"),
+			"note":       note,
+			"journal_id": journal.ID,
+		})
+		if err != nil {
+			return false, err
+		}
+
+		if err := outboxRepo.Add(ctx, &OutboxEvent{
+			ID:            uuid.New(),
+			AggregateType: "account",
+			AggregateID:   key.AccountID,
+			EventType:     "transaction.created",
+			Payload:       payload,
+		}); err != nil {
+			return false, err
+		}
+	}
+
+	// accruals that round to nothing are closed without an entry
+	if err := interestRepo.MarkPosted(ctx, key, start, end, entryID); err != nil {
+		return false, err
+	}
+
+	return amount > 0, nil
+}
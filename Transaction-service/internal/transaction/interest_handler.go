@@ -0,0 +1,56 @@
+package transaction
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi"
+)
+
+func (h *TransactionHandler) ListInterestRates(w http.ResponseWriter, r *http.Request) {
+	rates, err := h.service.ListInterestRates(r.Context())
+	if err != nil {
+		respondServiceError(w, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, SuccessResponse{
+		Status:  "success",
+		Message: "Interest rates retrieved successfully",
+		Data:    rates,
+	})
+}
+
+// SetInterestRate replaces the rate of the tier and currency in the route.
+func (h *TransactionHandler) SetInterestRate(w http.ResponseWriter, r *http.Request) {
+	var rate InterestRate
+	if err := json.NewDecoder(r.Body).Decode(&rate); err != nil {
+		respondError(w, http.StatusBadRequest, "INVALID_REQUEST", "Invalid JSON payload")
+		return
+	}
+	rate.Tier = chi.URLParam(r, "tier")
+	rate.Currency = chi.URLParam(r, "currency")
+
+	if err := h.service.SetInterestRate(r.Context(), &rate); err != nil {
+		respondServiceError(w, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, SuccessResponse{
+		Status:  "success",
+		Message: "Interest rate updated successfully",
+		Data:    rate,
+	})
+}
+
+func (h *TransactionHandler) DeleteInterestRate(w http.ResponseWriter, r *http.Request) {
+	if err := h.service.DeleteInterestRate(r.Context(), chi.URLParam(r, "tier"), chi.URLParam(r, "currency")); err != nil {
+		respondServiceError(w, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, SuccessResponse{
+		Status:  "success",
+		Message: "Interest rate deleted successfully",
+	})
+}
@@ -0,0 +1,209 @@
+package transaction
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+	"transaction/internal/infrastructure/database"
+)
+
+var _ InterestRepository = (*PostgresInterestRepo)(nil)
+
+type PostgresInterestRepo struct {
+	db database.DBTX
+}
+
+func NewPostgresInterestRepo(db database.DBTX) *PostgresInterestRepo {
+	return &PostgresInterestRepo{db: db}
+}
+
+func scanInterestRate(row rowScanner) (*InterestRate, error) {
+	var (
+		rate  InterestRate
+		tiers []byte
+	)
+	if err := row.Scan(&rate.Tier, &rate.Currency, &rate.Method, &tiers, &rate.UpdatedAt); err != nil {
+		return nil, err
+	}
+	return &rate, json.Unmarshal(tiers, &rate.Tiers)
+}
+
+func (r *PostgresInterestRepo) ListRates(ctx context.Context) ([]InterestRate, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT tier, currency, method, tiers, updated_at
+		FROM interest_rates
+		ORDER BY tier, currency
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	rates := []InterestRate{}
+	for rows.Next() {
+		rate, err := scanInterestRate(rows)
+		if err != nil {
+			return nil, err
+		}
+		rates = append(rates, *rate)
+	}
+	return rates, rows.Err()
+}
+
+func (r *PostgresInterestRepo) Rate(ctx context.Context, tier, currency string) (*InterestRate, error) {
+	rate, err := scanInterestRate(r.db.QueryRowContext(ctx, `
+		SELECT tier, currency, method, tiers, updated_at
+		FROM interest_rates
+		WHERE tier = $1 AND currency = $2
+	`, tier, currency))
+	if err == sql.ErrNoRows {
+		return nil, ErrInterestRateNotFound
+	}
+	return rate, err
+}
+
+func (r *PostgresInterestRepo) SetRate(ctx context.Context, rate *InterestRate) error {
+	tiers, err := json.Marshal(rate.Tiers)
+	if err != nil {
+		return err
+	}
+
+	return r.db.QueryRowContext(ctx, `
+		INSERT INTO interest_rates (tier, currency, method, tiers)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (tier, currency) DO UPDATE
+		SET method = EXCLUDED.method,
+		    tiers = EXCLUDED.tiers,
+		    updated_at = now()
+		RETURNING updated_at
+	`, rate.Tier, rate.Currency, rate.Method, string(tiers)).Scan(&rate.UpdatedAt)
+}
+
+func (r *PostgresInterestRepo) DeleteRate(ctx context.Context, tier, currency string) error {
+	res, err := r.db.ExecContext(ctx, `
+		DELETE FROM interest_rates
+		WHERE tier = $1 AND currency = $2
+	`, tier, currency)
+	if err != nil {
+		return err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrInterestRateNotFound
+	}
+	return nil
+}
+
+func scanBalanceKeys(rows *sql.Rows) ([]BalanceKey, error) {
+	defer rows.Close()
+
+	var keys []BalanceKey
+	for rows.Next() {
+		var k BalanceKey
+		if err := rows.Scan(&k.AccountID, &k.Currency); err != nil {
+			return nil, err
+		}
+		keys = append(keys, k)
+	}
+	return keys, rows.Err()
+}
+
+func (r *PostgresInterestRepo) DueForAccrual(ctx context.Context, day time.Time, after BalanceKey, limit int) ([]BalanceKey, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT b.account_id, b.currency
+		FROM account_balances b
+		WHERE (b.account_id, b.currency) > ($2, $3)
+		  AND NOT EXISTS (
+			SELECT 1
+			FROM interest_accruals a
+			WHERE a.account_id = b.account_id AND a.currency = b.currency AND a.day = $1
+		  )
+		ORDER BY b.account_id, b.currency
+		LIMIT $4
+	`, day, after.AccountID, after.Currency, limit)
+	if err != nil {
+		return nil, err
+	}
+	return scanBalanceKeys(rows)
+}
+
+func (r *PostgresInterestRepo) PendingMicros(ctx context.Context, key BalanceKey, day, asOf time.Time) (int64, error) {
+	var micros int64
+	err := r.db.QueryRowContext(ctx, `
+		SELECT COALESCE(SUM(amount_micros), 0)
+		FROM interest_accruals
+		WHERE account_id = $1 AND currency = $2 AND day < $3
+		  AND (posted_at IS NULL OR posted_at > $4)
+	`, key.AccountID, key.Currency, day, asOf).Scan(&micros)
+	return micros, err
+}
+
+func (r *PostgresInterestRepo) AddAccrual(ctx context.Context, a *InterestAccrual) (bool, error) {
+	res, err := r.db.ExecContext(ctx, `
+		INSERT INTO interest_accruals (account_id, currency, day, balance, basis, rate_bps, method, amount_micros)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (account_id, currency, day) DO NOTHING
+	`, a.AccountID, a.Currency, a.Day, a.Balance, a.Basis, a.RateBps, a.Method, a.AmountMicros)
+	if err != nil {
+		return false, err
+	}
+
+	n, err := res.RowsAffected()
+	return n == 1, err
+}
+
+func (r *PostgresInterestRepo) DueForPosting(ctx context.Context, start, end time.Time, limit int) ([]BalanceKey, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT account_id, currency
+		FROM interest_accruals
+		WHERE posted_at IS NULL AND day >= $1 AND day < $2
+		GROUP BY account_id, currency
+		ORDER BY account_id, currency
+		LIMIT $3
+	`, start, end, limit)
+	if err != nil {
+		return nil, err
+	}
+	return scanBalanceKeys(rows)
+}
+
+func (r *PostgresInterestRepo) LockUnposted(ctx context.Context, key BalanceKey, start, end time.Time) (int64, int, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT amount_micros
+		FROM interest_accruals
+		WHERE account_id = $1 AND currency = $2 AND posted_at IS NULL AND day >= $3 AND day < $4
+		FOR UPDATE
+	`, key.AccountID, key.Currency, start, end)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer rows.Close()
+
+	var (
+		total int64
+		count int
+	)
+	for rows.Next() {
+		var micros int64
+		if err := rows.Scan(&micros); err != nil {
+			return 0, 0, err
+		}
+		total += micros
+		count++
+	}
+	return total, count, rows.Err()
+}
+
+func (r *PostgresInterestRepo) MarkPosted(ctx context.Context, key BalanceKey, start, end time.Time, transactionID int64) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE interest_accruals
+		SET posted_at = now(), transaction_id = $5
+		WHERE account_id = $1 AND currency = $2 AND posted_at IS NULL AND day >= $3 AND day < $4
+	`, key.AccountID, key.Currency, start, end, nullID(transactionID))
+	return err
+}
@@ -0,0 +1,33 @@
+package transaction
+
+import (
+	"context"
+	"time"
+)
+
+type InterestRepository interface {
+	ListRates(ctx context.Context) ([]InterestRate, error)
+	// Rate returns the rate of tier in currency, or ErrInterestRateNotFound.
+	Rate(ctx context.Context, tier, currency string) (*InterestRate, error)
+	SetRate(ctx context.Context, rate *InterestRate) error
+	DeleteRate(ctx context.Context, tier, currency string) error
+
+	// DueForAccrual lists up to limit balances ordered after the given key
+	// that have no accrual for day yet.
+	DueForAccrual(ctx context.Context, day time.Time, after BalanceKey, limit int) ([]BalanceKey, error)
+	// PendingMicros sums the balance's accruals before day that had not
+	// been posted by asOf.
+	PendingMicros(ctx context.Context, key BalanceKey, day, asOf time.Time) (int64, error)
+	// AddAccrual stores the accrual unless the day is already accrued.
+	AddAccrual(ctx context.Context, accrual *InterestAccrual) (bool, error)
+
+	// DueForPosting lists up to limit balances with unposted accruals in
+	// [start, end).
+	DueForPosting(ctx context.Context, start, end time.Time, limit int) ([]BalanceKey, error)
+	// LockUnposted locks the balance's unposted accruals in [start, end) and
+	// returns their total and how many there are.
+	LockUnposted(ctx context.Context, key BalanceKey, start, end time.Time) (int64, int, error)
+	// MarkPosted closes the unposted accruals in [start, end); transactionID
+	// is 0 when they rounded to nothing.
+	MarkPosted(ctx context.Context, key BalanceKey, start, end time.Time, transactionID int64) error
+}
@@ -0,0 +1,153 @@
+package transaction_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+	"transaction/internal/transaction"
+)
+
+func TestDailyInterest(t *testing.T) {
+	tests := []struct {
+		name    string
+		basis   int64
+		rateBps int64
+		want    int64
+	}{
+		{"one cent a day", 10_000, 365, 1_000_000},
+		{"fractions of a cent are kept", 1_000, 500, 136_986},
+		{"no balance", 0, 500, 0},
+		{"overdrawn", -5_000, 500, 0},
+		{"no rate", 5_000, 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := transaction.DailyInterest(tt.basis, tt.rateBps); got != tt.want {
+				t.Fatalf("expected %d, got %d", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestInterestRate_AnnualRate(t *testing.T) {
+	rate := transaction.InterestRate{Tiers: []transaction.InterestTier{
+		{UpTo: ptr(100_000), RateBps: 50},
+		{UpTo: ptr(1_000_000), RateBps: 150},
+		{RateBps: 200},
+	}}
+
+	for balance, want := range map[int64]int64{
+		1:         50,
+		100_000:   50,
+		100_001:   150,
+		5_000_000: 200,
+	} {
+		if got := rate.AnnualRate(balance); got != want {
+			t.Fatalf("balance %d: expected %d, got %d", balance, want, got)
+		}
+	}
+}
+
+func TestInterestRate_RejectsInvalid(t *testing.T) {
+	// rates are validated before they are stored
+	service := transaction.NewTransactionService(nil, nil, nil)
+
+	for name, rate := range map[string]transaction.InterestRate{
+		"no tier":        {Currency: "USD", Method: transaction.InterestSimple, Tiers: []transaction.InterestTier{{RateBps: 100}}},
+		"bad method":     {Tier: "savings", Currency: "USD", Method: "daily", Tiers: []transaction.InterestTier{{RateBps: 100}}},
+		"no tiers":       {Tier: "savings", Currency: "USD", Method: transaction.InterestSimple},
+		"negative rate":  {Tier: "savings", Currency: "USD", Method: transaction.InterestSimple, Tiers: []transaction.InterestTier{{RateBps: -1}}},
+		"unordered tier": {Tier: "savings", Currency: "USD", Method: transaction.InterestCompound, Tiers: []transaction.InterestTier{{UpTo: ptr(100)}, {UpTo: ptr(50)}}},
+		"open mid tier":  {Tier: "savings", Currency: "USD", Method: transaction.InterestCompound, Tiers: []transaction.InterestTier{{RateBps: 1}, {UpTo: ptr(50)}}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			if err := service.SetInterestRate(context.Background(), &rate); !errors.Is(err, transaction.ErrInvalidInterestRate) {
+				t.Fatalf("expected ErrInvalidInterestRate, got %v", err)
+			}
+		})
+	}
+}
+
+func TestInterest_AccruedOnceAndPostedMonthly(t *testing.T) {
+	db := setupTestDB(t)
+	ctx := context.Background()
+
+	txRepo := transaction.NewPostgresRepo(db)
+	accounts := &MockAccountClient{Tiers: map[int64]string{}}
+	service := transaction.NewTransactionService(db, accounts, txRepo)
+
+	saver := createTestAccount(t, db, "Saver")
+	accounts.Tiers[saver.ID] = "savings"
+
+	if err := service.SetInterestRate(ctx, &transaction.InterestRate{
+		Tier:     "Savings",
+		Currency: "usd",
+		Method:   transaction.InterestSimple,
+		Tiers:    []transaction.InterestTier{{RateBps: 365}},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := service.Deposit(ctx, "", saver.ID, 1_000_000, "USD", "savings", transaction.Labels{}); err != nil {
+		t.Fatal(err)
+	}
+
+	// the deposit was made well before the day being accrued
+	if _, err := db.Exec(`UPDATE transactions SET created_at = now() - interval '10 days'`); err != nil {
+		t.Fatal(err)
+	}
+
+	day := time.Now().UTC().AddDate(0, 0, -3)
+
+	n, err := service.AccrueInterest(ctx, day)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 1 {
+		t.Fatalf("expected 1 accrual, got %d", n)
+	}
+
+	// re-running the day must not accrue it twice
+	n, err = service.AccrueInterest(ctx, day)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 0 {
+		t.Fatalf("expected no accruals on re-run, got %d", n)
+	}
+
+	posted, err := service.PostInterest(ctx, day, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if posted != 1 {
+		t.Fatalf("expected 1 posting, got %d", posted)
+	}
+
+	posted, err = service.PostInterest(ctx, day, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if posted != 0 {
+		t.Fatalf("expected no postings on re-run, got %d", posted)
+	}
+
+	// one day at 3.65% a year on 10000.00 is 1.00
+	balance, err := txRepo.BalanceOf(ctx, saver.ID, "USD")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if balance != 1_000_100 {
+		t.Fatalf("expected 1000100, got %d", balance)
+	}
+
+	page, err := service.History(ctx, saver.ID, transaction.HistoryFilter{Types: []transaction.Type{transaction.TypeInterest}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(page.Entries) != 1 || page.Entries[0].Amount != 100 {
+		t.Fatalf("expected one interest entry of 100, got %+v", page.Entries)
+	}
+}
@@ -0,0 +1,92 @@
+package transaction
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// interestCatchUpDays is how many closed days the worker accrues, so a day
+// missed while the service was down is still accrued.
+const interestCatchUpDays = 3
+
+// InterestWorker accrues interest for each day once it has closed and posts
+// the previous month's interest once the month is over.
+type InterestWorker struct {
+	service   *Service
+	interval  time.Duration
+	batchSize int
+	accrued   time.Time // last day accrued by this process
+}
+
+func NewInterestWorker(service *Service) *InterestWorker {
+	return &InterestWorker{
+		service:   service,
+		interval:  time.Hour,
+		batchSize: 100,
+	}
+}
+
+func (w *InterestWorker) Start(ctx context.Context) {
+	log.Println("🚀 Interest worker started")
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Println("🛑 Interest worker stopped")
+			return
+
+		case <-ticker.C:
+			// the last day whose end is at least SettleDelay old
+			closed := time.Now().UTC().Add(-SettleDelay).Truncate(24*time.Hour).AddDate(0, 0, -1)
+
+			if closed.After(w.accrued) && w.accrue(ctx, closed) {
+				w.accrued = closed
+			}
+
+			w.post(ctx, closed)
+		}
+	}
+}
+
+func (w *InterestWorker) accrue(ctx context.Context, closed time.Time) bool {
+	for i := interestCatchUpDays - 1; i >= 0; i-- {
+		day := closed.AddDate(0, 0, -i)
+
+		n, err := w.service.AccrueInterest(ctx, day)
+		if err != nil {
+			log.Printf("❌ Accrue interest for %s failed: %v", day.Format("2006-01-02"), err)
+			return false
+		}
+
+		if n > 0 {
+			log.Printf("💰 Accrued interest on %d balances for %s", n, day.Format("2006-01-02"))
+		}
+	}
+	return true
+}
+
+// post pays out the month before the one the next day to close falls in.
+func (w *InterestWorker) post(ctx context.Context, closed time.Time) {
+	month := closed.AddDate(0, 0, 1).AddDate(0, -1, 0)
+
+	// drain everything that is due before waiting again
+	for {
+		n, err := w.service.PostInterest(ctx, month, w.batchSize)
+		if err != nil {
+			log.Println("❌ Post interest failed:", err)
+			return
+		}
+
+		if n > 0 {
+			log.Printf("💰 Posted interest to %d balances for %s", n, month.Format("2006-01"))
+		}
+
+		if n < w.batchSize {
+			return
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package transaction
+
+import "errors"
+
+var (
+	ErrUnbalancedJournal = errors.New("journal postings do not sum to zero")
+	ErrJournalNotFound   = errors.New("journal entry not found")
+)
+
+// depositJournal credits the customer and debits cash.
+func depositJournal(accountID, amount int64, currency, note string) *JournalEntry {
+	return &JournalEntry{
+		Type:     JournalDeposit,
+		Currency: currency,
+		Note:     note,
+		Postings: []Posting{
+			{AccountID: accountID, Amount: amount},
+			{SystemAccount: SystemCash, Amount: -amount},
+		},
+	}
+}
+
+// withdrawJournal debits the customer and credits cash.
+func withdrawJournal(accountID, amount int64, currency, note string) *JournalEntry {
+	return &JournalEntry{
+		Type:     JournalWithdraw,
+		Currency: currency,
+		Note:     note,
+		Postings: []Posting{
+			{AccountID: accountID, Amount: -amount},
+			{SystemAccount: SystemCash, Amount: amount},
+		},
+	}
+}
+
+// feeJournal debits the customer and credits fee income.
+func feeJournal(accountID, amount int64, currency, note string) *JournalEntry {
+	return &JournalEntry{
+		Type:     JournalFee,
+		Currency: currency,
+		Note:     note,
+		Postings: []Posting{
+			{AccountID: accountID, Amount: -amount},
+			{SystemAccount: SystemFeeIncome, Amount: amount},
+		},
+	}
+}
+
+// interestJournal pays interest to the customer out of interest expense.
+func interestJournal(accountID, amount int64, currency, note string) *JournalEntry {
+	return &JournalEntry{
+		Type:     JournalInterest,
+		Currency: currency,
+		Note:     note,
+		Postings: []Posting{
+			{SystemAccount: SystemInterestExpense, Amount: -amount},
+			{AccountID: accountID, Amount: amount},
+		},
+	}
+}
+
+// transferJournal moves funds through clearing so each customer leg is
+// balanced on its own and clearing nets to zero.
+func transferJournal(fromAccountID, toAccountID, amount int64, currency, note string) *JournalEntry {
+	return &JournalEntry{
+		Type:     JournalTransfer,
+		Currency: currency,
+		Note:     note,
+		Postings: []Posting{
+			{AccountID: fromAccountID, Amount: -amount},
+			{SystemAccount: SystemClearing, Amount: amount},
+			{SystemAccount: SystemClearing, Amount: -amount},
+			{AccountID: toAccountID, Amount: amount},
+		},
+	}
+}
@@ -0,0 +1,98 @@
+package transaction
+
+import (
+	"context"
+	"database/sql"
+	"transaction/internal/infrastructure/database"
+
+	"github.com/google/uuid"
+)
+
+var _ JournalRepository = (*PostgresJournalRepo)(nil)
+
+type PostgresJournalRepo struct {
+	db database.DBTX
+}
+
+func NewPostgresJournalRepo(db database.DBTX) *PostgresJournalRepo {
+	return &PostgresJournalRepo{db: db}
+}
+
+// Create writes the journal header and all of its postings. It must run
+// inside a DB transaction so a partially written journal is never visible.
+func (r *PostgresJournalRepo) Create(ctx context.Context, entry *JournalEntry) error {
+	if !entry.Balanced() {
+		return ErrUnbalancedJournal
+	}
+
+	err := r.db.QueryRowContext(ctx, `
+		INSERT INTO journal_entries (type, currency, note, reverses_journal_id, batch_id)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at
+	`, entry.Type, entry.Currency, entry.Note, nullID(entry.ReversesID), entry.BatchID).Scan(&entry.ID, &entry.CreatedAt)
+	if err != nil {
+		return err
+	}
+
+	for i := range entry.Postings {
+		p := &entry.Postings[i]
+		p.JournalID = entry.ID
+
+		systemAccount := sql.NullString{String: string(p.SystemAccount), Valid: p.SystemAccount != ""}
+		if err := r.db.QueryRowContext(ctx, `
+			INSERT INTO postings (journal_id, account_id, system_account, amount)
+			VALUES ($1, $2, $3, $4)
+			RETURNING id
+		`, p.JournalID, nullID(p.AccountID), systemAccount, p.Amount).Scan(&p.ID); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r *PostgresJournalRepo) GetByID(ctx context.Context, id int64) (*JournalEntry, error) {
+	entry := &JournalEntry{}
+	var note sql.NullString
+	var batchID uuid.NullUUID
+	err := r.db.QueryRowContext(ctx, `
+		SELECT id, type, currency, COALESCE(reverses_journal_id, 0), batch_id, note, created_at
+		FROM journal_entries
+		WHERE id = $1
+	`, id).Scan(&entry.ID, &entry.Type, &entry.Currency, &entry.ReversesID, &batchID, &note, &entry.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, ErrJournalNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	entry.Note = note.String
+	if batchID.Valid {
+		entry.BatchID = &batchID.UUID
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, journal_id, account_id, system_account, amount
+		FROM postings
+		WHERE journal_id = $1
+		ORDER BY id
+	`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var p Posting
+		var accountID sql.NullInt64
+		var systemAccount sql.NullString
+		if err := rows.Scan(&p.ID, &p.JournalID, &accountID, &systemAccount, &p.Amount); err != nil {
+			return nil, err
+		}
+		p.AccountID = accountID.Int64
+		p.SystemAccount = SystemAccount(systemAccount.String)
+		entry.Postings = append(entry.Postings, p)
+	}
+
+	return entry, rows.Err()
+}
@@ -0,0 +1,8 @@
+package transaction
+
+import "context"
+
+type JournalRepository interface {
+	Create(ctx context.Context, entry *JournalEntry) error
+	GetByID(ctx context.Context, id int64) (*JournalEntry, error)
+}
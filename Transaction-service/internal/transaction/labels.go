@@ -0,0 +1,81 @@
+package transaction
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	MaxMetadataKeys     = 50
+	MaxMetadataValueLen = 500
+	MaxTags             = 20
+)
+
+var ErrInvalidLabels = errors.New("invalid metadata or tags")
+
+var (
+	metadataKeyPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,64}$`)
+	tagPattern         = regexp.MustCompile(`^[a-z0-9_-]{1,32}$`)
+)
+
+// Labels are the caller's structured annotations of an entry: string
+// metadata and a set of tags. Both are stored with the entry, returned in
+// history and carried in its outbox event.
+type Labels struct {
+	Metadata map[string]string `json:"metadata,omitempty"`
+	Tags     []string          `json:"tags,omitempty"`
+}
+
+// normalize validates the labels and returns them with tags lower-cased,
+// sorted and deduplicated.
+func (l Labels) normalize() (Labels, error) {
+	if len(l.Metadata) > MaxMetadataKeys {
+		return l, fmt.Errorf("%w: at most %d metadata keys", ErrInvalidLabels, MaxMetadataKeys)
+	}
+
+	for k, v := range l.Metadata {
+		if !metadataKeyPattern.MatchString(k) {
+			return l, fmt.Errorf("%w: metadata key %q must be 1-64 letters, digits, '_' or '-'", ErrInvalidLabels, k)
+		}
+		if len(v) > MaxMetadataValueLen {
+			return l, fmt.Errorf("%w: metadata %q is longer than %d bytes", ErrInvalidLabels, k, MaxMetadataValueLen)
+		}
+		// stored as JSON, which would replace invalid bytes and change
+		// what the entry's chain hash covers
+		if !utf8.ValidString(v) {
+			return l, fmt.Errorf("%w: metadata %q is not valid UTF-8", ErrInvalidLabels, k)
+		}
+	}
+
+	tags := make([]string, 0, len(l.Tags))
+	for _, tag := range l.Tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if !tagPattern.MatchString(tag) {
+			return l, fmt.Errorf("%w: tag %q must be 1-32 letters, digits, '_' or '-'", ErrInvalidLabels, tag)
+		}
+		tags = append(tags, tag)
+	}
+
+	slices.Sort(tags)
+	tags = slices.Compact(tags)
+	if len(tags) > MaxTags {
+		return l, fmt.Errorf("%w: at most %d tags", ErrInvalidLabels, MaxTags)
+	}
+
+	l.Tags = tags
+	return l, nil
+}
+
+// addTo copies the labels into an outbox payload.
+func (l Labels) addTo(payload map[string]interface{}) {
+	if len(l.Metadata) > 0 {
+		payload["metadata"] = l.Metadata
+	}
+	if len(l.Tags) > 0 {
+		payload["tags"] = l.Tags
+	}
+}
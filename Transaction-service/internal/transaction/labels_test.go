@@ -0,0 +1,100 @@
+package transaction_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"transaction/internal/transaction"
+)
+
+func TestLabels_RejectsInvalidBeforeAnyLookup(t *testing.T) {
+	// neither the account service nor the database is reached
+	service := transaction.NewTransactionService(nil, nil, nil)
+
+	var tooManyTags []string
+	for i := 0; i <= transaction.MaxTags; i++ {
+		tooManyTags = append(tooManyTags, strings.Repeat("t", i+1))
+	}
+
+	for name, labels := range map[string]transaction.Labels{
+		"bad key":       {Metadata: map[string]string{"invoice id": "1"}},
+		"long value":    {Metadata: map[string]string{"memo": strings.Repeat("x", transaction.MaxMetadataValueLen+1)}},
+		"bad tag":       {Tags: []string{"pay roll"}},
+		"empty tag":     {Tags: []string{" "}},
+		"too many tags": {Tags: tooManyTags},
+	} {
+		t.Run(name, func(t *testing.T) {
+			err := service.Deposit(context.Background(), "", 1, 1_000, "USD", "", labels)
+			if !errors.Is(err, transaction.ErrInvalidLabels) {
+				t.Fatalf("expected ErrInvalidLabels, got %v", err)
+			}
+		})
+	}
+}
+
+func TestLabels_StoredAndFiltered(t *testing.T) {
+	db := setupTestDB(t)
+	ctx := context.Background()
+
+	txRepo := transaction.NewPostgresRepo(db)
+	service := transaction.NewTransactionService(db, &MockAccountClient{}, txRepo)
+
+	employer := createTestAccount(t, db, "Employer")
+	employee := createTestAccount(t, db, "Employee")
+
+	if err := service.Deposit(ctx, "", employer.ID, 50_000, "USD", "funding", transaction.Labels{
+		Tags: []string{"Treasury"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := service.Transfer(ctx, "", employer.ID, employee.ID, 20_000, "USD", "March salary", transaction.Labels{
+		Metadata: map[string]string{"invoice_id": "123"},
+		Tags:     []string{"payroll", "PAYROLL"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	page, err := service.History(ctx, employee.ID, transaction.HistoryFilter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(page.Entries) != 1 {
+		t.Fatalf("expected 1 entry, got %d", len(page.Entries))
+	}
+
+	credit := page.Entries[0]
+	if credit.Note != "March salary" {
+		t.Fatalf("expected the note as given, got %q", credit.Note)
+	}
+	if credit.CounterpartyID != employer.ID {
+		t.Fatalf("expected counterparty %d, got %d", employer.ID, credit.CounterpartyID)
+	}
+	if credit.Metadata["invoice_id"] != "123" || len(credit.Tags) != 1 || credit.Tags[0] != "payroll" {
+		t.Fatalf("unexpected labels %+v", credit.Labels)
+	}
+
+	for _, tt := range []struct {
+		name   string
+		filter transaction.HistoryFilter
+		want   int
+	}{
+		{"tag", transaction.HistoryFilter{Tags: []string{"payroll"}}, 1},
+		{"other tag", transaction.HistoryFilter{Tags: []string{"treasury"}}, 1},
+		{"metadata", transaction.HistoryFilter{Metadata: map[string]string{"invoice_id": "123"}}, 1},
+		{"metadata mismatch", transaction.HistoryFilter{Metadata: map[string]string{"invoice_id": "124"}}, 0},
+		{"tag and metadata", transaction.HistoryFilter{Tags: []string{"payroll"}, Metadata: map[string]string{"invoice_id": "123"}}, 1},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			page, err := service.History(ctx, employer.ID, tt.filter)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(page.Entries) != tt.want {
+				t.Fatalf("expected %d entries, got %d", tt.want, len(page.Entries))
+			}
+		})
+	}
+}
@@ -17,7 +17,7 @@ func TestDeposit_CreatesLedgerEntry(t *testing.T) {
 	acc := createTestAccount(t, db, "Charlie")
 	var err error
 
-	err = service.Deposit(ctx, key, acc.ID, 5_000, "salary")
+	err = service.Deposit(ctx, key, acc.ID, 5_000, "USD", "salary", transaction.Labels{})
 	if err != nil {
 		t.Fatalf("❌ Deposit failed for account %s: %v", acc.Name, err)
 
@@ -55,9 +55,9 @@ func TestWithdraw_CreatesLedgerEntry(t *testing.T) {
 	service := transaction.NewTransactionService(db, &MockAccountClient{}, txRepo)
 
 	acc := createTestAccount(t, db, "Bob")
-	service.Deposit(ctx, key, acc.ID, 10_000, "funding")
+	service.Deposit(ctx, key, acc.ID, 10_000, "USD", "funding", transaction.Labels{})
 
-	err := service.Withdraw(ctx, key, acc.ID, 3_000, "rent")
+	_, err := service.Withdraw(ctx, key, acc.ID, 3_000, "USD", "rent", transaction.Labels{})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -84,9 +84,9 @@ func TestTransfer_CreatesTwoLedgerEntries(t *testing.T) {
 	from := createTestAccount(t, db, "Sender")
 	to := createTestAccount(t, db, "Receiver")
 
-	service.Deposit(ctx, key, from.ID, 20_000, "funding")
+	service.Deposit(ctx, key, from.ID, 20_000, "USD", "funding", transaction.Labels{})
 
-	err := service.Transfer(ctx, from.ID, to.ID, 7_000, "payment")
+	_, err := service.Transfer(ctx, "", from.ID, to.ID, 7_000, "USD", "payment", transaction.Labels{})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -110,3 +110,75 @@ func TestTransfer_CreatesTwoLedgerEntries(t *testing.T) {
 		t.Fatalf("expected TRANSFER_IN, got %s", toEntries[0].Type)
 	}
 }
+
+func TestTransfer_PostsBalancedJournal(t *testing.T) {
+	db := setupTestDB(t)
+	ctx := context.Background()
+	key := "abc-123"
+
+	txRepo := transaction.NewPostgresRepo(db)
+	journalRepo := transaction.NewPostgresJournalRepo(db)
+	service := transaction.NewTransactionService(db, &MockAccountClient{}, txRepo)
+
+	from := createTestAccount(t, db, "Sender")
+	to := createTestAccount(t, db, "Receiver")
+
+	service.Deposit(ctx, key, from.ID, 20_000, "USD", "funding", transaction.Labels{})
+
+	if _, err := service.Transfer(ctx, "", from.ID, to.ID, 7_000, "USD", "payment", transaction.Labels{}); err != nil {
+		t.Fatal(err)
+	}
+
+	fromEntries, _ := txRepo.ListByAccount(ctx, from.ID)
+	toEntries, _ := txRepo.ListByAccount(ctx, to.ID)
+
+	if fromEntries[0].JournalID == 0 || fromEntries[0].JournalID != toEntries[0].JournalID {
+		t.Fatalf("expected both transfer legs in one journal, got %d and %d", fromEntries[0].JournalID, toEntries[0].JournalID)
+	}
+
+	journal, err := journalRepo.GetByID(ctx, fromEntries[0].JournalID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if journal.Type != transaction.JournalTransfer {
+		t.Fatalf("expected TRANSFER journal, got %s", journal.Type)
+	}
+
+	if len(journal.Postings) != 4 {
+		t.Fatalf("expected 4 postings, got %d", len(journal.Postings))
+	}
+
+	if !journal.Balanced() {
+		t.Fatalf("journal %d does not sum to zero: %+v", journal.ID, journal.Postings)
+	}
+}
+
+func TestJournalEntry_Balanced(t *testing.T) {
+	cases := []struct {
+		name     string
+		postings []transaction.Posting
+		want     bool
+	}{
+		{"empty", nil, false},
+		{"balanced", []transaction.Posting{
+			{AccountID: 1, Amount: 500},
+			{SystemAccount: transaction.SystemCash, Amount: -500},
+		}, true},
+		{"unbalanced", []transaction.Posting{
+			{AccountID: 1, Amount: 500},
+			{SystemAccount: transaction.SystemCash, Amount: -400},
+		}, false},
+		{"posting without account", []transaction.Posting{
+			{Amount: 500},
+			{SystemAccount: transaction.SystemCash, Amount: -500},
+		}, false},
+	}
+
+	for _, c := range cases {
+		j := &transaction.JournalEntry{Postings: c.postings}
+		if got := j.Balanced(); got != c.want {
+			t.Errorf("%s: expected Balanced() = %v, got %v", c.name, c.want, got)
+		}
+	}
+}
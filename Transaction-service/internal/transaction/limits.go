@@ -0,0 +1,223 @@
+package transaction
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+	"transaction/internal/infrastructure/database"
+	"transaction/pb"
+)
+
+var (
+	ErrLimitExceeded = errors.New("limit exceeded")
+	ErrInvalidLimits = errors.New("limits must be positive")
+	ErrInvalidScope  = errors.New("unknown limits scope")
+)
+
+// Limit names, as reported in LimitError.
+const (
+	LimitPerTransaction = "per_transaction"
+	LimitDaily          = "daily"
+	LimitRolling30Days  = "rolling_30_days"
+	LimitHourlyCount    = "hourly_count"
+)
+
+const (
+	limitsWindow30Days = 30 * 24 * time.Hour
+	limitsWindowHour   = time.Hour
+)
+
+// Limits caps what may leave an account through withdrawals and outgoing
+// transfers. Amounts are cents of the debited currency; a nil field is not
+// limited at this level.
+type Limits struct {
+	PerTransaction *int64 `json:"per_transaction,omitempty"`
+	Daily          *int64 `json:"daily,omitempty"`           // UTC calendar day
+	Rolling30Days  *int64 `json:"rolling_30_days,omitempty"` // trailing 30 days
+	HourlyCount    *int64 `json:"hourly_count,omitempty"`    // debits in the trailing hour
+}
+
+func (l *Limits) empty() bool {
+	return l.PerTransaction == nil && l.Daily == nil && l.Rolling30Days == nil && l.HourlyCount == nil
+}
+
+func (l *Limits) validate() error {
+	for _, v := range []*int64{l.PerTransaction, l.Daily, l.Rolling30Days, l.HourlyCount} {
+		if v != nil && *v <= 0 {
+			return ErrInvalidLimits
+		}
+	}
+	return nil
+}
+
+// override returns l with every field set in more specific replaced.
+func (l Limits) override(more *Limits) Limits {
+	if more.PerTransaction != nil {
+		l.PerTransaction = more.PerTransaction
+	}
+	if more.Daily != nil {
+		l.Daily = more.Daily
+	}
+	if more.Rolling30Days != nil {
+		l.Rolling30Days = more.Rolling30Days
+	}
+	if more.HourlyCount != nil {
+		l.HourlyCount = more.HourlyCount
+	}
+	return l
+}
+
+// LimitScope selects who a set of limits applies to: every account, one
+// tier, or one account. The zero value is the global scope.
+type LimitScope struct {
+	Tier      string
+	AccountID int64
+}
+
+// LimitError says which limit a debit ran into and when it frees up again.
+// ResetsAt is nil when waiting would not help.
+type LimitError struct {
+	Limit    string     `json:"limit"`
+	Max      int64      `json:"max"`
+	Used     int64      `json:"used"`
+	ResetsAt *time.Time `json:"resets_at,omitempty"`
+}
+
+func (e *LimitError) Error() string {
+	msg := fmt.Sprintf("%s limit of %d exceeded (%d used)", e.Limit, e.Max, e.Used)
+	if e.ResetsAt != nil {
+		msg += ", resets at " + e.ResetsAt.Format(time.RFC3339)
+	}
+	return msg
+}
+
+func (e *LimitError) Unwrap() error {
+	return ErrLimitExceeded
+}
+
+// Outflow is a past debit counted against the limits.
+type Outflow struct {
+	Amount    int64
+	CreatedAt time.Time
+}
+
+// checkLimits applies the account's effective limits to debits about to be
+// posted in currency. It reads past outflows through db, so it must run in
+// the transaction that posts the debits.
+func (s *Service) checkLimits(ctx context.Context, db database.DBTX, account *pb.GetAccountResponse, currency string, debits ...int64) error {
+	repo := NewPostgresLimitRepo(db)
+
+	limits, err := repo.Effective(ctx, account.GetTier(), account.GetId())
+	if err != nil || limits.empty() {
+		return err
+	}
+
+	now := time.Now().UTC()
+
+	outflows, err := repo.Outflows(ctx, account.GetId(), currency, now.Add(-limitsWindow30Days))
+	if err != nil {
+		return err
+	}
+
+	return evaluateLimits(limits, outflows, now, debits)
+}
+
+// evaluateLimits checks debits against limits given the outflows of the last
+// 30 days, oldest first.
+func evaluateLimits(limits *Limits, outflows []Outflow, now time.Time, debits []int64) error {
+	var total int64
+	for _, d := range debits {
+		if limits.PerTransaction != nil && d > *limits.PerTransaction {
+			return &LimitError{Limit: LimitPerTransaction, Max: *limits.PerTransaction, Used: d}
+		}
+		total += d
+	}
+
+	if limits.Daily != nil {
+		midnight := now.Truncate(24 * time.Hour)
+
+		var used int64
+		for _, o := range outflows {
+			if !o.CreatedAt.Before(midnight) {
+				used += o.Amount
+			}
+		}
+
+		if used+total > *limits.Daily {
+			err := &LimitError{Limit: LimitDaily, Max: *limits.Daily, Used: used}
+			if total <= *limits.Daily {
+				tomorrow := midnight.AddDate(0, 0, 1)
+				err.ResetsAt = &tomorrow
+			}
+			return err
+		}
+	}
+
+	if limits.Rolling30Days != nil {
+		if err := rollingLimit(LimitRolling30Days, *limits.Rolling30Days, outflows, now, limitsWindow30Days, total, func(o Outflow) int64 {
+			return o.Amount
+		}); err != nil {
+			return err
+		}
+	}
+
+	if limits.HourlyCount != nil {
+		if err := rollingLimit(LimitHourlyCount, *limits.HourlyCount, outflows, now, limitsWindowHour, int64(len(debits)), func(Outflow) int64 {
+			return 1
+		}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// rollingLimit checks need more against max over the trailing window. When
+// it does not fit, the limit resets once enough of the oldest outflows have
+// aged out of the window.
+func rollingLimit(name string, max int64, outflows []Outflow, now time.Time, window time.Duration, need int64, weight func(Outflow) int64) error {
+	start := now.Add(-window)
+
+	var inWindow []Outflow
+	var used int64
+	for _, o := range outflows {
+		if o.CreatedAt.After(start) {
+			inWindow = append(inWindow, o)
+			used += weight(o)
+		}
+	}
+
+	if used+need <= max {
+		return nil
+	}
+
+	err := &LimitError{Limit: name, Max: max, Used: used}
+	if need > max {
+		return err
+	}
+
+	for _, o := range inWindow {
+		used -= weight(o)
+		if used+need <= max {
+			resets := o.CreatedAt.Add(window)
+			err.ResetsAt = &resets
+			break
+		}
+	}
+
+	return err
+}
+
+func (s *Service) GetLimits(ctx context.Context, scope LimitScope) (*Limits, error) {
+	return s.limitRepo.Get(ctx, scope)
+}
+
+// SetLimits replaces the limits of scope. Fields left nil fall back to the
+// next broader scope.
+func (s *Service) SetLimits(ctx context.Context, scope LimitScope, limits *Limits) error {
+	if err := limits.validate(); err != nil {
+		return err
+	}
+	return s.limitRepo.Set(ctx, scope, limits)
+}
@@ -0,0 +1,73 @@
+package transaction
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi"
+)
+
+// limitScope reads the scope from the route: /limits/global,
+// /limits/tiers/{tier} or /limits/accounts/{accountID}.
+func limitScope(w http.ResponseWriter, r *http.Request) (LimitScope, bool) {
+	var scope LimitScope
+
+	if v := chi.URLParam(r, "accountID"); v != "" {
+		id, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || id <= 0 {
+			respondError(w, http.StatusBadRequest, "INVALID_ACCOUNT_ID", "Account ID must be a number")
+			return scope, false
+		}
+		scope.AccountID = id
+	}
+
+	scope.Tier = strings.ToLower(chi.URLParam(r, "tier"))
+	return scope, true
+}
+
+func (h *TransactionHandler) GetLimits(w http.ResponseWriter, r *http.Request) {
+	scope, ok := limitScope(w, r)
+	if !ok {
+		return
+	}
+
+	limits, err := h.service.GetLimits(r.Context(), scope)
+	if err != nil {
+		respondServiceError(w, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, SuccessResponse{
+		Status:  "success",
+		Message: "Limits retrieved successfully",
+		Data:    limits,
+	})
+}
+
+// SetLimits replaces the limits of the scope; omitted fields are inherited
+// from the broader scope.
+func (h *TransactionHandler) SetLimits(w http.ResponseWriter, r *http.Request) {
+	scope, ok := limitScope(w, r)
+	if !ok {
+		return
+	}
+
+	var limits Limits
+	if err := json.NewDecoder(r.Body).Decode(&limits); err != nil {
+		respondError(w, http.StatusBadRequest, "INVALID_REQUEST", "Invalid JSON payload")
+		return
+	}
+
+	if err := h.service.SetLimits(r.Context(), scope, &limits); err != nil {
+		respondServiceError(w, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, SuccessResponse{
+		Status:  "success",
+		Message: "Limits updated successfully",
+		Data:    limits,
+	})
+}
@@ -0,0 +1,159 @@
+package transaction
+
+import (
+	"context"
+	"database/sql"
+	"strconv"
+	"time"
+	"transaction/internal/infrastructure/database"
+)
+
+var _ LimitRepository = (*PostgresLimitRepo)(nil)
+
+type PostgresLimitRepo struct {
+	db database.DBTX
+}
+
+func NewPostgresLimitRepo(db database.DBTX) *PostgresLimitRepo {
+	return &PostgresLimitRepo{db: db}
+}
+
+// limitScopeKey is how a scope is stored: ("global", ""), ("tier", name)
+// or ("account", id).
+func limitScopeKey(scope LimitScope) (string, string, error) {
+	switch {
+	case scope.Tier != "" && scope.AccountID != 0:
+		return "", "", ErrInvalidScope
+	case scope.Tier != "":
+		return "tier", scope.Tier, nil
+	case scope.AccountID != 0:
+		return "account", strconv.FormatInt(scope.AccountID, 10), nil
+	}
+	return "global", "", nil
+}
+
+func scanLimits(row rowScanner) (*Limits, error) {
+	var perTransaction, daily, rolling, hourly sql.NullInt64
+	if err := row.Scan(&perTransaction, &daily, &rolling, &hourly); err != nil {
+		return nil, err
+	}
+
+	limits := &Limits{}
+	for _, f := range []struct {
+		v   sql.NullInt64
+		dst **int64
+	}{
+		{perTransaction, &limits.PerTransaction},
+		{daily, &limits.Daily},
+		{rolling, &limits.Rolling30Days},
+		{hourly, &limits.HourlyCount},
+	} {
+		if f.v.Valid {
+			v := f.v.Int64
+			*f.dst = &v
+		}
+	}
+	return limits, nil
+}
+
+func nullLimit(v *int64) sql.NullInt64 {
+	if v == nil {
+		return sql.NullInt64{}
+	}
+	return sql.NullInt64{Int64: *v, Valid: true}
+}
+
+// Get returns the limits stored for exactly scope, empty if none are.
+func (r *PostgresLimitRepo) Get(ctx context.Context, scope LimitScope) (*Limits, error) {
+	kind, id, err := limitScopeKey(scope)
+	if err != nil {
+		return nil, err
+	}
+
+	limits, err := scanLimits(r.db.QueryRowContext(ctx, `
+		SELECT per_transaction, daily, rolling_30_days, hourly_count
+		FROM transfer_limits
+		WHERE scope = $1 AND scope_id = $2
+	`, kind, id))
+	if err == sql.ErrNoRows {
+		return &Limits{}, nil
+	}
+	return limits, err
+}
+
+func (r *PostgresLimitRepo) Set(ctx context.Context, scope LimitScope, limits *Limits) error {
+	kind, id, err := limitScopeKey(scope)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.ExecContext(ctx, `
+		INSERT INTO transfer_limits (scope, scope_id, per_transaction, daily, rolling_30_days, hourly_count)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (scope, scope_id) DO UPDATE
+		SET per_transaction = EXCLUDED.per_transaction,
+		    daily = EXCLUDED.daily,
+		    rolling_30_days = EXCLUDED.rolling_30_days,
+		    hourly_count = EXCLUDED.hourly_count,
+		    updated_at = now()
+	`, kind, id,
+		nullLimit(limits.PerTransaction),
+		nullLimit(limits.Daily),
+		nullLimit(limits.Rolling30Days),
+		nullLimit(limits.HourlyCount),
+	)
+	return err
+}
+
+func (r *PostgresLimitRepo) Effective(ctx context.Context, tier string, accountID int64) (*Limits, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT per_transaction, daily, rolling_30_days, hourly_count
+		FROM transfer_limits
+		WHERE scope = 'global'
+		   OR (scope = 'tier' AND scope_id = $1)
+		   OR (scope = 'account' AND scope_id = $2)
+		ORDER BY CASE scope WHEN 'global' THEN 0 WHEN 'tier' THEN 1 ELSE 2 END
+	`, tier, strconv.FormatInt(accountID, 10))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var effective Limits
+	for rows.Next() {
+		limits, err := scanLimits(rows)
+		if err != nil {
+			return nil, err
+		}
+		effective = effective.override(limits)
+	}
+
+	return &effective, rows.Err()
+}
+
+func (r *PostgresLimitRepo) Outflows(ctx context.Context, accountID int64, currency string, since time.Time) ([]Outflow, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT amount, created_at
+		FROM transactions
+		WHERE account_id = $1
+		  AND currency = $2
+		  AND type IN ($3, $4)
+		  AND created_at > $5
+		ORDER BY created_at, id
+	`, accountID, currency, TypeWithdraw, TypeTransferOut, since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var outflows []Outflow
+	for rows.Next() {
+		var o Outflow
+		if err := rows.Scan(&o.Amount, &o.CreatedAt); err != nil {
+			return nil, err
+		}
+		outflows = append(outflows, o)
+	}
+
+	return outflows, rows.Err()
+}
@@ -0,0 +1,16 @@
+package transaction
+
+import (
+	"context"
+	"time"
+)
+
+type LimitRepository interface {
+	Get(ctx context.Context, scope LimitScope) (*Limits, error)
+	Set(ctx context.Context, scope LimitScope, limits *Limits) error
+	// Effective merges the global, tier and account limits; the most
+	// specific value of each field wins.
+	Effective(ctx context.Context, tier string, accountID int64) (*Limits, error)
+	// Outflows lists the account's debits in currency since, oldest first.
+	Outflows(ctx context.Context, accountID int64, currency string, since time.Time) ([]Outflow, error)
+}
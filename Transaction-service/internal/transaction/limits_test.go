@@ -0,0 +1,164 @@
+package transaction_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+	"transaction/internal/transaction"
+)
+
+func limit(v int64) *int64 {
+	return &v
+}
+
+func TestLimits_DailyResetsAtMidnight(t *testing.T) {
+	db := setupTestDB(t)
+	ctx := context.Background()
+
+	txRepo := transaction.NewPostgresRepo(db)
+	service := transaction.NewTransactionService(db, &MockAccountClient{}, txRepo)
+
+	acc := createTestAccount(t, db, "Saver")
+	if err := service.Deposit(ctx, "", acc.ID, 100_000, "USD", "funding", transaction.Labels{}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := service.SetLimits(ctx, transaction.LimitScope{}, &transaction.Limits{Daily: limit(10_000)}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := service.Withdraw(ctx, "", acc.ID, 8_000, "USD", "atm", transaction.Labels{}); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := service.Withdraw(ctx, "", acc.ID, 3_000, "USD", "atm again", transaction.Labels{})
+
+	var limitErr *transaction.LimitError
+	if !errors.As(err, &limitErr) || !errors.Is(err, transaction.ErrLimitExceeded) {
+		t.Fatalf("expected a LimitError, got %v", err)
+	}
+
+	if limitErr.Limit != transaction.LimitDaily || limitErr.Used != 8_000 {
+		t.Fatalf("expected daily limit with 8000 used, got %+v", limitErr)
+	}
+
+	tomorrow := time.Now().UTC().Truncate(24*time.Hour).AddDate(0, 0, 1)
+	if limitErr.ResetsAt == nil || !limitErr.ResetsAt.Equal(tomorrow) {
+		t.Fatalf("expected reset at %v, got %v", tomorrow, limitErr.ResetsAt)
+	}
+
+	// what is left of today's limit can still go out
+	if _, err := service.Withdraw(ctx, "", acc.ID, 2_000, "USD", "exact", transaction.Labels{}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLimits_AccountOverridesTierAndGlobal(t *testing.T) {
+	db := setupTestDB(t)
+	ctx := context.Background()
+
+	business := createTestAccount(t, db, "Business")
+	vip := createTestAccount(t, db, "VIP")
+	txRepo := transaction.NewPostgresRepo(db)
+	service := transaction.NewTransactionService(db, &MockAccountClient{
+		Tiers: map[int64]string{business.ID: "business", vip.ID: "business"},
+	}, txRepo)
+
+	for _, id := range []int64{business.ID, vip.ID} {
+		if err := service.Deposit(ctx, "", id, 100_000, "USD", "funding", transaction.Labels{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	scopes := map[transaction.LimitScope]*transaction.Limits{
+		{}:                  {PerTransaction: limit(1_000), HourlyCount: limit(2)},
+		{Tier: "business"}:  {PerTransaction: limit(5_000)},
+		{AccountID: vip.ID}: {PerTransaction: limit(50_000)},
+	}
+	for scope, limits := range scopes {
+		if err := service.SetLimits(ctx, scope, limits); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var limitErr *transaction.LimitError
+	_, err := service.Withdraw(ctx, "", business.ID, 6_000, "USD", "over tier", transaction.Labels{})
+	if !errors.As(err, &limitErr) || limitErr.Limit != transaction.LimitPerTransaction || limitErr.ResetsAt != nil {
+		t.Fatalf("expected the tier per-transaction limit, got %v", err)
+	}
+
+	if _, err := service.Transfer(ctx, "", vip.ID, business.ID, 40_000, "USD", "within account limit", transaction.Labels{}); err != nil {
+		t.Fatal(err)
+	}
+
+	// the hourly count is still inherited from the global limits
+	if _, err := service.Withdraw(ctx, "", vip.ID, 1_000, "USD", "second", transaction.Labels{}); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = service.Withdraw(ctx, "", vip.ID, 1_000, "USD", "third", transaction.Labels{})
+	if !errors.As(err, &limitErr) || limitErr.Limit != transaction.LimitHourlyCount || limitErr.ResetsAt == nil {
+		t.Fatalf("expected the hourly count limit with a reset time, got %v", err)
+	}
+
+	if until := time.Until(*limitErr.ResetsAt); until <= 0 || until > time.Hour {
+		t.Fatalf("expected a reset within the hour, got %v", limitErr.ResetsAt)
+	}
+}
+
+func TestLimits_RejectNonPositive(t *testing.T) {
+	service := transaction.NewTransactionService(nil, &MockAccountClient{}, nil)
+
+	err := service.SetLimits(context.Background(), transaction.LimitScope{}, &transaction.Limits{Daily: limit(0)})
+	if !errors.Is(err, transaction.ErrInvalidLimits) {
+		t.Fatalf("expected ErrInvalidLimits, got %v", err)
+	}
+}
+
+func TestLimits_AppliedOnHoldCapture(t *testing.T) {
+	db := setupTestDB(t)
+	ctx := context.Background()
+
+	txRepo := transaction.NewPostgresRepo(db)
+	service := transaction.NewTransactionService(db, &MockAccountClient{}, txRepo)
+
+	acc := createTestAccount(t, db, "Card Holder")
+	if err := service.Deposit(ctx, "", acc.ID, 100_000, "USD", "funding", transaction.Labels{}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := service.SetLimits(ctx, transaction.LimitScope{}, &transaction.Limits{PerTransaction: limit(5_000), Daily: limit(8_000)}); err != nil {
+		t.Fatal(err)
+	}
+
+	large, err := service.PlaceHold(ctx, acc.ID, 6_000, "USD", time.Hour, "car rental")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var limitErr *transaction.LimitError
+	_, err = service.CaptureHold(ctx, large.ID, 0, "")
+	if !errors.As(err, &limitErr) || limitErr.Limit != transaction.LimitPerTransaction {
+		t.Fatalf("expected the per-transaction limit, got %v", err)
+	}
+
+	if _, err := service.Withdraw(ctx, "", acc.ID, 4_000, "USD", "atm", transaction.Labels{}); err != nil {
+		t.Fatal(err)
+	}
+
+	small, err := service.PlaceHold(ctx, acc.ID, 4_500, "USD", time.Hour, "groceries")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = service.CaptureHold(ctx, small.ID, 0, "")
+	if !errors.As(err, &limitErr) || limitErr.Limit != transaction.LimitDaily {
+		t.Fatalf("expected the daily limit, got %v", err)
+	}
+
+	// a smaller capture fits what is left of the day
+	if _, err := service.CaptureHold(ctx, small.ID, 4_000, ""); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -1,24 +1,135 @@
 package transaction
 
-import "time"
+import (
+	"slices"
+	"time"
 
-type Type string
+	"github.com/google/uuid"
+)
 
+type Type string
 
 const (
 	TypeDeposit     Type = "DEPOSIT"
 	TypeWithdraw    Type = "WITHDRAW"
 	TypeTransferIn  Type = "TRANSFER_IN"
 	TypeTransferOut Type = "TRANSFER_OUT"
+	TypeReversalIn  Type = "REVERSAL_IN"
+	TypeReversalOut Type = "REVERSAL_OUT"
+	TypeFee         Type = "FEE"
+	TypeInterest    Type = "INTEREST"
 )
 
+// creditTypes are the types whose entries increase the account balance.
+var creditTypes = []Type{TypeDeposit, TypeTransferIn, TypeReversalIn, TypeInterest}
+
+// Credit reports whether entries of this type increase the account balance.
+func (t Type) Credit() bool {
+	return slices.Contains(creditTypes, t)
+}
+
+func (t Type) Valid() bool {
+	switch t {
+	case TypeDeposit, TypeWithdraw, TypeTransferIn, TypeTransferOut, TypeReversalIn, TypeReversalOut, TypeFee, TypeInterest:
+		return true
+	}
+	return false
+}
 
+func (t Type) Reversal() bool {
+	return t == TypeReversalIn || t == TypeReversalOut
+}
 
 type Transaction struct {
-	ID        int64     `json:"id"`
-	AccountID int64     `json:"account_id"`
-	Type      Type      `json:"type"`
-	Amount    int64     `json:"amount"` // cents, always positive
-	Note      string    `json:"note"`
+	ID             int64  `json:"id"`
+	AccountID      int64  `json:"account_id"`
+	JournalID      int64  `json:"journal_id"`
+	Type           Type   `json:"type"`
+	Amount         int64  `json:"amount"` // cents, always positive
+	Currency       string `json:"currency"`
+	BalanceAfter   int64  `json:"balance_after"` // account balance once this entry is applied
+	ReversalOf     int64  `json:"reversal_of,omitempty"`
+	CounterpartyID int64  `json:"counterparty_account_id,omitempty"` // the other side of a transfer
+	FeeFor         int64  `json:"fee_for,omitempty"`                 // the entry a FEE was charged with
+	Note           string `json:"note"`
+	Labels
 	CreatedAt time.Time `json:"created_at"`
-}
\ No newline at end of file
+
+	// position in the account's hash chain, see chain.go
+	ChainSeq int64  `json:"-"`
+	PrevHash []byte `json:"-"`
+	Hash     []byte `json:"-"`
+}
+
+// SignedAmount is the effect of the entry on the account balance.
+func (t *Transaction) SignedAmount() int64 {
+	if t.Type.Credit() {
+		return t.Amount
+	}
+	return -t.Amount
+}
+
+type JournalType string
+
+const (
+	JournalDeposit  JournalType = "DEPOSIT"
+	JournalWithdraw JournalType = "WITHDRAW"
+	JournalTransfer JournalType = "TRANSFER"
+	JournalReversal JournalType = "REVERSAL"
+	JournalFee      JournalType = "FEE"
+	JournalInterest JournalType = "INTEREST"
+)
+
+// SystemAccount is an internal ledger account that balances customer
+// postings. Cash is the bank's money on hand, clearing holds funds in
+// flight between two customer accounts, fee income collects the fees
+// charged to customers and interest expense pays the interest they earn.
+type SystemAccount string
+
+const (
+	SystemCash            SystemAccount = "CASH"
+	SystemClearing        SystemAccount = "CLEARING"
+	SystemFeeIncome       SystemAccount = "FEE_INCOME"
+	SystemInterestExpense SystemAccount = "INTEREST_EXPENSE"
+)
+
+// JournalEntry is the header of a double-entry journal. Its postings must
+// sum to zero.
+type JournalEntry struct {
+	ID         int64       `json:"id"`
+	Type       JournalType `json:"type"`
+	ReversesID int64       `json:"reverses_id,omitempty"`
+	BatchID    *uuid.UUID  `json:"batch_id,omitempty"` // shared by the legs of a batch transfer
+	Currency   string      `json:"currency"`           // shared by every posting
+	Note       string      `json:"note"`
+	Postings   []Posting   `json:"postings"`
+	CreatedAt  time.Time   `json:"created_at"`
+}
+
+// Posting is one line of a journal entry. Amount is signed: positive credits
+// the ledger account, negative debits it. Exactly one of AccountID and
+// SystemAccount is set.
+type Posting struct {
+	ID            int64         `json:"id"`
+	JournalID     int64         `json:"journal_id"`
+	AccountID     int64         `json:"account_id,omitempty"`
+	SystemAccount SystemAccount `json:"system_account,omitempty"`
+	Amount        int64         `json:"amount"`
+}
+
+// Balanced reports whether the journal has well-formed postings that sum to
+// zero.
+func (j *JournalEntry) Balanced() bool {
+	if len(j.Postings) == 0 {
+		return false
+	}
+
+	var sum int64
+	for _, p := range j.Postings {
+		if p.Amount == 0 || (p.AccountID == 0) == (p.SystemAccount == "") {
+			return false
+		}
+		sum += p.Amount
+	}
+	return sum == 0
+}
@@ -0,0 +1,173 @@
+package transaction
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+	"transaction/internal/infrastructure/database"
+	"transaction/pb"
+
+	"github.com/google/uuid"
+)
+
+// overdraftLimit is how far below zero the account may be taken in currency.
+// Credit lines are agreed in the account's own currency only.
+func overdraftLimit(account *pb.GetAccountResponse, currency string) int64 {
+	own, _ := resolveCurrency("", account.GetCurrency())
+	if currency != own {
+		return 0
+	}
+	return account.GetOverdraftLimit()
+}
+
+// checkFunds fails with ErrInsufficientFunds unless the available balance
+// plus the account's overdraft limit covers amount. When account is nil the
+// account service is only asked for the limit if the balance falls short.
+func (s *Service) checkFunds(ctx context.Context, db database.DBTX, account *pb.GetAccountResponse, accountID int64, currency string, amount int64) error {
+	available, err := availableBalance(ctx, db, accountID, currency)
+	if err != nil {
+		return err
+	}
+
+	if available >= amount {
+		return nil
+	}
+
+	if account == nil {
+		account, err = s.activeAccount(ctx, accountID)
+		if err != nil {
+			return err
+		}
+	}
+
+	if available+overdraftLimit(account, currency) < amount {
+		return ErrInsufficientFunds
+	}
+
+	return nil
+}
+
+// ChargeOverdraftFees posts a fee of amount cents against up to limit
+// balances that are below zero and have not been charged for day yet. Each
+// fee is recorded per account, currency and day, so running it again for the
+// same day charges nothing twice.
+func (s *Service) ChargeOverdraftFees(ctx context.Context, day time.Time, amount int64, limit int) (int, error) {
+	if amount <= 0 {
+		return 0, ErrInvalidAmount
+	}
+
+	day = day.UTC().Truncate(24 * time.Hour)
+
+	due, err := NewPostgresOverdraftFeeRepo(s.db).DueForFee(ctx, day, limit)
+	if err != nil {
+		return 0, err
+	}
+
+	charged := 0
+	for _, b := range due {
+		ok, err := s.chargeOverdraftFee(ctx, b.AccountID, b.Currency, day, amount)
+		if err != nil {
+			return charged, fmt.Errorf("account %d: %w", b.AccountID, err)
+		}
+
+		if ok {
+			charged++
+		}
+	}
+
+	return charged, nil
+}
+
+// errAlreadyCharged rolls back a fee another run recorded first.
+var errAlreadyCharged = errors.New("overdraft fee already charged")
+
+func (s *Service) chargeOverdraftFee(ctx context.Context, accountID int64, currency string, day time.Time, amount int64) (bool, error) {
+	var charged bool
+	err := s.runner.Run(ctx, "overdraft_fee", nil, func(tx *sql.Tx) error {
+		var err error
+		charged, err = s.postOverdraftFee(ctx, tx, accountID, currency, day, amount)
+		return err
+	})
+	if errors.Is(err, errAlreadyCharged) {
+		return false, nil
+	}
+
+	return charged, err
+}
+
+func (s *Service) postOverdraftFee(ctx context.Context, tx *sql.Tx, accountID int64, currency string, day time.Time, amount int64) (bool, error) {
+	if err := lockAccounts(ctx, tx, accountID); err != nil {
+		return false, err
+	}
+
+	transactionRepo := NewPostgresRepo(tx)
+	journalRepo := NewPostgresJournalRepo(tx)
+	outboxRepo := NewPostgresOutboxRepository(tx)
+
+	// the balance may have been topped up since it was listed
+	balance, err := transactionRepo.BalanceOf(ctx, accountID, currency)
+	if err != nil {
+		return false, err
+	}
+
+	if balance >= 0 {
+		return false, nil
+	}
+
+	note := fmt.Sprintf("Overdraft fee for %s", day.Format("2006-01-02"))
+
+	journal := feeJournal(accountID, amount, currency, note)
+	if err := journalRepo.Create(ctx, journal); err != nil {
+		return false, err
+	}
+
+	entry := &Transaction{
+		AccountID: accountID,
+		JournalID: journal.ID,
+		Type:      TypeFee,
+		Amount:    amount,
+		Currency:  currency,
+		Note:      note,
+	}
+
+	if err := transactionRepo.Create(ctx, entry); err != nil {
+		return false, err
+	}
+
+	recorded, err := NewPostgresOverdraftFeeRepo(tx).Record(ctx, accountID, currency, day, entry.ID)
+	if err != nil {
+		return false, err
+	}
+
+	if !recorded {
+		return false, errAlreadyCharged
+	}
+
+	payload, err := json.Marshal(map[string]interface{}{
+		"account_id": accountID,
+		"amount":     amount,
+		"currency":   currency,
+		"type":       "fee",
+		"reason":     "overdraft",
+		"note":       note,
+		"journal_id": entry.JournalID,
+	})
+	if err != nil {
+		return false, err
+	}
+
+	if err := outboxRepo.Add(ctx, &OutboxEvent{
+		ID:            uuid.New(),
+		AggregateType: "account",
+		AggregateID:   accountID,
+		EventType:     "transaction.created",
+		Payload:       payload,
+	}); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
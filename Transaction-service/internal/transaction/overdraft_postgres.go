@@ -0,0 +1,63 @@
+package transaction
+
+import (
+	"context"
+	"time"
+	"transaction/internal/infrastructure/database"
+)
+
+var _ OverdraftFeeRepository = (*PostgresOverdraftFeeRepo)(nil)
+
+type PostgresOverdraftFeeRepo struct {
+	db database.DBTX
+}
+
+func NewPostgresOverdraftFeeRepo(db database.DBTX) *PostgresOverdraftFeeRepo {
+	return &PostgresOverdraftFeeRepo{db: db}
+}
+
+func (r *PostgresOverdraftFeeRepo) DueForFee(ctx context.Context, day time.Time, limit int) ([]OverdrawnBalance, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT b.account_id, b.currency, b.balance
+		FROM account_balances b
+		WHERE b.balance < 0
+		  AND NOT EXISTS (
+			SELECT 1
+			FROM overdraft_fees f
+			WHERE f.account_id = b.account_id
+			  AND f.currency = b.currency
+			  AND f.charged_on = $1
+		  )
+		ORDER BY b.account_id, b.currency
+		LIMIT $2
+	`, day.Format("2006-01-02"), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var due []OverdrawnBalance
+	for rows.Next() {
+		var b OverdrawnBalance
+		if err := rows.Scan(&b.AccountID, &b.Currency, &b.Balance); err != nil {
+			return nil, err
+		}
+		due = append(due, b)
+	}
+
+	return due, rows.Err()
+}
+
+func (r *PostgresOverdraftFeeRepo) Record(ctx context.Context, accountID int64, currency string, day time.Time, transactionID int64) (bool, error) {
+	res, err := r.db.ExecContext(ctx, `
+		INSERT INTO overdraft_fees (account_id, currency, charged_on, transaction_id)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (account_id, currency, charged_on) DO NOTHING
+	`, accountID, currency, day.Format("2006-01-02"), transactionID)
+	if err != nil {
+		return false, err
+	}
+
+	n, err := res.RowsAffected()
+	return n == 1, err
+}
@@ -0,0 +1,23 @@
+package transaction
+
+import (
+	"context"
+	"time"
+)
+
+// OverdrawnBalance is an account balance that has gone below zero.
+type OverdrawnBalance struct {
+	AccountID int64
+	Currency  string
+	Balance   int64
+}
+
+// OverdraftFeeRepository tracks which overdrawn balances have already been
+// charged the daily overdraft fee.
+type OverdraftFeeRepository interface {
+	// DueForFee lists up to limit overdrawn balances not yet charged for day.
+	DueForFee(ctx context.Context, day time.Time, limit int) ([]OverdrawnBalance, error)
+	// Record claims the fee of one account, currency and day. It reports
+	// false if that fee was already charged.
+	Record(ctx context.Context, accountID int64, currency string, day time.Time, transactionID int64) (bool, error)
+}
@@ -0,0 +1,118 @@
+package transaction_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+	"transaction/internal/transaction"
+)
+
+func TestOverdraft_WithdrawWithinLimit(t *testing.T) {
+	db := setupTestDB(t)
+	ctx := context.Background()
+
+	acc := createTestAccount(t, db, "Business")
+	txRepo := transaction.NewPostgresRepo(db)
+	service := transaction.NewTransactionService(db, &MockAccountClient{
+		OverdraftLimits: map[int64]int64{acc.ID: 5_000},
+	}, txRepo)
+
+	if err := service.Deposit(ctx, "", acc.ID, 1_000, "USD", "funding", transaction.Labels{}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := service.Withdraw(ctx, "", acc.ID, 4_000, "USD", "supplier", transaction.Labels{}); err != nil {
+		t.Fatalf("withdraw within the overdraft should succeed: %v", err)
+	}
+
+	assertBalance(t, service, acc.ID, -3_000)
+
+	// 3000 overdrawn, 2000 of credit left
+	if _, err := service.Withdraw(ctx, "", acc.ID, 2_001, "USD", "too much", transaction.Labels{}); !errors.Is(err, transaction.ErrInsufficientFunds) {
+		t.Fatalf("expected ErrInsufficientFunds, got %v", err)
+	}
+
+	if _, err := service.PlaceHold(ctx, acc.ID, 2_000, "USD", time.Hour, "card"); err != nil {
+		t.Fatalf("hold within the overdraft should succeed: %v", err)
+	}
+
+	if _, err := service.Withdraw(ctx, "", acc.ID, 1, "USD", "held", transaction.Labels{}); !errors.Is(err, transaction.ErrInsufficientFunds) {
+		t.Fatalf("expected ErrInsufficientFunds once the limit is held, got %v", err)
+	}
+}
+
+func TestOverdraft_TransferFromOverdrawnAccount(t *testing.T) {
+	db := setupTestDB(t)
+	ctx := context.Background()
+
+	from := createTestAccount(t, db, "Business")
+	to := createTestAccount(t, db, "Supplier")
+	txRepo := transaction.NewPostgresRepo(db)
+	service := transaction.NewTransactionService(db, &MockAccountClient{
+		OverdraftLimits: map[int64]int64{from.ID: 10_000},
+	}, txRepo)
+
+	if _, err := service.Transfer(ctx, "", from.ID, to.ID, 10_000, "USD", "invoice", transaction.Labels{}); err != nil {
+		t.Fatalf("transfer within the overdraft should succeed: %v", err)
+	}
+
+	assertBalance(t, service, from.ID, -10_000)
+	assertBalance(t, service, to.ID, 10_000)
+
+	// the supplier has no credit line
+	if _, err := service.Transfer(ctx, "", to.ID, from.ID, 10_001, "USD", "refund", transaction.Labels{}); !errors.Is(err, transaction.ErrInsufficientFunds) {
+		t.Fatalf("expected ErrInsufficientFunds, got %v", err)
+	}
+}
+
+func TestOverdraft_DailyFeeChargedOnce(t *testing.T) {
+	db := setupTestDB(t)
+	ctx := context.Background()
+
+	overdrawn := createTestAccount(t, db, "Overdrawn")
+	funded := createTestAccount(t, db, "Funded")
+	txRepo := transaction.NewPostgresRepo(db)
+	service := transaction.NewTransactionService(db, &MockAccountClient{
+		OverdraftLimits: map[int64]int64{overdrawn.ID: 5_000},
+	}, txRepo)
+
+	if _, err := service.Withdraw(ctx, "", overdrawn.ID, 1_000, "USD", "atm", transaction.Labels{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := service.Deposit(ctx, "", funded.ID, 1_000, "USD", "salary", transaction.Labels{}); err != nil {
+		t.Fatal(err)
+	}
+
+	day := time.Date(2025, 3, 14, 9, 0, 0, 0, time.UTC)
+
+	n, err := service.ChargeOverdraftFees(ctx, day, 250, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 1 {
+		t.Fatalf("expected 1 fee, got %d", n)
+	}
+
+	// later the same day nothing is charged again
+	n, err = service.ChargeOverdraftFees(ctx, day.Add(10*time.Hour), 250, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 0 {
+		t.Fatalf("expected no fee on a second run, got %d", n)
+	}
+
+	assertBalance(t, service, overdrawn.ID, -1_250)
+	assertBalance(t, service, funded.ID, 1_000)
+
+	n, err = service.ChargeOverdraftFees(ctx, day.AddDate(0, 0, 1), 250, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 1 {
+		t.Fatalf("expected the fee again the next day, got %d", n)
+	}
+
+	assertBalance(t, service, overdrawn.ID, -1_500)
+}
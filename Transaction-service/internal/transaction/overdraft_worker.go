@@ -0,0 +1,60 @@
+package transaction
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// OverdraftFeeWorker charges the daily overdraft fee on every balance that
+// is below zero. It checks hourly; each balance is charged once per UTC day.
+type OverdraftFeeWorker struct {
+	service   *Service
+	fee       int64
+	interval  time.Duration
+	batchSize int
+}
+
+func NewOverdraftFeeWorker(service *Service, fee int64) *OverdraftFeeWorker {
+	return &OverdraftFeeWorker{
+		service:   service,
+		fee:       fee,
+		interval:  time.Hour,
+		batchSize: 100,
+	}
+}
+
+func (w *OverdraftFeeWorker) Start(ctx context.Context) {
+	log.Println("🚀 Overdraft fee worker started")
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Println("🛑 Overdraft fee worker stopped")
+			return
+
+		case <-ticker.C:
+			day := time.Now()
+
+			// drain everything that is due before waiting again
+			for {
+				n, err := w.service.ChargeOverdraftFees(ctx, day, w.fee, w.batchSize)
+				if err != nil {
+					log.Println("❌ Charge overdraft fees failed:", err)
+					break
+				}
+
+				if n > 0 {
+					log.Printf("💸 Charged %d overdraft fees", n)
+				}
+
+				if n < w.batchSize {
+					break
+				}
+			}
+		}
+	}
+}
@@ -2,15 +2,72 @@ package transaction
 
 import (
 	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
 	"transaction/internal/infrastructure/database"
-	//"transaction/Transaction-service/internal/infrastructure/database"
-	//"transaction/internal/infrastructure/database"
-	//"database/sql"
-	//"transaction/internal/infrastructure/database"
+
+	"github.com/lib/pq"
 )
 
 var _ TransactionRepository = (*PostgresRepo)(nil)
 
+const transactionColumns = `id, account_id, COALESCE(journal_id, 0), type, amount, currency, balance_after,
+	COALESCE(reversal_of, 0), COALESCE(counterparty_account_id, 0), COALESCE(fee_for, 0), note, metadata, tags, created_at`
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanTransaction(row rowScanner) (Transaction, error) {
+	var (
+		t        Transaction
+		metadata []byte
+	)
+	err := row.Scan(
+		&t.ID,
+		&t.AccountID,
+		&t.JournalID,
+		&t.Type,
+		&t.Amount,
+		&t.Currency,
+		&t.BalanceAfter,
+		&t.ReversalOf,
+		&t.CounterpartyID,
+		&t.FeeFor,
+		&t.Note,
+		&metadata,
+		(*pq.StringArray)(&t.Tags),
+		&t.CreatedAt,
+	)
+	if err != nil {
+		return t, err
+	}
+
+	return t, json.Unmarshal(metadata, &t.Metadata)
+}
+
+// labelArgs returns the metadata and tags columns of an entry. Both are NOT
+// NULL, so missing labels are stored empty.
+func labelArgs(l Labels) (string, any, error) {
+	metadata := "{}"
+	if len(l.Metadata) > 0 {
+		raw, err := json.Marshal(l.Metadata)
+		if err != nil {
+			return "", nil, err
+		}
+		metadata = string(raw)
+	}
+
+	tags := l.Tags
+	if tags == nil {
+		tags = []string{}
+	}
+	return metadata, pq.Array(tags), nil
+}
+
 type PostgresRepo struct {
 	db database.DBTX
 }
@@ -19,26 +76,111 @@ func NewPostgresRepo(db database.DBTX) *PostgresRepo {
 	return &PostgresRepo{db: db}
 }
 
-
+// Create applies the entry to the account's balance row for its currency and stores the
+// resulting balance on the entry in a single statement, so the two can never
+// drift apart. Concurrent writers for the same account queue on the balance
+// row lock.
+//
+// The entry is then hashed onto the account's chain. The chain head is
+// locked first, so entries join the chain in the order they are written;
+// an account's first entry also chains any entries written before hashing.
 func (r *PostgresRepo) Create(ctx context.Context, tx *Transaction) error {
-	query := `
-	        INSERT INTO transactions (account_id, amount, type, note) 
-			VALUES ($1, $2, $3, $4)
+	metadata, tags, err := labelArgs(tx.Labels)
+	if err != nil {
+		return err
+	}
+
+	head, err := lockChainHead(ctx, r.db, tx.AccountID)
+	if err != nil {
+		return err
+	}
 
+	if head.Entries == 0 {
+		if err := sealLegacy(ctx, r.db, head); err != nil {
+			return err
+		}
+	}
+
+	query := `
+	        WITH balance AS (
+				INSERT INTO account_balances (account_id, currency, balance)
+				VALUES ($1, $8, $6)
+				ON CONFLICT (account_id, currency) DO UPDATE
+				SET balance = account_balances.balance + EXCLUDED.balance,
+				    updated_at = now()
+				RETURNING balance
+			)
+	        INSERT INTO transactions (account_id, journal_id, amount, type, note, reversal_of, currency, balance_after,
+				counterparty_account_id, fee_for, metadata, tags)
+			SELECT $1, $2, $3, $4, $5, $7, $8, balance, $9, $10, $11, $12 FROM balance
+			RETURNING id, balance_after, created_at
 	`
-	_, err := r.db.ExecContext(ctx, query, tx.AccountID, tx.Amount, tx.Type, tx.Note)
+	err = r.db.QueryRowContext(ctx, query,
+		tx.AccountID, nullID(tx.JournalID), tx.Amount, tx.Type, tx.Note, tx.SignedAmount(), nullID(tx.ReversalOf), tx.Currency,
+		nullID(tx.CounterpartyID), nullID(tx.FeeFor), metadata, tags,
+	).Scan(&tx.ID, &tx.BalanceAfter, &tx.CreatedAt)
+	if err != nil {
+		return err
+	}
+
+	return appendToChain(ctx, r.db, head, tx)
+}
 
-	return err
+func (r *PostgresRepo) GetByID(ctx context.Context, id int64) (*Transaction, error) {
+	t, err := scanTransaction(r.db.QueryRowContext(ctx, `
+		SELECT `+transactionColumns+`
+		FROM transactions
+		WHERE id = $1
+	`, id))
+	if err == sql.ErrNoRows {
+		return nil, ErrTransactionNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
 }
 
+func (r *PostgresRepo) ListByJournal(ctx context.Context, journalID int64) ([]Transaction, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+transactionColumns+`
+		FROM transactions
+		WHERE journal_id = $1
+		ORDER BY id
+	`, journalID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
 
+	var result []Transaction
+	for rows.Next() {
+		t, err := scanTransaction(rows)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, t)
+	}
+	return result, rows.Err()
+}
+
+// ReversedAmount sums every reversal already posted against the entry.
+func (r *PostgresRepo) ReversedAmount(ctx context.Context, id int64) (int64, error) {
+	var amount int64
+	err := r.db.QueryRowContext(ctx, `
+		SELECT COALESCE(SUM(amount), 0)
+		FROM transactions
+		WHERE reversal_of = $1
+	`, id).Scan(&amount)
+	return amount, err
+}
 
 func (r *PostgresRepo) ListByAccount(ctx context.Context, accountID int64) ([]Transaction, error) {
 	query := `
-		SELECT id, account_id, type, amount, note, created_at
+		SELECT ` + transactionColumns + `
 		FROM transactions
 		WHERE account_id = $1
-		ORDER BY created_at DESC
+		ORDER BY created_at DESC, id DESC
 	`
 
 	rows, err := r.db.QueryContext(ctx, query, accountID)
@@ -50,15 +192,8 @@ func (r *PostgresRepo) ListByAccount(ctx context.Context, accountID int64) ([]Tr
 	var result []Transaction
 
 	for rows.Next() {
-		var t Transaction
-		if err := rows.Scan(
-			&t.ID,
-			&t.AccountID,
-			&t.Type,
-			&t.Amount,
-			&t.Note,
-			&t.CreatedAt,
-		); err != nil {
+		t, err := scanTransaction(rows)
+		if err != nil {
 			return nil, err
 		}
 		result = append(result, t)
@@ -67,30 +202,256 @@ func (r *PostgresRepo) ListByAccount(ctx context.Context, accountID int64) ([]Tr
 	return result, nil
 }
 
+func (r *PostgresRepo) EachByAccount(ctx context.Context, accountID int64, filter ExportFilter, fn func(Transaction) error) error {
+	where := []string{"account_id = $1"}
+	args := []any{accountID}
+	arg := func(v any) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	if filter.Currency != "" {
+		where = append(where, "currency = "+arg(filter.Currency))
+	}
+	if filter.From != nil {
+		where = append(where, "created_at >= "+arg(*filter.From))
+	}
+	if filter.To != nil {
+		where = append(where, "created_at < "+arg(*filter.To))
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+transactionColumns+`
+		FROM transactions
+		WHERE `+strings.Join(where, " AND ")+`
+		ORDER BY created_at, id
+	`, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		t, err := scanTransaction(rows)
+		if err != nil {
+			return err
+		}
+
+		if err := fn(t); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
+// ListPage returns one page of an account's history, newest first, using
+// keyset pagination on (created_at, id) so deep pages stay cheap.
+func (r *PostgresRepo) ListPage(ctx context.Context, accountID int64, filter HistoryFilter) (*HistoryPage, error) {
+	filter = filter.normalize()
+
+	where := []string{"account_id = $1"}
+	args := []any{accountID}
+	arg := func(v any) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	if filter.Cursor != "" {
+		cursor, err := decodeCursor(filter.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		where = append(where, fmt.Sprintf("(created_at, id) < (%s, %s)", arg(cursor.CreatedAt), arg(cursor.ID)))
+	}
+
+	if len(filter.Types) > 0 {
+		placeholders := make([]string, len(filter.Types))
+		for i, t := range filter.Types {
+			placeholders[i] = arg(t)
+		}
+		where = append(where, fmt.Sprintf("type IN (%s)", strings.Join(placeholders, ", ")))
+	}
+
+	if filter.From != nil {
+		where = append(where, "created_at >= "+arg(*filter.From))
+	}
+	if filter.To != nil {
+		where = append(where, "created_at < "+arg(*filter.To))
+	}
+	if filter.AsOf != nil {
+		where = append(where, "created_at <= "+arg(*filter.AsOf))
+	}
+	if filter.MinAmount != nil {
+		where = append(where, "amount >= "+arg(*filter.MinAmount))
+	}
+	if filter.MaxAmount != nil {
+		where = append(where, "amount <= "+arg(*filter.MaxAmount))
+	}
+	if filter.NoteContains != "" {
+		where = append(where, fmt.Sprintf("strpos(lower(note), lower(%s)) > 0", arg(filter.NoteContains)))
+	}
+	if len(filter.Tags) > 0 {
+		where = append(where, "tags @> "+arg(pq.Array(filter.Tags)))
+	}
+	if len(filter.Metadata) > 0 {
+		metadata, err := json.Marshal(filter.Metadata)
+		if err != nil {
+			return nil, err
+		}
+		where = append(where, "metadata @> "+arg(string(metadata))+"::jsonb")
+	}
+
+	// fetch one extra row to know whether another page exists
+	query := fmt.Sprintf(`
+		SELECT `+transactionColumns+`
+		FROM transactions
+		WHERE %s
+		ORDER BY created_at DESC, id DESC
+		LIMIT %s
+	`, strings.Join(where, " AND "), arg(filter.Limit+1))
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	page := &HistoryPage{Entries: []Transaction{}}
+	for rows.Next() {
+		t, err := scanTransaction(rows)
+		if err != nil {
+			return nil, err
+		}
+		page.Entries = append(page.Entries, t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(page.Entries) > filter.Limit {
+		page.Entries = page.Entries[:filter.Limit]
+		page.NextCursor = encodeCursor(page.Entries[filter.Limit-1])
+	}
+
+	return page, nil
+}
+
+// BalanceByAccount reads the maintained balance rows instead of summing the
+// account's history. There is one row per currency the account has posted in.
+func (r *PostgresRepo) BalanceByAccount(ctx context.Context, accountID int64) ([]Balance, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT currency, balance
+		FROM account_balances
+		WHERE account_id = $1
+		ORDER BY currency
+	`, accountID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var balances []Balance
+	for rows.Next() {
+		var b Balance
+		if err := rows.Scan(&b.Currency, &b.Ledger); err != nil {
+			return nil, err
+		}
+		balances = append(balances, b)
+	}
+	return balances, rows.Err()
+}
+
+func (r *PostgresRepo) BalanceOf(ctx context.Context, accountID int64, currency string) (int64, error) {
+	var balance int64
+	err := r.db.QueryRowContext(ctx, `
+		SELECT balance
+		FROM account_balances
+		WHERE account_id = $1 AND currency = $2
+	`, accountID, currency).Scan(&balance)
+
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	return balance, err
+}
+
+// signedAmount is the effect of a transactions row on its balance; $1 must
+// be the credit types.
+const signedAmount = `CASE WHEN t.type = ANY($1) THEN t.amount ELSE -t.amount END`
+
+func creditTypeArray() any {
+	types := make([]string, len(creditTypes))
+	for i, t := range creditTypes {
+		types[i] = string(t)
+	}
+	return pq.Array(types)
+}
 
-func (r *PostgresRepo) BalanceByAccount(ctx context.Context, accountId int64) (int64, error) {
+func (r *PostgresRepo) BalanceAt(ctx context.Context, accountID int64, currency string, asOf time.Time) (int64, error) {
 	var balance int64
-	err := r.db.QueryRowContext(ctx,
-	 `
-	     SELECT COALESCE(SUM(
-		 CASE 
-		     WHEN type IN ($2, $3) THEN amount
-			 WHEN type IN ($4, $5) THEN -amount
-			 ELSE 0
-		 END
-		 ), 0)
-		 FROM transactions
-		 WHERE account_id = $1
-	`,
-	     
-	    accountId,
-		TypeDeposit,
-		TypeTransferIn,
-		TypeWithdraw,
-		TypeTransferOut,
-
-	).Scan(&balance)
-	
-	return  balance, err
-
-}
\ No newline at end of file
+	err := r.db.QueryRowContext(ctx, `
+		WITH checkpoint AS (
+			SELECT as_of, balance
+			FROM balance_checkpoints
+			WHERE account_id = $2 AND currency = $3 AND as_of <= $4
+			ORDER BY as_of DESC
+			LIMIT 1
+		)
+		SELECT COALESCE((SELECT balance FROM checkpoint), 0) + COALESCE((
+			SELECT SUM(`+signedAmount+`)
+			FROM transactions t
+			WHERE t.account_id = $2 AND t.currency = $3
+			  AND t.created_at <= $4
+			  AND t.created_at >= COALESCE((SELECT as_of FROM checkpoint), '-infinity')
+		), 0)
+	`, creditTypeArray(), accountID, currency, asOf).Scan(&balance)
+	return balance, err
+}
+
+func (r *PostgresRepo) CreateCheckpoints(ctx context.Context, at time.Time, limit int) (int, error) {
+	res, err := r.db.ExecContext(ctx, `
+		INSERT INTO balance_checkpoints (account_id, currency, as_of, balance)
+		SELECT b.account_id, b.currency, $2,
+		       COALESCE(prev.balance, 0) + COALESCE((
+				SELECT SUM(`+signedAmount+`)
+				FROM transactions t
+				WHERE t.account_id = b.account_id AND t.currency = b.currency
+				  AND t.created_at < $2
+				  AND t.created_at >= COALESCE(prev.as_of, '-infinity')
+		       ), 0)
+		FROM account_balances b
+		LEFT JOIN LATERAL (
+			SELECT as_of, balance
+			FROM balance_checkpoints c
+			WHERE c.account_id = b.account_id AND c.currency = b.currency AND c.as_of < $2
+			ORDER BY as_of DESC
+			LIMIT 1
+		) prev ON true
+		WHERE EXISTS (
+			SELECT 1
+			FROM transactions t
+			WHERE t.account_id = b.account_id AND t.currency = b.currency
+			  AND t.created_at < $2
+			  AND t.created_at >= COALESCE(prev.as_of, '-infinity')
+		  )
+		  AND NOT EXISTS (
+			SELECT 1
+			FROM balance_checkpoints c
+			WHERE c.account_id = b.account_id AND c.currency = b.currency AND c.as_of = $2
+		  )
+		ORDER BY b.account_id, b.currency
+		LIMIT $3
+		ON CONFLICT DO NOTHING
+	`, creditTypeArray(), at, limit)
+	if err != nil {
+		return 0, err
+	}
+
+	n, err := res.RowsAffected()
+	return int(n), err
+}
+
+func nullID(id int64) sql.NullInt64 {
+	return sql.NullInt64{Int64: id, Valid: id != 0}
+}
@@ -0,0 +1,256 @@
+package transaction
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// MaxReconciliationWindow caps how much of the ledger one run compares.
+	MaxReconciliationWindow = 31 * 24 * time.Hour
+
+	// outboxStuckAfter is how long an event may stay pending before it is
+	// reported; the outbox worker polls every second.
+	outboxStuckAfter = 5 * time.Minute
+
+	// reconcileBatch caps each list of a report.
+	reconcileBatch = 1000
+)
+
+var ErrInvalidWindow = fmt.Errorf("window must be non-empty and at most %s", MaxReconciliationWindow)
+
+// ReconciledEvent is an outbox event reported by a reconciliation.
+type ReconciledEvent struct {
+	ID          uuid.UUID       `json:"id"`
+	AggregateID int64           `json:"aggregate_id"`
+	EventType   string          `json:"event_type"`
+	Status      string          `json:"status"`
+	Payload     json.RawMessage `json:"payload"`
+	CreatedAt   time.Time       `json:"created_at"`
+}
+
+// ReconciliationReport compares the ledger with the outbox over [From, To).
+// An event covers the entries of the journal named by its journal_id;
+// events written before journal_id was added cover the entry of the same
+// account, amount and commit time.
+type ReconciliationReport struct {
+	From          time.Time         `json:"from"`
+	To            time.Time         `json:"to"`
+	MissingEvents []Transaction     `json:"missing_events"` // ledger entries no event covers
+	StuckEvents   []ReconciledEvent `json:"stuck_events"`   // pending for longer than outboxStuckAfter
+	OrphanEvents  []ReconciledEvent `json:"orphan_events"`  // events covering no ledger entry
+	Backfilled    int               `json:"backfilled"`
+}
+
+// Clean reports whether the ledger and outbox agree.
+func (r *ReconciliationReport) Clean() bool {
+	return len(r.MissingEvents) == 0 && len(r.StuckEvents) == 0 && len(r.OrphanEvents) == 0
+}
+
+func validWindow(from, to time.Time) error {
+	if !to.After(from) || to.Sub(from) > MaxReconciliationWindow {
+		return ErrInvalidWindow
+	}
+	return nil
+}
+
+// Reconcile reports the ledger entries in [from, to) no event covers, the
+// events of the window still pending, and the events no entry matches.
+// Each list holds at most reconcileBatch items.
+func (s *Service) Reconcile(ctx context.Context, from, to time.Time) (*ReconciliationReport, error) {
+	if err := validWindow(from, to); err != nil {
+		return nil, err
+	}
+
+	return reconcile(ctx, s.reconciliationRepo, from.UTC(), to.UTC())
+}
+
+func reconcile(ctx context.Context, repo ReconciliationRepository, from, to time.Time) (*ReconciliationReport, error) {
+	report := &ReconciliationReport{From: from, To: to}
+
+	var err error
+	if report.MissingEvents, err = repo.MissingEvents(ctx, from, to, reconcileBatch); err != nil {
+		return nil, err
+	}
+
+	if report.StuckEvents, err = repo.StuckEvents(ctx, from, to, time.Now().Add(-outboxStuckAfter), reconcileBatch); err != nil {
+		return nil, err
+	}
+
+	if report.OrphanEvents, err = repo.OrphanEvents(ctx, from, to, reconcileBatch); err != nil {
+		return nil, err
+	}
+
+	return report, nil
+}
+
+// BackfillEvents writes the missing event of every ledger entry in
+// [from, to) and returns the report of the window afterwards. Backfilled
+// events are marked "backfilled" and go out through the outbox like any
+// other. Both legs of a transfer share one event raised on the sender.
+func (s *Service) BackfillEvents(ctx context.Context, from, to time.Time) (*ReconciliationReport, error) {
+	if err := validWindow(from, to); err != nil {
+		return nil, err
+	}
+	from, to = from.UTC(), to.UTC()
+
+	// the accounts of the entries are locked before their events are
+	// written, so two backfills of one window cannot both write them
+	var report *ReconciliationReport
+	err := s.runner.Run(ctx, "backfill_events", nil, func(tx *sql.Tx) error {
+		var err error
+		report, err = backfill(ctx, tx, from, to)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return report, nil
+}
+
+func backfill(ctx context.Context, tx *sql.Tx, from, to time.Time) (*ReconciliationReport, error) {
+	repo := NewPostgresReconciliationRepo(tx)
+	outboxRepo := NewPostgresOutboxRepository(tx)
+
+	backfilled := 0
+	for {
+		listed, err := repo.MissingEvents(ctx, from, to, reconcileBatch)
+		if err != nil {
+			return nil, err
+		}
+
+		// list again once the accounts are locked: a backfill that held
+		// them before has committed its events by now. Batches lock in
+		// their own order, and a deadlock between two backfills is retried.
+		locked := map[int64]bool{}
+		for _, entry := range listed {
+			locked[entry.AccountID] = true
+		}
+		accountIDs := make([]int64, 0, len(locked))
+		for id := range locked {
+			accountIDs = append(accountIDs, id)
+		}
+		if err := lockAccounts(ctx, tx, accountIDs...); err != nil {
+			return nil, err
+		}
+
+		relisted, err := repo.MissingEvents(ctx, from, to, reconcileBatch)
+		if err != nil {
+			return nil, err
+		}
+
+		var missing []Transaction
+		for _, entry := range relisted {
+			if locked[entry.AccountID] {
+				missing = append(missing, entry)
+			}
+		}
+
+		// journals given a transfer event in this batch
+		covered := map[int64]bool{}
+		for i := range missing {
+			entry := &missing[i]
+			if isTransfer(entry.Type) {
+				if covered[entry.JournalID] {
+					continue
+				}
+				covered[entry.JournalID] = true
+			}
+
+			event, err := backfillEvent(entry)
+			if err != nil {
+				return nil, err
+			}
+
+			if err := outboxRepo.Add(ctx, event); err != nil {
+				return nil, err
+			}
+			backfilled++
+		}
+
+		// every entry listed is now covered or was left for the next
+		// batch, so the next call moves on
+		if len(listed) < reconcileBatch {
+			break
+		}
+	}
+
+	report, err := reconcile(ctx, repo, from, to)
+	if err != nil {
+		return nil, err
+	}
+	report.Backfilled = backfilled
+
+	return report, nil
+}
+
+func isTransfer(t Type) bool {
+	return t == TypeTransferOut || t == TypeTransferIn
+}
+
+// backfillEvent rebuilds the event the entry should have been written
+// with.
+func backfillEvent(entry *Transaction) (*OutboxEvent, error) {
+	eventType := "transaction.created"
+	accountID := entry.AccountID
+
+	payload := map[string]interface{}{
+		"amount":     entry.Amount,
+		"currency":   entry.Currency,
+		"note":       entry.Note,
+		"journal_id": entry.JournalID,
+		"backfilled": true,
+	}
+	entry.Labels.addTo(payload)
+
+	switch entry.Type {
+	case TypeTransferOut:
+		payload["type"] = "transfer"
+		if entry.CounterpartyID != 0 {
+			payload["to_account_id"] = entry.CounterpartyID
+		}
+	case TypeTransferIn:
+		// raised on the sender, as the transfer itself does; entries
+		// written before counterparties were recorded only know their side
+		payload["type"] = "transfer"
+		if entry.CounterpartyID != 0 {
+			accountID = entry.CounterpartyID
+			payload["to_account_id"] = entry.AccountID
+		}
+	case TypeReversalIn, TypeReversalOut:
+		eventType = "transaction.reversed"
+		payload["type"] = "reversal"
+		payload["reversal_of"] = entry.ReversalOf
+	case TypeFee:
+		payload["type"] = "fee"
+		if entry.FeeFor != 0 {
+			payload["fee_for"] = entry.FeeFor
+		}
+	case TypeDeposit, TypeWithdraw, TypeInterest:
+		payload["type"] = strings.ToLower(string(entry.Type))
+	default:
+		return nil, errors.New("no event for transaction type " + string(entry.Type))
+	}
+	payload["account_id"] = accountID
+
+	raw, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	return &OutboxEvent{
+		ID:            uuid.New(),
+		AggregateType: "account",
+		AggregateID:   accountID,
+		EventType:     eventType,
+		Payload:       raw,
+	}, nil
+}
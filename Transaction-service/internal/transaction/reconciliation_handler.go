@@ -0,0 +1,77 @@
+package transaction
+
+import (
+	"net/http"
+	"time"
+)
+
+// reconciliationWindow reads from and to from the query; dates cover whole
+// days. The window defaults to the last 24 hours.
+func reconciliationWindow(w http.ResponseWriter, r *http.Request) (time.Time, time.Time, bool) {
+	q := r.URL.Query()
+
+	to := time.Now().UTC()
+	if v := q.Get("to"); v != "" {
+		t, dateOnly, err := parseHistoryTime(v)
+		if err != nil {
+			respondError(w, http.StatusBadRequest, "INVALID_REQUEST", "to must be an RFC 3339 timestamp or YYYY-MM-DD date")
+			return to, to, false
+		}
+		if dateOnly {
+			t = t.AddDate(0, 0, 1)
+		}
+		to = t
+	}
+
+	from := to.Add(-24 * time.Hour)
+	if v := q.Get("from"); v != "" {
+		t, _, err := parseHistoryTime(v)
+		if err != nil {
+			respondError(w, http.StatusBadRequest, "INVALID_REQUEST", "from must be an RFC 3339 timestamp or YYYY-MM-DD date")
+			return from, to, false
+		}
+		from = t
+	}
+
+	return from, to, true
+}
+
+func (h *TransactionHandler) Reconcile(w http.ResponseWriter, r *http.Request) {
+	from, to, ok := reconciliationWindow(w, r)
+	if !ok {
+		return
+	}
+
+	report, err := h.service.Reconcile(r.Context(), from, to)
+	if err != nil {
+		respondServiceError(w, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, SuccessResponse{
+		Status:  "success",
+		Message: "Reconciliation completed",
+		Data:    report,
+	})
+}
+
+// BackfillEvents writes the missing events of the window and returns what
+// is still out of line afterwards.
+func (h *TransactionHandler) BackfillEvents(w http.ResponseWriter, r *http.Request) {
+	from, to, ok := reconciliationWindow(w, r)
+	if !ok {
+		return
+	}
+
+	report, err := h.service.BackfillEvents(r.Context(), from, to)
+	if err != nil {
+		respondServiceError(w, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, SuccessResponse{
+		Status:  "success",
+		Message: "Missing events backfilled",
+		Data:    report,
+	})
+}
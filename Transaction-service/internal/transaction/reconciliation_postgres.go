@@ -0,0 +1,113 @@
+package transaction
+
+import (
+	"context"
+	"database/sql"
+	"time"
+	"transaction/internal/infrastructure/database"
+)
+
+var _ ReconciliationRepository = (*PostgresReconciliationRepo)(nil)
+
+type PostgresReconciliationRepo struct {
+	db database.DBTX
+}
+
+func NewPostgresReconciliationRepo(db database.DBTX) *PostgresReconciliationRepo {
+	return &PostgresReconciliationRepo{db: db}
+}
+
+// eventCovers matches outbox event e to ledger entry t: by journal, or for
+// events written without a journal_id by account, amount and commit time,
+// which the entry and its event share.
+const eventCovers = `e.aggregate_type = 'account'
+	AND (
+		(e.payload ? 'journal_id' AND t.journal_id = (e.payload->>'journal_id')::bigint)
+		OR (NOT e.payload ? 'journal_id'
+			AND e.aggregate_id = t.account_id
+			AND e.created_at = t.created_at
+			AND e.payload->>'amount' = t.amount::text)
+	)`
+
+func (r *PostgresReconciliationRepo) MissingEvents(ctx context.Context, from, to time.Time, limit int) ([]Transaction, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+transactionColumns+`
+		FROM transactions t
+		WHERE t.created_at >= $1 AND t.created_at < $2
+		  AND NOT EXISTS (
+			SELECT 1
+			FROM outbox_events e
+			WHERE `+eventCovers+`
+		  )
+		ORDER BY t.created_at, t.id
+		LIMIT $3
+	`, from, to, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []Transaction
+	for rows.Next() {
+		t, err := scanTransaction(rows)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, t)
+	}
+
+	return entries, rows.Err()
+}
+
+const reconciledEventColumns = `e.id, e.aggregate_id, e.event_type, e.status, e.payload, e.created_at`
+
+func scanReconciledEvents(rows *sql.Rows) ([]ReconciledEvent, error) {
+	defer rows.Close()
+
+	var events []ReconciledEvent
+	for rows.Next() {
+		var e ReconciledEvent
+		if err := rows.Scan(&e.ID, &e.AggregateID, &e.EventType, &e.Status, &e.Payload, &e.CreatedAt); err != nil {
+			return nil, err
+		}
+		events = append(events, e)
+	}
+	return events, rows.Err()
+}
+
+func (r *PostgresReconciliationRepo) StuckEvents(ctx context.Context, from, to, before time.Time, limit int) ([]ReconciledEvent, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+reconciledEventColumns+`
+		FROM outbox_events e
+		WHERE e.status = 'pending'
+		  AND e.created_at >= $1 AND e.created_at < $2
+		  AND e.created_at < $3
+		ORDER BY e.created_at
+		LIMIT $4
+	`, from, to, before, limit)
+	if err != nil {
+		return nil, err
+	}
+	return scanReconciledEvents(rows)
+}
+
+func (r *PostgresReconciliationRepo) OrphanEvents(ctx context.Context, from, to time.Time, limit int) ([]ReconciledEvent, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+reconciledEventColumns+`
+		FROM outbox_events e
+		WHERE e.aggregate_type = 'account'
+		  AND e.event_type LIKE 'transaction.%'
+		  AND e.created_at >= $1 AND e.created_at < $2
+		  AND NOT EXISTS (
+			SELECT 1
+			FROM transactions t
+			WHERE `+eventCovers+`
+		  )
+		ORDER BY e.created_at
+		LIMIT $3
+	`, from, to, limit)
+	if err != nil {
+		return nil, err
+	}
+	return scanReconciledEvents(rows)
+}
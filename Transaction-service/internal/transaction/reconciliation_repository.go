@@ -0,0 +1,18 @@
+package transaction
+
+import (
+	"context"
+	"time"
+)
+
+type ReconciliationRepository interface {
+	// MissingEvents returns up to limit ledger entries created in
+	// [from, to) that no outbox event covers, oldest first.
+	MissingEvents(ctx context.Context, from, to time.Time, limit int) ([]Transaction, error)
+	// StuckEvents returns pending events created in [from, to) and before
+	// before.
+	StuckEvents(ctx context.Context, from, to, before time.Time, limit int) ([]ReconciledEvent, error)
+	// OrphanEvents returns transaction events created in [from, to) that
+	// cover no ledger entry.
+	OrphanEvents(ctx context.Context, from, to time.Time, limit int) ([]ReconciledEvent, error)
+}
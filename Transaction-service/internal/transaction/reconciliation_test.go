@@ -0,0 +1,140 @@
+package transaction_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+	"transaction/internal/transaction"
+)
+
+func TestReconcile_RejectsInvalidWindow(t *testing.T) {
+	service := transaction.NewTransactionService(nil, nil, nil)
+	now := time.Now()
+
+	for name, from := range map[string]time.Time{
+		"empty":    now,
+		"reversed": now.Add(time.Hour),
+		"too long": now.Add(-transaction.MaxReconciliationWindow - time.Second),
+	} {
+		t.Run(name, func(t *testing.T) {
+			if _, err := service.Reconcile(context.Background(), from, now); !errors.Is(err, transaction.ErrInvalidWindow) {
+				t.Fatalf("expected ErrInvalidWindow, got %v", err)
+			}
+		})
+	}
+}
+
+func TestReconcile_ReportsAndBackfillsMissingEvents(t *testing.T) {
+	db := setupTestDB(t)
+	ctx := context.Background()
+
+	txRepo := transaction.NewPostgresRepo(db)
+	service := transaction.NewTransactionService(db, &MockAccountClient{}, txRepo)
+
+	from := createTestAccount(t, db, "From")
+	to := createTestAccount(t, db, "To")
+
+	if err := service.Deposit(ctx, "", from.ID, 10_000, "USD", "funding", transaction.Labels{}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := service.Transfer(ctx, "", from.ID, to.ID, 3_000, "USD", "rent", transaction.Labels{}); err != nil {
+		t.Fatal(err)
+	}
+
+	start, end := time.Now().Add(-time.Hour), time.Now().Add(time.Minute)
+
+	report, err := service.Reconcile(ctx, start, end)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !report.Clean() {
+		t.Fatalf("expected a clean report, got %+v", report)
+	}
+
+	// lose the transfer's event and publish one for a journal that does not exist
+	if _, err := db.Exec(`DELETE FROM outbox_events WHERE payload->>'type' = 'transfer'`); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec(`
+		INSERT INTO outbox_events (id, aggregate_type, aggregate_id, event_type, payload)
+		VALUES (gen_random_uuid(), 'account', $1, 'transaction.created', '{"journal_id": 999999, "amount": 1}')
+	`, from.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	report, err = service.Reconcile(ctx, start, end)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.MissingEvents) != 2 || len(report.OrphanEvents) != 1 || len(report.StuckEvents) != 0 {
+		t.Fatalf("expected both transfer legs missing and one orphan, got %+v", report)
+	}
+
+	// both legs share one event on the sender
+	report, err = service.BackfillEvents(ctx, start, end)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Backfilled != 1 || len(report.MissingEvents) != 0 || len(report.OrphanEvents) != 1 {
+		t.Fatalf("expected one backfilled event and the orphan left, got %+v", report)
+	}
+
+	var aggregate int64
+	if err := db.QueryRow(`SELECT aggregate_id FROM outbox_events WHERE payload->>'backfilled' = 'true'`).Scan(&aggregate); err != nil {
+		t.Fatal(err)
+	}
+	if aggregate != from.ID {
+		t.Fatalf("expected the event on account %d, got %d", from.ID, aggregate)
+	}
+}
+
+func TestBackfillEvents_ConcurrentBackfillsWriteOnce(t *testing.T) {
+	db := setupTestDB(t)
+	ctx := context.Background()
+
+	txRepo := transaction.NewPostgresRepo(db)
+	service := transaction.NewTransactionService(db, &MockAccountClient{}, txRepo)
+
+	for i := 0; i < 5; i++ {
+		acc := createTestAccount(t, db, "Saver")
+		if err := service.Deposit(ctx, "", acc.ID, 1_000, "USD", "funding", transaction.Labels{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, err := db.Exec(`DELETE FROM outbox_events`); err != nil {
+		t.Fatal(err)
+	}
+
+	start, end := time.Now().Add(-time.Hour), time.Now().Add(time.Minute)
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	backfilled := 0
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			report, err := service.BackfillEvents(ctx, start, end)
+			if err != nil {
+				t.Errorf("backfill failed: %v", err)
+				return
+			}
+			mu.Lock()
+			backfilled += report.Backfilled
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	var events int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM outbox_events`).Scan(&events); err != nil {
+		t.Fatal(err)
+	}
+	if backfilled != 5 || events != 5 {
+		t.Fatalf("expected 5 events written once, got %d backfilled and %d events", backfilled, events)
+	}
+}
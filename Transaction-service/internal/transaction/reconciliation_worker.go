@@ -0,0 +1,53 @@
+package transaction
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// ReconciliationWorker compares the ledger with the outbox every hour and
+// logs what is out of line. It never backfills; that is done on request.
+type ReconciliationWorker struct {
+	service  *Service
+	interval time.Duration
+	window   time.Duration
+}
+
+func NewReconciliationWorker(service *Service) *ReconciliationWorker {
+	return &ReconciliationWorker{
+		service:  service,
+		interval: time.Hour,
+		// overlap the previous run so nothing falls between two windows
+		window: 25 * time.Hour,
+	}
+}
+
+func (w *ReconciliationWorker) Start(ctx context.Context) {
+	log.Println("🚀 Reconciliation worker started")
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Println("🛑 Reconciliation worker stopped")
+			return
+
+		case <-ticker.C:
+			to := time.Now().UTC()
+
+			report, err := w.service.Reconcile(ctx, to.Add(-w.window), to)
+			if err != nil {
+				log.Println("❌ Reconciliation failed:", err)
+				continue
+			}
+
+			if !report.Clean() {
+				log.Printf("⚠️ Reconciliation found %d ledger entries without events, %d stuck events and %d orphan events",
+					len(report.MissingEvents), len(report.StuckEvents), len(report.OrphanEvents))
+			}
+		}
+	}
+}
@@ -1,9 +1,26 @@
 package transaction
 
-import "context"
+import (
+	"context"
+	"time"
+)
 
 type TransactionRepository interface {
 	Create(ctx context.Context, tx *Transaction) error
+	GetByID(ctx context.Context, id int64) (*Transaction, error)
+	ListByJournal(ctx context.Context, journalID int64) ([]Transaction, error)
+	ReversedAmount(ctx context.Context, id int64) (int64, error)
 	ListByAccount(ctx context.Context, accountID int64) ([]Transaction, error)
-	BalanceByAccount(ctx context.Context, accountID int64) (int64,error)
-}
\ No newline at end of file
+	// EachByAccount calls fn for every entry matching filter, oldest first,
+	// reading rows one at a time instead of loading the whole history.
+	EachByAccount(ctx context.Context, accountID int64, filter ExportFilter, fn func(Transaction) error) error
+	ListPage(ctx context.Context, accountID int64, filter HistoryFilter) (*HistoryPage, error)
+	BalanceByAccount(ctx context.Context, accountID int64) ([]Balance, error)
+	BalanceOf(ctx context.Context, accountID int64, currency string) (int64, error)
+	// BalanceAt is the balance including every entry created at or before
+	// asOf. It starts from the latest checkpoint instead of the first entry.
+	BalanceAt(ctx context.Context, accountID int64, currency string, asOf time.Time) (int64, error)
+	// CreateCheckpoints records the balance before at for up to limit
+	// account currencies that moved since their previous checkpoint.
+	CreateCheckpoints(ctx context.Context, at time.Time, limit int) (int, error)
+}
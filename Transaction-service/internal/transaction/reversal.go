@@ -0,0 +1,176 @@
+package transaction
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrTransactionNotFound   = errors.New("transaction not found")
+	ErrAlreadyReversed       = errors.New("transaction already fully reversed")
+	ErrReversalOfReversal    = errors.New("a reversal cannot itself be reversed")
+	ErrReversalExceedsAmount = errors.New("refund exceeds the amount left to reverse")
+)
+
+// reversalJournal mirrors the original journal with every posting negated and
+// scaled to the refunded amount. Journals posted by this service use the same
+// magnitude on every line, so a partial refund stays balanced.
+func reversalJournal(original *JournalEntry, originalAmount, amount int64, note string) *JournalEntry {
+	entry := &JournalEntry{
+		Type:       JournalReversal,
+		ReversesID: original.ID,
+		Currency:   original.Currency,
+		Note:       note,
+	}
+
+	for _, p := range original.Postings {
+		entry.Postings = append(entry.Postings, Posting{
+			AccountID:     p.AccountID,
+			SystemAccount: p.SystemAccount,
+			Amount:        -p.Amount * amount / originalAmount,
+		})
+	}
+
+	return entry
+}
+
+// Reverse posts compensating entries for a posted transaction. Any leg of a
+// transfer may be passed; every leg of its journal is reversed. An amount of
+// zero refunds whatever has not been reversed yet.
+func (s *Service) Reverse(ctx context.Context, transactionID int64, amount int64, note string) (*JournalEntry, error) {
+	if amount < 0 {
+		return nil, ErrInvalidAmount
+	}
+
+	var journal *JournalEntry
+	err := s.runner.Run(ctx, "reverse", nil, func(tx *sql.Tx) error {
+		var err error
+		journal, err = s.reverse(ctx, tx, transactionID, amount, note)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return journal, nil
+}
+
+func (s *Service) reverse(ctx context.Context, tx *sql.Tx, transactionID int64, amount int64, note string) (*JournalEntry, error) {
+	transactionRepo := NewPostgresRepo(tx)
+	journalRepo := NewPostgresJournalRepo(tx)
+	outboxRepo := NewPostgresOutboxRepository(tx)
+
+	original, err := transactionRepo.GetByID(ctx, transactionID)
+	if err != nil {
+		return nil, err
+	}
+
+	if original.Type.Reversal() {
+		return nil, ErrReversalOfReversal
+	}
+
+	originalJournal, err := journalRepo.GetByID(ctx, original.JournalID)
+	if err != nil {
+		return nil, err
+	}
+
+	legs, err := transactionRepo.ListByJournal(ctx, originalJournal.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	// lock before reading what was reversed, so two concurrent reversals of
+	// one entry cannot both see it unreversed
+	accountIDs := make([]int64, 0, len(legs))
+	for _, leg := range legs {
+		accountIDs = append(accountIDs, leg.AccountID)
+	}
+	if err := lockAccounts(ctx, tx, accountIDs...); err != nil {
+		return nil, err
+	}
+
+	reversed, err := transactionRepo.ReversedAmount(ctx, original.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	remaining := original.Amount - reversed
+	if remaining <= 0 {
+		return nil, ErrAlreadyReversed
+	}
+
+	if amount == 0 {
+		amount = remaining
+	}
+
+	if amount > remaining {
+		return nil, ErrReversalExceedsAmount
+	}
+
+	journal := reversalJournal(originalJournal, original.Amount, amount, note)
+
+	// a reversal that debits a customer needs the funds to be there
+	for _, p := range journal.Postings {
+		if p.AccountID == 0 || p.Amount > 0 {
+			continue
+		}
+
+		if err := s.checkFunds(ctx, tx, nil, p.AccountID, journal.Currency, -p.Amount); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := journalRepo.Create(ctx, journal); err != nil {
+		return nil, err
+	}
+
+	for _, leg := range legs {
+		entry := &Transaction{
+			AccountID:      leg.AccountID,
+			JournalID:      journal.ID,
+			Type:           TypeReversalIn,
+			Amount:         amount,
+			Currency:       leg.Currency,
+			ReversalOf:     leg.ID,
+			Note:           note,
+			Labels:         leg.Labels,
+			CounterpartyID: leg.CounterpartyID,
+		}
+		if leg.Type.Credit() {
+			entry.Type = TypeReversalOut
+		}
+
+		if err := transactionRepo.Create(ctx, entry); err != nil {
+			return nil, err
+		}
+
+		payload, err := json.Marshal(map[string]interface{}{
+			"account_id":  entry.AccountID,
+			"amount":      entry.Amount,
+			"currency":    entry.Currency,
+			"type":        "reversal",
+			"reversal_of": leg.ID,
+			"note":        note,
+			"journal_id":  entry.JournalID,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		if err := outboxRepo.Add(ctx, &OutboxEvent{
+			ID:            uuid.New(),
+			AggregateType: "account",
+			AggregateID:   entry.AccountID,
+			EventType:     "transaction.reversed",
+			Payload:       payload,
+		}); err != nil {
+			return nil, err
+		}
+	}
+
+	return journal, nil
+}
@@ -0,0 +1,79 @@
+package transaction_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"transaction/internal/transaction"
+)
+
+func TestReverse_Deposit(t *testing.T) {
+	db := setupTestDB(t)
+	ctx := context.Background()
+
+	txRepo := transaction.NewPostgresRepo(db)
+	service := transaction.NewTransactionService(db, &MockAccountClient{}, txRepo)
+
+	acc := createTestAccount(t, db, "Reversal User")
+	service.Deposit(ctx, "rev-1", acc.ID, 10_000, "USD", "mistaken deposit", transaction.Labels{})
+
+	entries, _ := txRepo.ListByAccount(ctx, acc.ID)
+	deposit := entries[0]
+
+	journal, err := service.Reverse(ctx, deposit.ID, 0, "typo")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if journal.ReversesID != deposit.JournalID {
+		t.Fatalf("expected reversal of journal %d, got %d", deposit.JournalID, journal.ReversesID)
+	}
+
+	assertBalance(t, service, acc.ID, 0)
+
+	entries, _ = txRepo.ListByAccount(ctx, acc.ID)
+	if entries[0].Type != transaction.TypeReversalOut || entries[0].ReversalOf != deposit.ID {
+		t.Fatalf("expected REVERSAL_OUT linked to %d, got %s linked to %d", deposit.ID, entries[0].Type, entries[0].ReversalOf)
+	}
+
+	if _, err := service.Reverse(ctx, deposit.ID, 0, "again"); !errors.Is(err, transaction.ErrAlreadyReversed) {
+		t.Fatalf("expected ErrAlreadyReversed, got %v", err)
+	}
+}
+
+func TestReverse_PartialTransferRefund(t *testing.T) {
+	db := setupTestDB(t)
+	ctx := context.Background()
+
+	txRepo := transaction.NewPostgresRepo(db)
+	service := transaction.NewTransactionService(db, &MockAccountClient{}, txRepo)
+
+	from := createTestAccount(t, db, "Buyer")
+	to := createTestAccount(t, db, "Merchant")
+
+	service.Deposit(ctx, "rev-2", from.ID, 20_000, "USD", "funding", transaction.Labels{})
+	if _, err := service.Transfer(ctx, "", from.ID, to.ID, 8_000, "USD", "order", transaction.Labels{}); err != nil {
+		t.Fatal(err)
+	}
+
+	toEntries, _ := txRepo.ListByAccount(ctx, to.ID)
+	credit := toEntries[0]
+
+	if _, err := service.Reverse(ctx, credit.ID, 3_000, "partial refund"); err != nil {
+		t.Fatal(err)
+	}
+
+	assertBalance(t, service, from.ID, 15_000)
+	assertBalance(t, service, to.ID, 5_000)
+
+	if _, err := service.Reverse(ctx, credit.ID, 6_000, "too much"); !errors.Is(err, transaction.ErrReversalExceedsAmount) {
+		t.Fatalf("expected ErrReversalExceedsAmount, got %v", err)
+	}
+
+	if _, err := service.Reverse(ctx, credit.ID, 0, "rest"); err != nil {
+		t.Fatal(err)
+	}
+
+	assertBalance(t, service, from.ID, 20_000)
+	assertBalance(t, service, to.ID, 0)
+}
@@ -4,9 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
-	"errors"
-	"fmt"
-	"log"
+	"transaction/internal/infrastructure/database"
 	"transaction/pb"
 
 	//"transaction/internal/account"
@@ -21,9 +19,18 @@ import (
 type Service struct {
 	db *sql.DB
 	//accountRepo     account.AccountRepository
-	accountClient   pb.AccountServiceClient
-	transactionRepo TransactionRepository
-	idempotencyRepo IdempotencyRepository
+	accountClient      pb.AccountServiceClient
+	transactionRepo    TransactionRepository
+	idempotencyRepo    IdempotencyRepository
+	retention          IdempotencyRetention
+	holdRepo           HoldRepository
+	limitRepo          LimitRepository
+	feeRuleRepo        FeeRuleRepository
+	interestRepo       InterestRepository
+	reconciliationRepo ReconciliationRepository
+	chainRepo          ChainRepository
+	chainSigner        *ChainSigner
+	runner             *database.TxRunner
 }
 
 func NewTransactionService(
@@ -33,54 +40,70 @@ func NewTransactionService(
 	transactionRepo TransactionRepository,
 ) *Service {
 	return &Service{
-		db:              db,
-		accountClient:   accountClient,
-		transactionRepo: transactionRepo,
-		idempotencyRepo: NewPostgresIdempotencyRepo(db),
+		db:                 db,
+		accountClient:      accountClient,
+		transactionRepo:    transactionRepo,
+		idempotencyRepo:    NewPostgresIdempotencyRepo(db),
+		retention:          DefaultIdempotencyRetention,
+		holdRepo:           NewPostgresHoldRepo(db),
+		limitRepo:          NewPostgresLimitRepo(db),
+		feeRuleRepo:        NewPostgresFeeRuleRepo(db),
+		interestRepo:       NewPostgresInterestRepo(db),
+		reconciliationRepo: NewPostgresReconciliationRepo(db),
+		chainRepo:          NewPostgresChainRepo(db),
+		runner:             database.NewTxRunner(db, database.DefaultRetryPolicy),
 	}
 }
 
-func (s *Service) Deposit(ctx context.Context, idempotencyKey string, accountID int64, amount int64, note string) error {
+func (s *Service) Deposit(ctx context.Context, idempotencyKey string, accountID int64, amount int64, currency string, note string, labels Labels) error {
 
 	if amount <= 0 {
-		return errors.New("amount must be positive")
+		return ErrInvalidAmount
 	}
 
-	// assk account service
-	resp, err := s.accountClient.GetAccount(ctx, &pb.GetAccountRequest{
-		AccountId: accountID,
-	})
-
+	labels, err := labels.normalize()
 	if err != nil {
 		return err
 	}
 
-	if !resp.IsExists || !resp.IsActive {
-		return errors.New("account not found or inactive")
+	// assk account service
+	resp, err := s.activeAccount(ctx, accountID)
+	if err != nil {
+		return err
 	}
 
-	tx, err := s.db.BeginTx(ctx, nil)
+	currency, err = resolveCurrency(currency, resp.Currency)
 	if err != nil {
 		return err
 	}
 
-	defer tx.Rollback()
+	return s.runner.Run(ctx, "deposit", nil, func(tx *sql.Tx) error {
+		return s.deposit(ctx, tx, idempotencyKey, accountID, amount, currency, note, labels)
+	})
+}
 
+// deposit posts a validated deposit in tx.
+func (s *Service) deposit(ctx context.Context, tx *sql.Tx, idempotencyKey string, accountID int64, amount int64, currency string, note string, labels Labels) error {
 	//accountRepo := account.NewPostgresRepository(tx)
 
 	transactionRepo := NewPostgresRepo(tx)
-	idemRepo := NewPostgresIdempotencyRepo(tx)
+	journalRepo := NewPostgresJournalRepo(tx)
+	idemRepo := NewPostgresIdempotencyRepoWithRetention(tx, s.retention)
 	outboxRepo := NewPostgresOutboxRepository(tx)
 
 	// try insert first
 	if idempotencyKey != "" {
-		inserted, err := idemRepo.TryInsert(ctx, idempotencyKey, "deposit")
+		hash, err := payloadHash(accountID, amount, currency, note, labels)
+		if err != nil {
+			return err
+		}
+
+		claimed, _, err := claimKey(ctx, idemRepo, idempotencyKey, "deposit", hash)
 		if err != nil {
 			return err
 		}
 
-		if !inserted {
-			log.Printf("Duplicate request with key %s, returning success", idempotencyKey)
+		if !claimed {
 			return nil // already proccesed
 		}
 	}
@@ -101,12 +124,20 @@ func (s *Service) Deposit(ctx context.Context, idempotencyKey string, accountID
 	// 	return  errors.New("insufficient funds")
 	// }
 
-	// write ledger entry
+	// write balanced journal, then the customer's ledger entry
+	journal := depositJournal(accountID, amount, currency, note)
+	if err := journalRepo.Create(ctx, journal); err != nil {
+		return err
+	}
+
 	if err := transactionRepo.Create(ctx, &Transaction{
 		AccountID: accountID,
+		JournalID: journal.ID,
 		Amount:    amount,
+		Currency:  currency,
 		Type:      TypeDeposit,
 		Note:      note,
+		Labels:    labels,
 	}); err != nil {
 		return err
 
@@ -115,9 +146,12 @@ func (s *Service) Deposit(ctx context.Context, idempotencyKey string, accountID
 	payload := map[string]interface{}{
 		"account_id": accountID,
 		"amount":     amount,
+		"currency":   currency,
 		"type":       "deposit",
 		"note":       note,
+		"journal_id": journal.ID,
 	}
+	labels.addTo(payload)
 
 	payloadJSON, err := json.Marshal(payload)
 	if err != nil {
@@ -136,103 +170,139 @@ func (s *Service) Deposit(ctx context.Context, idempotencyKey string, accountID
 		return err
 	}
 
-	// save idempotency record
-	if idempotencyKey != "" {
-		resp, _ := json.Marshal(map[string]string{"status": "ok"})
-		if err := idemRepo.Save(ctx, idempotencyKey, "deposit", resp); err != nil {
-			return err
-		}
-	}
-
-	return tx.Commit()
+	return nil
 
 }
 
-func (s *Service) Withdraw(ctx context.Context, idempotencyKey string, accountID int64, amount int64, note string) error {
+func (s *Service) Withdraw(ctx context.Context, idempotencyKey string, accountID int64, amount int64, currency string, note string, labels Labels) ([]Fee, error) {
 	if amount <= 0 {
-		return errors.New("amount must be positive")
+		return nil, ErrInvalidAmount
 	}
 
-	// assk account service
-	resp, err := s.accountClient.GetAccount(ctx, &pb.GetAccountRequest{
-		AccountId: accountID,
-	})
-
+	labels, err := labels.normalize()
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	if !resp.IsExists || !resp.IsActive {
-		return errors.New("account not found or inactive")
+	// assk account service
+	resp, err := s.activeAccount(ctx, accountID)
+	if err != nil {
+		return nil, err
 	}
 
-	tx, err := s.db.BeginTx(ctx, nil)
+	currency, err = resolveCurrency(currency, resp.Currency)
 	if err != nil {
+		return nil, err
+	}
+
+	var fees []Fee
+	err = s.runner.Run(ctx, "withdraw", nil, func(tx *sql.Tx) error {
+		var err error
+		fees, err = s.withdraw(ctx, tx, idempotencyKey, resp, accountID, amount, currency, note, labels)
 		return err
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	defer tx.Rollback()
+	return fees, nil
+}
 
+// withdraw posts a validated withdrawal and its fees in tx.
+func (s *Service) withdraw(ctx context.Context, tx *sql.Tx, idempotencyKey string, resp *pb.GetAccountResponse, accountID int64, amount int64, currency string, note string, labels Labels) ([]Fee, error) {
 	//accountRepo := account.NewPostgresRepository(tx)
 	transactionRepo := NewPostgresRepo(tx)
-	idemRepo := NewPostgresIdempotencyRepo(tx)
+	journalRepo := NewPostgresJournalRepo(tx)
+	idemRepo := NewPostgresIdempotencyRepoWithRetention(tx, s.retention)
 	outboxRepo := NewPostgresOutboxRepository(tx)
 
 	// acc, err := accountRepo.LockByID(ctx, accountID)
 	// if err != nil {
-	// 	return err
+	// 	return nil, err
 	// }
 
+	// hold the account until commit, so a concurrent debit waits and then
+	// sees this withdrawal when it checks funds
+	if err := lockAccounts(ctx, tx, accountID); err != nil {
+		return nil, err
+	}
+
 	// try insert first
 	if idempotencyKey != "" {
-		inserted, err := idemRepo.TryInsert(ctx, idempotencyKey, "withdraw")
+		hash, err := payloadHash(accountID, amount, currency, note, labels)
 		if err != nil {
-			return err
+			return nil, err
 		}
 
-		if !inserted {
-			log.Printf("Duplicate request with key %s, returning success", idempotencyKey)
-			return nil // already proccesed
+		claimed, result, err := claimKey(ctx, idemRepo, idempotencyKey, "withdraw", hash)
+		if err != nil {
+			return nil, err
 		}
 
+		if !claimed {
+			// already proccesed; answer with the fees charged then
+			var fees []Fee
+			return fees, replayResult(result, &fees)
+		}
 	}
 
 	// newBalance := acc.Balance - amount
 
 	// if err := accountRepo.UpdateBalance(ctx, acc.ID, newBalance); err != nil {
-	// 	return err
+	// 	return nil, err
 	// }
 
-	// compute balance inside Tx
-	balance, err := transactionRepo.BalanceByAccount(ctx, accountID)
+	fees, err := quoteFees(ctx, tx, FeeOnWithdraw, resp, currency, amount)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	if balance < amount {
-		return errors.New("insufficient funds")
+	// compute available balance inside Tx; an agreed overdraft may
+	// take it below zero. Fees are debited with the withdrawal.
+	if err := s.checkFunds(ctx, tx, resp, accountID, currency, amount+totalFees(fees)); err != nil {
+		return nil, err
 	}
 
-	// write ledger entry
-	if err := transactionRepo.Create(ctx, &Transaction{
+	if err := s.checkLimits(ctx, tx, resp, currency, amount); err != nil {
+		return nil, err
+	}
+
+	// write balanced journal, then the customer's ledger entry
+	journal := withdrawJournal(accountID, amount, currency, note)
+	if err := journalRepo.Create(ctx, journal); err != nil {
+		return nil, err
+	}
+
+	entry := &Transaction{
 		AccountID: accountID,
+		JournalID: journal.ID,
 		Type:      TypeWithdraw,
 		Amount:    amount,
+		Currency:  currency,
 		Note:      note,
-	}); err != nil {
-		return err
+		Labels:    labels,
+	}
+	if err := transactionRepo.Create(ctx, entry); err != nil {
+		return nil, err
+	}
+
+	if err := postFees(ctx, tx, entry, fees); err != nil {
+		return nil, err
 	}
 
 	payload := map[string]interface{}{
 		"account_id": accountID,
 		"amount":     amount,
+		"currency":   currency,
 		"type":       "withdraw",
 		"note":       note,
+		"journal_id": journal.ID,
 	}
+	labels.addTo(payload)
 
 	payloadJSON, err := json.Marshal(payload)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	event := OutboxEvent{
@@ -244,41 +314,100 @@ func (s *Service) Withdraw(ctx context.Context, idempotencyKey string, accountID
 	}
 
 	if err := outboxRepo.Add(ctx, &event); err != nil {
-		return err
+		return nil, err
 	}
 
-	// save idempotency record
-	if idempotencyKey != "" {
-		resp, _ := json.Marshal(map[string]string{"status": "ok"})
-		if err := idemRepo.Save(ctx, idempotencyKey, "withdraw", resp); err != nil {
-			return err
-		}
+	if err := saveResult(ctx, idemRepo, idempotencyKey, fees); err != nil {
+		return nil, err
 	}
 
-	return tx.Commit()
+	return fees, nil
 }
 
-func (s *Service) Transfer(ctx context.Context, fromAccountID int64, toAccountID int64, amount int64, note string) error {
+func (s *Service) Transfer(ctx context.Context, idempotencyKey string, fromAccountID int64, toAccountID int64, amount int64, currency string, note string, labels Labels) ([]Fee, error) {
 	if amount <= 0 {
-		return errors.New("amount must be positive")
+		return nil, ErrInvalidAmount
 	}
 
 	if fromAccountID == toAccountID {
-		return errors.New("cannot transfer to the same account")
+		return nil, ErrSameAccount
 	}
 
-	// use serializable isolation
-	tx, err := s.db.BeginTx(ctx, &sql.TxOptions{
-		Isolation: sql.LevelSerializable,
-	})
+	labels, err := labels.normalize()
+	if err != nil {
+		return nil, err
+	}
 
+	// ask the account service before any lock is taken, so a slow answer
+	// does not hold up other debits and a retry does not ask again
+	fromResp, err := s.activeAccount(ctx, fromAccountID)
 	if err != nil {
+		return nil, err
+	}
+
+	toResp, err := s.activeAccount(ctx, toAccountID)
+	if err != nil {
+		return nil, err
+	}
+
+	// both sides must hold the transferred currency
+	currency, err = resolveCurrency(currency, fromResp.Currency)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := resolveCurrency(currency, toResp.Currency); err != nil {
+		return nil, err
+	}
+
+	// both accounts are locked for the transfer, so it runs at read
+	// committed; a deadlock it still runs into is retried
+	var fees []Fee
+	err = s.runner.Run(ctx, "transfer", nil, func(tx *sql.Tx) error {
+		var err error
+		fees, err = s.transfer(ctx, tx, idempotencyKey, fromResp, fromAccountID, toAccountID, amount, currency, note, labels)
 		return err
+	})
+	if err != nil {
+		return nil, err
 	}
-	defer tx.Rollback()
 
+	return fees, nil
+}
+
+// transfer posts a validated transfer and its fees in tx.
+func (s *Service) transfer(ctx context.Context, tx *sql.Tx, idempotencyKey string, fromResp *pb.GetAccountResponse, fromAccountID int64, toAccountID int64, amount int64, currency string, note string, labels Labels) ([]Fee, error) {
 	//accountRepo := account.NewPostgresRepository(tx)
 	transactionRepo := NewPostgresRepo(tx)
+	journalRepo := NewPostgresJournalRepo(tx)
+	idemRepo := NewPostgresIdempotencyRepoWithRetention(tx, s.retention)
+	outboxRepo := NewPostgresOutboxRepository(tx)
+
+	// sender and recipient, in ID order so opposite transfers between the
+	// same accounts cannot deadlock
+	if err := lockAccounts(ctx, tx, fromAccountID, toAccountID); err != nil {
+		return nil, err
+	}
+
+	// claim the key inside the tx, so a failed transfer releases it and a
+	// concurrent duplicate waits for it instead of moving money
+	if idempotencyKey != "" {
+		hash, err := payloadHash(fromAccountID, toAccountID, amount, currency, note, labels)
+		if err != nil {
+			return nil, err
+		}
+
+		claimed, result, err := claimKey(ctx, idemRepo, idempotencyKey, "transfer", hash)
+		if err != nil {
+			return nil, err
+		}
+
+		if !claimed {
+			// already proccesed; answer with the fees charged then
+			var fees []Fee
+			return fees, replayResult(result, &fees)
+		}
+	}
 
 	// // Lock accounts in ID order (deadlock prevention)
 	// first, second := fromAccountID, toAccountID
@@ -288,97 +417,176 @@ func (s *Service) Transfer(ctx context.Context, fromAccountID int64, toAccountID
 
 	// fromAcc, err := accountRepo.LockByID(ctx, fromAccountID)
 	// if err != nil {
-	// 	return err
+	// 	return nil, err
 	// }
 
 	// toAcc, err := accountRepo.LockByID(ctx, toAccountID)
 	// if err != nil {
-	// 	return err
+	// 	return nil, err
 	// }
 
-	fromResp, err := s.accountClient.GetAccount(ctx, &pb.GetAccountRequest{
-		AccountId: fromAccountID,
-	})
+	// if fromAcc.Balance < amount {
+	// 	return ErrInsufficientFunds
+	// }
 
-	if err != nil || !fromResp.IsExists || !fromResp.IsActive {
-		return errors.New("from account or debit account not found or inactive")
+	fees, err := quoteFees(ctx, tx, FeeOnTransfer, fromResp, currency, amount)
+	if err != nil {
+		return nil, err
 	}
 
-	toResp, err := s.accountClient.GetAccount(ctx, &pb.GetAccountRequest{
-		AccountId: toAccountID,
-	})
+	// check available balance; the sender pays the fees on top
+	if err := s.checkFunds(ctx, tx, fromResp, fromAccountID, currency, amount+totalFees(fees)); err != nil {
+		return nil, err
+	}
 
-	if err != nil || !toResp.IsExists || !toResp.IsActive {
-		return errors.New("to account or credit account not found or inactive")
+	if err := s.checkLimits(ctx, tx, fromResp, currency, amount); err != nil {
+		return nil, err
 	}
-	// if fromAcc.Balance < amount {
-	// 	return errors.New("insufficient funds")
+
+	// if err := accountRepo.UpdateBalance(ctx, fromAcc.ID, fromAcc.Balance-amount); err != nil {
+	// 	return nil, err
 	// }
 
-	// check balance
-	balance, err := transactionRepo.BalanceByAccount(ctx, fromAccountID)
+	// if err := accountRepo.UpdateBalance(ctx, toAcc.ID, toAcc.Balance+amount); err != nil {
+	// 	return nil, err
+	// }
+
+	journal := transferJournal(fromAccountID, toAccountID, amount, currency, note)
+	debit, err := postTransfer(ctx, journalRepo, transactionRepo, journal, fromAccountID, toAccountID, amount, note, labels)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	// if err := accountRepo.UpdateBalance(ctx, fromAcc.ID, fromAcc.Balance-amount); err != nil {
-	// 	return err
-	// }
+	if err := postFees(ctx, tx, debit, fees); err != nil {
+		return nil, err
+	}
 
-	if balance < amount {
-		return errors.New("Insufficient funds")
+	// one event for both legs, raised on the sender like a batch leg
+	payload := map[string]interface{}{
+		"account_id":    fromAccountID,
+		"to_account_id": toAccountID,
+		"amount":        amount,
+		"currency":      currency,
+		"type":          "transfer",
+		"note":          note,
+		"journal_id":    journal.ID,
 	}
+	labels.addTo(payload)
 
-	// if err := accountRepo.UpdateBalance(ctx, toAcc.ID, toAcc.Balance+amount); err != nil {
-	// 	return err
-	// }
+	payloadJSON, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
 
-	// debit
-	if err := transactionRepo.Create(ctx, &Transaction{
-		AccountID: fromAccountID,
-		Type:      TypeTransferOut,
-		Amount:    amount,
-		Note:      fmt.Sprintf("To account %d: %s", toAccountID, note),
+	if err := outboxRepo.Add(ctx, &OutboxEvent{
+		ID:            uuid.New(),
+		AggregateType: "account",
+		AggregateID:   fromAccountID,
+		EventType:     "transaction.created",
+		Payload:       payloadJSON,
 	}); err != nil {
-		return err
+		return nil, err
 	}
 
-	//credit
-	if err := transactionRepo.Create(ctx, &Transaction{
-		AccountID: toAccountID,
-		Type:      TypeTransferIn,
-		Amount:    amount,
-		Note:      fmt.Sprintf("From account %d: %s", fromAccountID, note),
-	}); err != nil {
-		return err
+	if err := saveResult(ctx, idemRepo, idempotencyKey, fees); err != nil {
+		return nil, err
 	}
 
-	return tx.Commit()
+	return fees, nil
 }
 
-func (s *Service) History(ctx context.Context, accountID int64) ([]Transaction, error) {
+// postTransfer writes a transfer journal and the debit and credit ledger
+// entries of both customers. Both entries keep the caller's note and labels
+// as given and name the other account as their counterparty. It returns the
+// debit entry.
+func postTransfer(ctx context.Context, journalRepo JournalRepository, transactionRepo TransactionRepository, journal *JournalEntry, fromAccountID, toAccountID, amount int64, note string, labels Labels) (*Transaction, error) {
+	if err := journalRepo.Create(ctx, journal); err != nil {
+		return nil, err
+	}
+
+	// debit
+	debit := &Transaction{
+		AccountID:      fromAccountID,
+		JournalID:      journal.ID,
+		Type:           TypeTransferOut,
+		Amount:         amount,
+		Currency:       journal.Currency,
+		CounterpartyID: toAccountID,
+		Note:           note,
+		Labels:         labels,
+	}
+	if err := transactionRepo.Create(ctx, debit); err != nil {
+		return nil, err
+	}
+
+	//credit
+	return debit, transactionRepo.Create(ctx, &Transaction{
+		AccountID:      toAccountID,
+		JournalID:      journal.ID,
+		Type:           TypeTransferIn,
+		Amount:         amount,
+		Currency:       journal.Currency,
+		CounterpartyID: fromAccountID,
+		Note:           note,
+		Labels:         labels,
+	})
+}
+
+func (s *Service) History(ctx context.Context, accountID int64, filter HistoryFilter) (*HistoryPage, error) {
 	// _, err := s.accountRepo.GetByID(ctx, accountID)
 	// if err != nil {
 	// 	return nil, err
 	// }
 
 	// ask accoutnt service
-	resp, err := s.accountClient.GetAccount(ctx, &pb.GetAccountRequest{
-		AccountId: accountID,
-	})
+	resp, err := s.activeAccount(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
 
+	page, err := s.transactionRepo.ListPage(ctx, accountID, filter)
+	if err != nil || filter.AsOf == nil {
+		return page, err
+	}
+
+	page.Balances, err = s.balancesAt(ctx, accountID, resp.Currency, filter.AsOf.UTC())
 	if err != nil {
 		return nil, err
 	}
 
-	if !resp.IsExists || !resp.IsActive {
-		return nil, errors.New("account not found or inactive")
+	return page, nil
+}
+
+// EachHistory calls fn for every entry of the history that matches filter,
+// reading it a page at a time from filter.Cursor on. The account is looked
+// up once, and no balances are computed.
+func (s *Service) EachHistory(ctx context.Context, accountID int64, filter HistoryFilter, fn func(Transaction) error) error {
+	if _, err := s.activeAccount(ctx, accountID); err != nil {
+		return err
 	}
 
-	return s.transactionRepo.ListByAccount(ctx, accountID)
+	for {
+		page, err := s.transactionRepo.ListPage(ctx, accountID, filter)
+		if err != nil {
+			return err
+		}
+
+		for _, t := range page.Entries {
+			if err := fn(t); err != nil {
+				return err
+			}
+		}
+
+		if page.NextCursor == "" {
+			return nil
+		}
+		filter.Cursor = page.NextCursor
+	}
 }
 
-func (s *Service) Balance(ctx context.Context, accountID int64) (int64, error) {
+// Balance returns one balance per currency the account has posted in, or a
+// zero balance in the account's currency if nothing was posted yet.
+func (s *Service) Balance(ctx context.Context, accountID int64) ([]Balance, error) {
 
 	// _, err := s.accountRepo.GetByID(ctx, accountID)
 	// if err != nil {
@@ -386,17 +594,29 @@ func (s *Service) Balance(ctx context.Context, accountID int64) (int64, error) {
 	// }
 
 	// ask account service
-	resp, err := s.accountClient.GetAccount(ctx, &pb.GetAccountRequest{
-		AccountId: accountID,
-	})
+	resp, err := s.activeAccount(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	balances, err := s.transactionRepo.BalanceByAccount(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
 
+	if len(balances) == 0 {
+		currency, _ := resolveCurrency("", resp.Currency)
+		balances = []Balance{{Currency: currency}}
+	}
+
+	held, err := s.holdRepo.HeldByAccount(ctx, accountID)
 	if err != nil {
-		return 0, err
+		return nil, err
 	}
 
-	if !resp.IsExists || !resp.IsActive {
-		return 0, errors.New("account not found or inactive")
+	for i := range balances {
+		balances[i].Available = balances[i].Ledger - held[balances[i].Currency]
 	}
 
-	return s.transactionRepo.BalanceByAccount(ctx, accountID)
+	return balances, nil
 }
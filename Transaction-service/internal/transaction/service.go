@@ -1,12 +1,38 @@
 package transaction
 
-import "context"
+import (
+	"context"
+	"time"
+)
 
 type TransactionService interface {
-	Deposit(ctx context.Context,  idempotencyKey string, accountID int64,  amount int64, note string) error 
-	Withdraw(ctx context.Context, idempotencyKey string, accountID int64, amount int64, note string) error
-	Transfer(ctx context.Context, fromAccountID int64, toAccountID int64, amount int64, note string) error
-	History(ctx context.Context, accountID int64) ([]Transaction, error)
-	Balance( ctx context.Context, accountID int64) (int64, error)
-
-}
\ No newline at end of file
+	Deposit(ctx context.Context, idempotencyKey string, accountID int64, amount int64, currency string, note string, labels Labels) error
+	Withdraw(ctx context.Context, idempotencyKey string, accountID int64, amount int64, currency string, note string, labels Labels) ([]Fee, error)
+	Transfer(ctx context.Context, idempotencyKey string, fromAccountID int64, toAccountID int64, amount int64, currency string, note string, labels Labels) ([]Fee, error)
+	BatchTransfer(ctx context.Context, idempotencyKey string, legs []TransferLeg) (*BatchResult, error)
+	History(ctx context.Context, accountID int64, filter HistoryFilter) (*HistoryPage, error)
+	EachHistory(ctx context.Context, accountID int64, filter HistoryFilter, fn func(Transaction) error) error
+	Balance(ctx context.Context, accountID int64) ([]Balance, error)
+	BalanceAsOf(ctx context.Context, accountID int64, asOf time.Time) ([]HistoricalBalance, error)
+	Reverse(ctx context.Context, transactionID int64, amount int64, note string) (*JournalEntry, error)
+	PlaceHold(ctx context.Context, accountID int64, amount int64, currency string, ttl time.Duration, note string) (*Hold, error)
+	CaptureHold(ctx context.Context, holdID int64, amount int64, note string) (*Hold, error)
+	ReleaseHold(ctx context.Context, holdID int64) (*Hold, error)
+	GetHold(ctx context.Context, holdID int64) (*Hold, error)
+	Export(ctx context.Context, accountID int64, filter ExportFilter, exporter Exporter) error
+	GetLimits(ctx context.Context, scope LimitScope) (*Limits, error)
+	SetLimits(ctx context.Context, scope LimitScope, limits *Limits) error
+	ListFeeRules(ctx context.Context) ([]FeeRule, error)
+	GetFeeRule(ctx context.Context, id int64) (*FeeRule, error)
+	CreateFeeRule(ctx context.Context, rule *FeeRule) error
+	UpdateFeeRule(ctx context.Context, rule *FeeRule) error
+	DeleteFeeRule(ctx context.Context, id int64) error
+	ListInterestRates(ctx context.Context) ([]InterestRate, error)
+	SetInterestRate(ctx context.Context, rate *InterestRate) error
+	DeleteInterestRate(ctx context.Context, tier, currency string) error
+	Reconcile(ctx context.Context, from, to time.Time) (*ReconciliationReport, error)
+	BackfillEvents(ctx context.Context, from, to time.Time) (*ReconciliationReport, error)
+	VerifyChain(ctx context.Context, accountID int64) (*ChainVerification, error)
+	ChainCheckpoints(ctx context.Context, accountID int64) ([]ChainCheckpoint, error)
+	ChainKey() (*ChainKey, error)
+}
@@ -3,6 +3,7 @@ package transaction_test
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"net/url"
 	"os"
@@ -36,25 +37,186 @@ CREATE TABLE IF NOT EXISTS accounts (
     updated_at TIMESTAMP NOT NULL DEFAULT now()
 );
 
+CREATE TABLE IF NOT EXISTS journal_entries (
+    id BIGSERIAL PRIMARY KEY,
+    type TEXT NOT NULL,
+    reverses_journal_id BIGINT REFERENCES journal_entries(id),
+    batch_id UUID,
+    currency TEXT NOT NULL DEFAULT 'USD',
+    note TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT now()
+);
+
+CREATE TABLE IF NOT EXISTS postings (
+    id BIGSERIAL PRIMARY KEY,
+    journal_id BIGINT NOT NULL REFERENCES journal_entries(id) ON DELETE CASCADE,
+    account_id BIGINT REFERENCES accounts(id) ON DELETE CASCADE,
+    system_account TEXT,
+    amount BIGINT NOT NULL CHECK (amount <> 0),
+    CHECK ((account_id IS NULL) <> (system_account IS NULL))
+);
+
 CREATE TABLE IF NOT EXISTS transactions (
     id BIGSERIAL PRIMARY KEY,
     account_id BIGINT NOT NULL REFERENCES accounts(id) ON DELETE CASCADE,
-    type TEXT NOT NULL CHECK (type IN ('DEPOSIT', 'WITHDRAW', 'TRANSFER_IN', 'TRANSFER_OUT')),
+    journal_id BIGINT REFERENCES journal_entries(id),
+    type TEXT NOT NULL CHECK (type IN ('DEPOSIT', 'WITHDRAW', 'TRANSFER_IN', 'TRANSFER_OUT', 'REVERSAL_IN', 'REVERSAL_OUT', 'FEE', 'INTEREST')),
     amount BIGINT NOT NULL CHECK (amount > 0),
+    currency TEXT NOT NULL DEFAULT 'USD',
+    balance_after BIGINT NOT NULL,
+    reversal_of BIGINT REFERENCES transactions(id),
+    counterparty_account_id BIGINT REFERENCES accounts(id),
+    fee_for BIGINT REFERENCES transactions(id),
     note TEXT,
-    created_at TIMESTAMP NOT NULL DEFAULT now()
+    metadata JSONB NOT NULL DEFAULT '{}',
+    tags TEXT[] NOT NULL DEFAULT '{}',
+    created_at TIMESTAMP NOT NULL DEFAULT now(),
+    chain_seq BIGINT,
+    prev_hash BYTEA,
+    hash BYTEA,
+    UNIQUE (account_id, chain_seq)
+);
+
+CREATE TABLE IF NOT EXISTS account_balances (
+    account_id BIGINT NOT NULL REFERENCES accounts(id) ON DELETE CASCADE,
+    currency TEXT NOT NULL DEFAULT 'USD',
+    balance BIGINT NOT NULL DEFAULT 0,
+    updated_at TIMESTAMP NOT NULL DEFAULT now(),
+    PRIMARY KEY (account_id, currency)
+);
+
+CREATE TABLE IF NOT EXISTS holds (
+    id BIGSERIAL PRIMARY KEY,
+    account_id BIGINT NOT NULL REFERENCES accounts(id) ON DELETE CASCADE,
+    amount BIGINT NOT NULL CHECK (amount > 0),
+    captured_amount BIGINT NOT NULL DEFAULT 0,
+    currency TEXT NOT NULL,
+    status TEXT NOT NULL DEFAULT 'ACTIVE',
+    note TEXT NOT NULL DEFAULT '',
+    transaction_id BIGINT REFERENCES transactions(id),
+    expires_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT now(),
+    updated_at TIMESTAMP NOT NULL DEFAULT now()
+);
+
+CREATE TABLE IF NOT EXISTS outbox_events (
+    id UUID PRIMARY KEY,
+    aggregate_type TEXT NOT NULL,
+    aggregate_id BIGINT NOT NULL,
+    event_type TEXT NOT NULL,
+    payload JSONB NOT NULL,
+    status TEXT NOT NULL DEFAULT 'pending',
+    created_at TIMESTAMP NOT NULL DEFAULT now(),
+    processed_at TIMESTAMP
 );
 
 CREATE TABLE IF NOT EXISTS idempotency_keys (
     key TEXT PRIMARY KEY,
     operation TEXT NOT NULL,
-    response JSONB NOT NULL,
+    request_hash TEXT,
+    status TEXT NOT NULL DEFAULT 'COMPLETED',
+    response_code INT,
+    response BYTEA,
+    payload_hash TEXT,
+    result BYTEA,
+    applied_at TIMESTAMP,
+    locked_at TIMESTAMP NOT NULL DEFAULT now(),
+    expires_at TIMESTAMP NOT NULL DEFAULT now() + INTERVAL '24 hours',
     created_at TIMESTAMP NOT NULL DEFAULT now()
 );
+
+CREATE TABLE IF NOT EXISTS overdraft_fees (
+    account_id BIGINT NOT NULL REFERENCES accounts(id) ON DELETE CASCADE,
+    currency TEXT NOT NULL,
+    charged_on DATE NOT NULL,
+    transaction_id BIGINT NOT NULL REFERENCES transactions(id),
+    created_at TIMESTAMP NOT NULL DEFAULT now(),
+    PRIMARY KEY (account_id, currency, charged_on)
+);
+
+CREATE TABLE IF NOT EXISTS transfer_limits (
+    scope TEXT NOT NULL,
+    scope_id TEXT NOT NULL DEFAULT '',
+    per_transaction BIGINT,
+    daily BIGINT,
+    rolling_30_days BIGINT,
+    hourly_count BIGINT,
+    updated_at TIMESTAMP NOT NULL DEFAULT now(),
+    PRIMARY KEY (scope, scope_id)
+);
+
+CREATE TABLE IF NOT EXISTS fee_rules (
+    id BIGSERIAL PRIMARY KEY,
+    name TEXT NOT NULL,
+    operation TEXT NOT NULL,
+    currency TEXT,
+    kind TEXT NOT NULL,
+    amount BIGINT NOT NULL DEFAULT 0,
+    basis_points BIGINT NOT NULL DEFAULT 0,
+    tiers JSONB NOT NULL DEFAULT '[]',
+    min_amount BIGINT,
+    max_amount BIGINT,
+    waived_tiers TEXT[] NOT NULL DEFAULT '{}',
+    disabled BOOLEAN NOT NULL DEFAULT false,
+    created_at TIMESTAMP NOT NULL DEFAULT now(),
+    updated_at TIMESTAMP NOT NULL DEFAULT now()
+);
+
+CREATE TABLE IF NOT EXISTS balance_checkpoints (
+    account_id BIGINT NOT NULL REFERENCES accounts(id) ON DELETE CASCADE,
+    currency TEXT NOT NULL,
+    as_of TIMESTAMP NOT NULL,
+    balance BIGINT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT now(),
+    PRIMARY KEY (account_id, currency, as_of)
+);
+
+CREATE TABLE IF NOT EXISTS interest_rates (
+    tier TEXT NOT NULL,
+    currency TEXT NOT NULL,
+    method TEXT NOT NULL,
+    tiers JSONB NOT NULL,
+    updated_at TIMESTAMP NOT NULL DEFAULT now(),
+    PRIMARY KEY (tier, currency)
+);
+
+CREATE TABLE IF NOT EXISTS interest_accruals (
+    account_id BIGINT NOT NULL REFERENCES accounts(id) ON DELETE CASCADE,
+    currency TEXT NOT NULL,
+    day DATE NOT NULL,
+    balance BIGINT NOT NULL,
+    basis BIGINT NOT NULL,
+    rate_bps BIGINT NOT NULL,
+    method TEXT NOT NULL,
+    amount_micros BIGINT NOT NULL,
+    posted_at TIMESTAMP,
+    transaction_id BIGINT REFERENCES transactions(id),
+    PRIMARY KEY (account_id, currency, day)
+);
+
+CREATE TABLE IF NOT EXISTS ledger_chain_heads (
+    account_id BIGINT PRIMARY KEY REFERENCES accounts(id) ON DELETE CASCADE,
+    last_transaction_id BIGINT REFERENCES transactions(id),
+    entries BIGINT NOT NULL DEFAULT 0,
+    hash BYTEA,
+    updated_at TIMESTAMP NOT NULL DEFAULT now()
+);
+
+CREATE TABLE IF NOT EXISTS ledger_chain_checkpoints (
+    id BIGSERIAL PRIMARY KEY,
+    account_id BIGINT NOT NULL REFERENCES accounts(id) ON DELETE CASCADE,
+    transaction_id BIGINT NOT NULL REFERENCES transactions(id),
+    entries BIGINT NOT NULL,
+    hash BYTEA NOT NULL,
+    key_id TEXT NOT NULL,
+    signature BYTEA NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    UNIQUE (account_id, entries)
+);
 `)
 
 	t.Cleanup(func() {
-		db.Exec("TRUNCATE accounts, transactions, idempotency_keys RESTART IDENTITY CASCADE")
+		db.Exec("TRUNCATE accounts, transactions, account_balances, holds, journal_entries, postings, outbox_events, idempotency_keys, overdraft_fees, transfer_limits, balance_checkpoints, fee_rules, interest_rates, interest_accruals, ledger_chain_heads, ledger_chain_checkpoints RESTART IDENTITY CASCADE")
 		db.Close()
 	})
 
@@ -67,10 +229,17 @@ func formatMoney(cents int64) string {
 
 func assertBalance(t *testing.T, s transaction.TransactionService, accountID int64, expected int64) {
 	t.Helper()
-	balance, err := s.Balance(context.Background(), accountID)
+	balances, err := s.Balance(context.Background(), accountID)
 	if err != nil {
 		t.Fatalf("❌ Failed to get balance: %v", err)
 	}
+
+	var balance int64
+	for _, b := range balances {
+		if b.Currency == "USD" {
+			balance = b.Ledger
+		}
+	}
 	if balance != expected {
 		t.Errorf("❌ Balance mismatch: expected %s, got %s", formatMoney(expected), formatMoney(balance))
 	} else {
@@ -93,7 +262,7 @@ func TestDeposit_IncreasesBalance(t *testing.T) {
 	t.Run("Perform Deposit", func(t *testing.T) {
 		amount := int64(10_000)
 		t.Logf("💵 Depositing %s...", formatMoney(amount))
-		err = service.Deposit(ctx, key, acc.ID, amount, "initial deposit")
+		err = service.Deposit(ctx, key, acc.ID, amount, "USD", "initial deposit", transaction.Labels{})
 		if err != nil {
 			t.Fatalf("❌ Deposit failed: %v", err)
 		}
@@ -119,10 +288,13 @@ func TestWithdraw_InsufficientFunds(t *testing.T) {
 	t.Run("Attempt Overdraft", func(t *testing.T) {
 		amount := int64(5_000)
 		t.Logf("💸 Attempting to withdraw %s from empty account...", formatMoney(amount))
-		err = service.Withdraw(ctx, key, acc.ID, amount, "bad withdraw")
+		_, err = service.Withdraw(ctx, key, acc.ID, amount, "USD", "bad withdraw", transaction.Labels{})
 		if err == nil {
 			t.Fatalf("❌ Withdraw succeeded but should have failed due to insufficient funds")
 		}
+		if !errors.Is(err, transaction.ErrInsufficientFunds) {
+			t.Fatalf("❌ Expected ErrInsufficientFunds, got %v", err)
+		}
 		t.Log("✅ Withdraw failed as expected (Insufficient Funds)")
 	})
 }
@@ -140,7 +312,7 @@ func TestTransfer_Atomicity(t *testing.T) {
 	t.Logf("👤 Created accounts: %s -> %s", from.Name, to.Name)
 
 	t.Run("Setup Initial Funds", func(t *testing.T) {
-		err := service.Deposit(ctx, key, from.ID, 20_000, "funding")
+		err := service.Deposit(ctx, key, from.ID, 20_000, "USD", "funding", transaction.Labels{})
 		if err != nil {
 			t.Fatalf("❌ Setup failed: %v", err)
 		}
@@ -150,7 +322,7 @@ func TestTransfer_Atomicity(t *testing.T) {
 	t.Run("Execute Transfer", func(t *testing.T) {
 		amount := int64(15_000)
 		t.Logf("🔄 Transferring %s from %s to %s...", formatMoney(amount), from.Name, to.Name)
-		err := service.Transfer(ctx, from.ID, to.ID, amount, "payment")
+		_, err := service.Transfer(ctx, "", from.ID, to.ID, amount, "USD", "payment", transaction.Labels{})
 		if err != nil {
 			t.Fatalf("❌ Transfer failed: %v", err)
 		}
@@ -178,9 +350,14 @@ func TestLedgerDerivedBalance(t *testing.T) {
 			name   string
 			action func() error
 		}{
-			{"Deposit 100.00", func() error { return service.Deposit(ctx, key, acc.ID, 10_000, "funding") }},
-			{"Withdraw 25.00", func() error { return service.Withdraw(ctx, key, acc.ID, 2_500, "expense") }},
-			{"Deposit 10.00", func() error { return service.Deposit(ctx, key+"-2", acc.ID, 1_000, "refund") }},
+			{"Deposit 100.00", func() error { return service.Deposit(ctx, key, acc.ID, 10_000, "USD", "funding", transaction.Labels{}) }},
+			{"Withdraw 25.00", func() error {
+				_, err := service.Withdraw(ctx, key, acc.ID, 2_500, "USD", "expense", transaction.Labels{})
+				return err
+			}},
+			{"Deposit 10.00", func() error {
+				return service.Deposit(ctx, key+"-2", acc.ID, 1_000, "USD", "refund", transaction.Labels{})
+			}},
 		}
 
 		for _, op := range ops {
@@ -209,7 +386,7 @@ func TestDeposit_Idempotent(t *testing.T) {
 
 	t.Run("First Deposit", func(t *testing.T) {
 		t.Log("1️⃣ Performing first deposit...")
-		err := service.Deposit(ctx, key, acc.ID, 10_000, "once")
+		err := service.Deposit(ctx, key, acc.ID, 10_000, "USD", "once", transaction.Labels{})
 		if err != nil {
 			t.Fatalf("❌ First deposit failed: %v", err)
 		}
@@ -218,7 +395,7 @@ func TestDeposit_Idempotent(t *testing.T) {
 
 	t.Run("Second Deposit (Duplicate Key)", func(t *testing.T) {
 		t.Log("2️⃣ Performing second deposit with same key...")
-		err := service.Deposit(ctx, key, acc.ID, 10_000, "twice")
+		err := service.Deposit(ctx, key, acc.ID, 10_000, "USD", "once", transaction.Labels{})
 		if err != nil {
 			t.Fatalf("❌ Second deposit returned error: %v", err)
 		}
@@ -227,3 +404,29 @@ func TestDeposit_Idempotent(t *testing.T) {
 		t.Log("✅ Balance correctly remained unchanged")
 	})
 }
+
+func TestTransfer_Idempotent(t *testing.T) {
+	db := setupTestDB(t)
+	ctx := context.Background()
+	key := "transfer-idempotent"
+
+	txRepo := transaction.NewPostgresRepo(db)
+	service := transaction.NewTransactionService(db, &MockAccountClient{}, txRepo)
+
+	from := createTestAccount(t, db, "Sender")
+	to := createTestAccount(t, db, "Receiver")
+
+	if err := service.Deposit(ctx, "", from.ID, 10_000, "USD", "seed", transaction.Labels{}); err != nil {
+		t.Fatalf("seed deposit failed: %v", err)
+	}
+
+	for i := 0; i < 2; i++ {
+		if _, err := service.Transfer(ctx, key, from.ID, to.ID, 4_000, "USD", "retried", transaction.Labels{}); err != nil {
+			t.Fatalf("transfer %d failed: %v", i+1, err)
+		}
+	}
+
+	// the retry must not move money a second time
+	assertBalance(t, service, from.ID, 6_000)
+	assertBalance(t, service, to.ID, 4_000)
+}
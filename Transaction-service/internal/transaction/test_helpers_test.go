@@ -9,14 +9,28 @@ import (
 	"google.golang.org/grpc"
 )
 
-type MockAccountClient struct{}
+// MockAccountClient reports every account as active. Accounts are held in
+// USD unless Currencies says otherwise.
+type MockAccountClient struct {
+	Currencies      map[int64]string
+	OverdraftLimits map[int64]int64
+	Tiers           map[int64]string
+}
 
 func (m *MockAccountClient) GetAccount(ctx context.Context, in *pb.GetAccountRequest, opts ...grpc.CallOption) (*pb.GetAccountResponse, error) {
+	currency := "USD"
+	if c, ok := m.Currencies[in.AccountId]; ok {
+		currency = c
+	}
+
 	return &pb.GetAccountResponse{
-		Id:       in.AccountId,
-		Name:     "Test Account",
-		IsExists: true,
-		IsActive: true,
+		Id:             in.AccountId,
+		Name:           "Test Account",
+		IsExists:       true,
+		IsActive:       true,
+		Currency:       currency,
+		OverdraftLimit: m.OverdraftLimits[in.AccountId],
+		Tier:           m.Tiers[in.AccountId],
 	}, nil
 }
 
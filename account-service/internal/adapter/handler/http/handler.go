@@ -1,14 +1,22 @@
 package http
 
 import (
+	"account/internal/core/domain"
 	"account/internal/core/port"
 	"encoding/json"
+	"errors"
 	"net/http"
+	"regexp"
 	"strconv"
+	"strings"
 
 	"github.com/go-chi/chi"
 )
 
+const defaultCurrency = "USD"
+
+var currencyCode = regexp.MustCompile(`^[A-Z]{3}$`)
+
 type AccountHandler struct {
 	repo           port.AccountRepository
 	balanceHandler http.HandlerFunc
@@ -20,7 +28,8 @@ func NewAccountHandler(repo port.AccountRepository, balanceHandler http.HandlerF
 
 func (h *AccountHandler) Create(w http.ResponseWriter, r *http.Request) {
 	var req struct {
-		Name string `json:"name"`
+		Name     string `json:"name"`
+		Currency string `json:"currency"`
 	}
 
 	// Parse request body
@@ -29,7 +38,16 @@ func (h *AccountHandler) Create(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	acc, err := h.repo.Create(r.Context(), req.Name)
+	currency := strings.ToUpper(strings.TrimSpace(req.Currency))
+	if currency == "" {
+		currency = defaultCurrency
+	}
+	if !currencyCode.MatchString(currency) {
+		http.Error(w, "currency must be a 3-letter ISO 4217 code", http.StatusBadRequest)
+		return
+	}
+
+	acc, err := h.repo.Create(r.Context(), req.Name, currency)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -47,19 +65,92 @@ func (h *AccountHandler) Get(w http.ResponseWriter, r *http.Request) {
 	}
 
 	acc, err := h.repo.GetByID(r.Context(), id)
+	if errors.Is(err, domain.ErrAccountNotFound) {
+		http.Error(w, "not found", http.StatusNotFound)
+		return
+	}
+
 	if err != nil {
+		http.Error(w, "failed to load account", http.StatusInternalServerError)
+		return
+	}
+
+	json.NewEncoder(w).Encode(acc)
+
+}
+
+// SetOverdraft replaces the account's overdraft limit; zero disables it.
+func (h *AccountHandler) SetOverdraft(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		http.Error(w, "invalid id", http.StatusBadRequest)
+		return
+	}
+
+	var req struct {
+		OverdraftLimit *int64 `json:"overdraft_limit"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.OverdraftLimit == nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	acc, err := h.repo.SetOverdraftLimit(r.Context(), id, *req.OverdraftLimit)
+	switch {
+	case errors.Is(err, domain.ErrInvalidOverdraftLimit):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	case errors.Is(err, domain.ErrAccountNotFound):
 		http.Error(w, "not found", http.StatusNotFound)
 		return
+	case err != nil:
+		http.Error(w, "failed to update account", http.StatusInternalServerError)
+		return
 	}
 
 	json.NewEncoder(w).Encode(acc)
+}
+
+// SetTier moves the account to another limits and pricing tier.
+func (h *AccountHandler) SetTier(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		http.Error(w, "invalid id", http.StatusBadRequest)
+		return
+	}
 
+	var req struct {
+		Tier string `json:"tier"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	acc, err := h.repo.SetTier(r.Context(), id, strings.ToLower(strings.TrimSpace(req.Tier)))
+	switch {
+	case errors.Is(err, domain.ErrInvalidTier):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	case errors.Is(err, domain.ErrAccountNotFound):
+		http.Error(w, "not found", http.StatusNotFound)
+		return
+	case err != nil:
+		http.Error(w, "failed to update account", http.StatusInternalServerError)
+		return
+	}
+
+	json.NewEncoder(w).Encode(acc)
 }
 
 func (h *AccountHandler) Routes() http.Handler {
 	r := chi.NewRouter()
 	r.Post("/", h.Create)
 	r.Get("/{id}", h.Get)
+	r.Put("/{id}/overdraft", h.SetOverdraft)
+	r.Put("/{id}/tier", h.SetTier)
 	if h.balanceHandler != nil {
 		r.Get("/{id}/balance", h.balanceHandler)
 	}
@@ -5,9 +5,11 @@ import (
 	"account/internal/infrastructure/database"
 	"context"
 	"database/sql"
-	"errors"
+	"regexp"
 )
 
+var tierName = regexp.MustCompile(`^[a-z0-9_-]{1,32}$`)
+
 type PostgresRepository struct {
 	db database.DBTX
 }
@@ -16,40 +18,40 @@ func NewPostgresRepository(db database.DBTX) *PostgresRepository {
 	return &PostgresRepository{db: db}
 }
 
-func (r *PostgresRepository) Create(ctx context.Context, name string) (*domain.Account, error) {
+func (r *PostgresRepository) Create(ctx context.Context, name string, currency string) (*domain.Account, error) {
 	query := `
-         INSERT INTO accounts (name)
-		 VALUES ($1)
-		 RETURNING id, name, created_at, updated_at
+         INSERT INTO accounts (name, currency)
+		 VALUES ($1, $2)
+		 RETURNING id, name, currency, overdraft_limit, tier, created_at, updated_at
          `
 
 	acc := &domain.Account{}
-	err := r.db.QueryRowContext(ctx, query, name).Scan(
-		&acc.ID, &acc.Name, &acc.CreatedAt, &acc.UpdatedAt)
+	err := r.db.QueryRowContext(ctx, query, name, currency).Scan(
+		&acc.ID, &acc.Name, &acc.Currency, &acc.OverdraftLimit, &acc.Tier, &acc.CreatedAt, &acc.UpdatedAt)
 
 	return acc, err
 }
 
 func (r *PostgresRepository) GetByID(ctx context.Context, id int64) (*domain.Account, error) {
 	query := `
-	         SELECT id, name, created_at, updated_at
+	         SELECT id, name, currency, overdraft_limit, tier, created_at, updated_at
 	         FROM accounts
 	         WHERE id = $1
 	   `
 
 	acc := &domain.Account{}
 	err := r.db.QueryRowContext(ctx, query, id).Scan(
-		&acc.ID, &acc.Name, &acc.CreatedAt, &acc.UpdatedAt)
+		&acc.ID, &acc.Name, &acc.Currency, &acc.OverdraftLimit, &acc.Tier, &acc.CreatedAt, &acc.UpdatedAt)
 
 	if err == sql.ErrNoRows {
-		return nil, errors.New("account not found")
+		return nil, domain.ErrAccountNotFound
 	}
 
 	return acc, err
 }
 
 func (r *PostgresRepository) LockByID(ctx context.Context, id int64) (*domain.Account, error) {
-	query := `SELECT id, name, created_at, updated_at
+	query := `SELECT id, name, currency, overdraft_limit, tier, created_at, updated_at
 	         FROM accounts
 			 WHERE id = $1
 			 FOR UPDATE
@@ -57,11 +59,69 @@ func (r *PostgresRepository) LockByID(ctx context.Context, id int64) (*domain.Ac
 
 	acc := &domain.Account{}
 	err := r.db.QueryRowContext(ctx, query, id).Scan(
-		&acc.ID, &acc.Name, &acc.CreatedAt, &acc.UpdatedAt)
+		&acc.ID, &acc.Name, &acc.Currency, &acc.OverdraftLimit, &acc.Tier, &acc.CreatedAt, &acc.UpdatedAt)
+
+	if err == sql.ErrNoRows {
+		return nil, domain.ErrAccountNotFound
+	}
 
 	if err != nil {
-		return nil, errors.New("account not found")
+		return nil, err
 	}
 
 	return acc, err
 }
+
+func (r *PostgresRepository) SetOverdraftLimit(ctx context.Context, id int64, limit int64) (*domain.Account, error) {
+	if limit < 0 {
+		return nil, domain.ErrInvalidOverdraftLimit
+	}
+
+	query := `
+	         UPDATE accounts
+	         SET overdraft_limit = $2, updated_at = now()
+	         WHERE id = $1
+	         RETURNING id, name, currency, overdraft_limit, tier, created_at, updated_at
+	   `
+
+	acc := &domain.Account{}
+	err := r.db.QueryRowContext(ctx, query, id, limit).Scan(
+		&acc.ID, &acc.Name, &acc.Currency, &acc.OverdraftLimit, &acc.Tier, &acc.CreatedAt, &acc.UpdatedAt)
+
+	if err == sql.ErrNoRows {
+		return nil, domain.ErrAccountNotFound
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return acc, nil
+}
+
+func (r *PostgresRepository) SetTier(ctx context.Context, id int64, tier string) (*domain.Account, error) {
+	if !tierName.MatchString(tier) {
+		return nil, domain.ErrInvalidTier
+	}
+
+	query := `
+	         UPDATE accounts
+	         SET tier = $2, updated_at = now()
+	         WHERE id = $1
+	         RETURNING id, name, currency, overdraft_limit, tier, created_at, updated_at
+	   `
+
+	acc := &domain.Account{}
+	err := r.db.QueryRowContext(ctx, query, id, tier).Scan(
+		&acc.ID, &acc.Name, &acc.Currency, &acc.OverdraftLimit, &acc.Tier, &acc.CreatedAt, &acc.UpdatedAt)
+
+	if err == sql.ErrNoRows {
+		return nil, domain.ErrAccountNotFound
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return acc, nil
+}
@@ -1,11 +1,26 @@
 package domain
 
-import "time"
+import (
+	"errors"
+	"time"
+)
 
 type Account struct {
 	ID   int64  `json:"id"`
 	Name string `json:"name"`
+	// ISO 4217 code of the currency the account is held in
+	Currency string `json:"currency"`
+	// how far below zero the balance may go in Currency, in cents
+	OverdraftLimit int64 `json:"overdraft_limit"`
+	// pricing and limits tier, e.g. "standard" or "business"
+	Tier string `json:"tier"`
 	//Balance   int64     `json:"balance"`
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
 }
+
+var (
+	ErrAccountNotFound       = errors.New("account not found")
+	ErrInvalidOverdraftLimit = errors.New("overdraft limit cannot be negative")
+	ErrInvalidTier           = errors.New("tier must be 1-32 lowercase letters, digits, '-' or '_'")
+)
@@ -2,8 +2,10 @@ package grpc
 
 import (
 	account "account/internal"
+	"account/internal/core/domain"
 	"account/pb"
 	"context"
+	"errors"
 
 	"google.golang.org/grpc/codes"
 	//"google.golang.org/grpc/stats"
@@ -23,14 +25,21 @@ func NewGrpcAccountServer(repo account.AccountRepository) *GrpcAccountServer {
 
 func (s *GrpcAccountServer) GetAccount(ctx context.Context, req *pb.GetAccountRequest) (*pb.GetAccountResponse, error) {
 	account, err := s.repo.GetByID(ctx, req.AccountId)
-	if err != nil {
+	if errors.Is(err, domain.ErrAccountNotFound) {
 		return nil, status.Error(codes.NotFound, "account not found")
 	}
 
+	if err != nil {
+		return nil, status.Error(codes.Internal, "failed to load account")
+	}
+
 	return &pb.GetAccountResponse{
-		Id:       account.ID,
-		Name:     account.Name,
-		IsActive: true,
-		IsExists: true,
+		Id:             account.ID,
+		Name:           account.Name,
+		Currency:       account.Currency,
+		OverdraftLimit: account.OverdraftLimit,
+		Tier:           account.Tier,
+		IsActive:       true,
+		IsExists:       true,
 	}, nil
 }
@@ -6,8 +6,10 @@ import (
 )
 
 type AccountRepository interface {
-	Create(ctx context.Context, name string) (*domain.Account, error)
+	Create(ctx context.Context, name string, currency string) (*domain.Account, error)
 	GetByID(ctx context.Context, id int64) (*domain.Account, error)
+	SetOverdraftLimit(ctx context.Context, id int64, limit int64) (*domain.Account, error)
+	SetTier(ctx context.Context, id int64, tier string) (*domain.Account, error)
 
 	//used only inside DB transactions
 	//UpdateBalance(ctx context.Context, id int64, newBalance int64) error
@@ -1,4 +1,4 @@
-CREATE TABLE accounts (
+CREATE TABLE IF NOT EXISTS accounts (
     id BIGSERIAL PRIMARY KEY,
     name TEXT NOT NULL,
   
@@ -8,4 +8,19 @@ CREATE TABLE accounts (
 );
 
 -- Optional but recommended
-CREATE INDEX idx_accounts_name ON accounts(name);
+CREATE INDEX IF NOT EXISTS idx_accounts_name ON accounts(name);
+
+-- ISO 4217 currency the account is held in
+ALTER TABLE accounts
+    ADD COLUMN IF NOT EXISTS currency TEXT NOT NULL DEFAULT 'USD'
+        CHECK (currency ~ '^[A-Z]{3}$');
+
+-- How far below zero the balance may go, in cents of the account currency
+ALTER TABLE accounts
+    ADD COLUMN IF NOT EXISTS overdraft_limit BIGINT NOT NULL DEFAULT 0
+        CHECK (overdraft_limit >= 0);
+
+-- Tier the account's limits and pricing are taken from
+ALTER TABLE accounts
+    ADD COLUMN IF NOT EXISTS tier TEXT NOT NULL DEFAULT 'standard'
+        CHECK (tier ~ '^[a-z0-9_-]{1,32}$');
@@ -66,11 +66,16 @@ func (x *GetAccountRequest) GetAccountId() int64 {
 }
 
 type GetAccountResponse struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
-	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
-	IsActive      bool                   `protobuf:"varint,3,opt,name=is_active,json=isActive,proto3" json:"is_active,omitempty"`
-	IsExists      bool                   `protobuf:"varint,4,opt,name=is_exists,json=isExists,proto3" json:"is_exists,omitempty"`
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	Id       int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name     string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	IsActive bool                   `protobuf:"varint,3,opt,name=is_active,json=isActive,proto3" json:"is_active,omitempty"`
+	IsExists bool                   `protobuf:"varint,4,opt,name=is_exists,json=isExists,proto3" json:"is_exists,omitempty"`
+	Currency string                 `protobuf:"bytes,5,opt,name=currency,proto3" json:"currency,omitempty"`
+	// cents the balance may go below zero in currency
+	OverdraftLimit int64 `protobuf:"varint,6,opt,name=overdraft_limit,json=overdraftLimit,proto3" json:"overdraft_limit,omitempty"`
+	// limits and pricing tier
+	Tier          string `protobuf:"bytes,7,opt,name=tier,proto3" json:"tier,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -133,6 +138,27 @@ func (x *GetAccountResponse) GetIsExists() bool {
 	return false
 }
 
+func (x *GetAccountResponse) GetCurrency() string {
+	if x != nil {
+		return x.Currency
+	}
+	return ""
+}
+
+func (x *GetAccountResponse) GetOverdraftLimit() int64 {
+	if x != nil {
+		return x.OverdraftLimit
+	}
+	return 0
+}
+
+func (x *GetAccountResponse) GetTier() string {
+	if x != nil {
+		return x.Tier
+	}
+	return ""
+}
+
 var File_account_proto protoreflect.FileDescriptor
 
 const file_account_proto_rawDesc = "" +
@@ -140,12 +166,15 @@ const file_account_proto_rawDesc = "" +
 	"\raccount.proto\x12\aaccount\"2\n" +
 	"\x11GetAccountRequest\x12\x1d\n" +
 	"\n" +
-	"account_id\x18\x01 \x01(\x03R\taccountId\"r\n" +
+	"account_id\x18\x01 \x01(\x03R\taccountId\"\xcb\x01\n" +
 	"\x12GetAccountResponse\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x12\n" +
 	"\x04name\x18\x02 \x01(\tR\x04name\x12\x1b\n" +
 	"\tis_active\x18\x03 \x01(\bR\bisActive\x12\x1b\n" +
-	"\tis_exists\x18\x04 \x01(\bR\bisExists2W\n" +
+	"\tis_exists\x18\x04 \x01(\bR\bisExists\x12\x1a\n" +
+	"\bcurrency\x18\x05 \x01(\tR\bcurrency\x12'\n" +
+	"\x0foverdraft_limit\x18\x06 \x01(\x03R\x0eoverdraftLimit\x12\x12\n" +
+	"\x04tier\x18\a \x01(\tR\x04tier2W\n" +
 	"\x0eAccountService\x12E\n" +
 	"\n" +
 	"GetAccount\x12\x1a.account.GetAccountRequest\x1a\x1b.account.GetAccountResponseB\fZ\n" +